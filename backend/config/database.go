@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"honya/backend/model"
+	"honya/backend/utils"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -17,6 +18,73 @@ type Dbinstance struct {
 
 var DB Dbinstance
 
+// auditLogGuard makes audit_logs append-only at the database level
+const auditLogGuard = `
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_no_change ON audit_logs;
+CREATE TRIGGER audit_logs_no_change BEFORE UPDATE OR DELETE ON audit_logs
+	FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+`
+
+// duplicateReviewEmails keeps the oldest review of each reviewer per book counted against the
+// one review rule and releases the rest, which would otherwise block the unique index
+const duplicateReviewEmails = `
+UPDATE reviews SET email_normalized = NULL
+WHERE id IN (
+	SELECT id FROM (
+		SELECT id, ROW_NUMBER() OVER (PARTITION BY book_id, email_normalized ORDER BY created_at, id) AS position
+		FROM reviews
+		WHERE email_normalized IS NOT NULL
+	) ranked
+	WHERE position > 1
+)`
+
+// backfillReviewEmails prepares reviews written before emails were normalized for the unique
+// index on book and normalized email. It only runs until that index exists.
+func backfillReviewEmails(db *gorm.DB, ignoreGmailDots bool) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&model.Review{}) || migrator.HasIndex(&model.Review{}, utils.ReviewUniqueConstraint) {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if !tx.Migrator().HasColumn(&model.Review{}, "EmailNormalized") {
+			if err := tx.Migrator().AddColumn(&model.Review{}, "EmailNormalized"); err != nil {
+				return err
+			}
+		}
+
+		var reviews []model.Review
+		if err := tx.Unscoped().Select("id", "email").
+			Where("email_normalized IS NULL AND email IS NOT NULL AND email <> ''").
+			Find(&reviews).Error; err != nil {
+			return err
+		}
+		for _, review := range reviews {
+			if err := tx.Unscoped().Model(&model.Review{}).Where("id = ?", review.ID).
+				UpdateColumn("email_normalized", utils.NormalizeEmail(review.Email, ignoreGmailDots)).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Exec(duplicateReviewEmails)
+		if result.Error != nil {
+			return result.Error
+		}
+		log.Printf("Backfilled %d review emails, released %d duplicate reviews", len(reviews), result.RowsAffected)
+		return nil
+	})
+}
+
 func ConnectToDatabase(dsn string) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
@@ -34,10 +102,18 @@ func ConnectToDatabase(dsn string) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	if err := db.AutoMigrate(&model.Book{}, &model.Review{}); err != nil {
+	if err := backfillReviewEmails(db, NewEnvConfig.ReviewIgnoreGmailDots); err != nil {
+		log.Fatalf("Failed to backfill review emails: %v", err)
+	}
+
+	if err := db.AutoMigrate(&model.Book{}, &model.Review{}, &model.DataSubjectRequest{}, &model.ReviewReport{}, &model.ScheduledReport{}, &model.ReportRun{}, &model.DashboardAggregate{}, &model.DashboardAggregateState{}, &model.ReviewAlert{}, &model.BookRedirect{}, &model.BookRevision{}, &model.AuditLog{}, &model.BookChangeRequest{}, &model.BlobDeletion{}, &model.BookImage{}, &model.SpentChallenge{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	if err := db.Exec(auditLogGuard).Error; err != nil {
+		log.Fatalf("Failed to protect audit log: %v", err)
+	}
+
 	DB = Dbinstance{Db: db}
 	log.Println("Database connection established successfully")
 }
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"honya/backend/errors"
+	"honya/backend/utils"
 
 	"github.com/joho/godotenv"
 )
@@ -11,13 +19,47 @@ import (
 type EnvConfig struct {
 	DatabaseURL              string
 	ServerPort               string
+	TrustedProxies           []string
 	LogStack                 string
 	LogRetention             string
 	UrlCleanupOriginalDomain string
+	StorageDriver            string
+	LocalStorageDir          string
+	LocalStorageURL          string
 	AWSBucket                string
 	AWSRegion                string
 	AWSAccessKey             string
 	AWSSecretKey             string
+	S3Endpoint               string
+	S3ForcePathStyle         bool
+	S3PublicURL              string
+	UploadSigningSecret      string
+	AdminApiKey              string
+	ModeratorApiKey          string
+	GdprKeepContent          bool
+	GdprHashSecret           string
+	ReviewIgnoreGmailDots    bool
+	ReviewRateLimitMax       int
+	ReviewRateLimitWindow    time.Duration
+	PowEnabled               bool
+	PowSecret                string
+	PowBaseDifficulty        int
+	PowMaxDifficulty         int
+	ReviewReportThreshold    int
+	SmtpHost                 string
+	SmtpPort                 string
+	SmtpUsername             string
+	SmtpPassword             string
+	MailFrom                 string
+	DashboardRefreshInterval time.Duration
+	AnomalyZThreshold        float64
+	AnomalyMinReviews        int
+	AnomalyHoldDuration      time.Duration
+	AnomalyIgnoredRanges     []string
+	TrashRetention           time.Duration
+	ImageGCGracePeriod       time.Duration
+	ImageGCDryRun            bool
+	ImageGCRevisionRetention time.Duration
 }
 
 var NewEnvConfig EnvConfig
@@ -45,6 +87,14 @@ func GetEnvConfig() (EnvConfig, error) {
 		NewEnvConfig.ServerPort = "8080"
 	}
 
+	// Only requests from these addresses may name the client in X-Forwarded-For. The frontend
+	// posts reviews and reports on behalf of its visitors, so it must be listed here.
+	trustedProxies, err := parseNetworkList("TRUSTED_PROXIES")
+	if err != nil {
+		return NewEnvConfig, err
+	}
+	NewEnvConfig.TrustedProxies = trustedProxies
+
 	NewEnvConfig.LogStack = os.Getenv("LOG_STACK")
 	if NewEnvConfig.LogStack == "" {
 		NewEnvConfig.LogStack = "daily"
@@ -60,25 +110,206 @@ func GetEnvConfig() (EnvConfig, error) {
 		return NewEnvConfig, errors.NewBadRequestError("URL_CLEANUP_ORIGINAL_DOMAIN environment variable is not set")
 	}
 
+	// Without an explicit driver, deployments that configured a bucket keep using it
 	NewEnvConfig.AWSBucket = os.Getenv("AWS_BUCKET_NAME")
-	if NewEnvConfig.AWSBucket == "" {
-		return NewEnvConfig, errors.NewBadRequestError("AWS_BUCKET_NAME environment variable is not set")
+	NewEnvConfig.StorageDriver = os.Getenv("STORAGE_DRIVER")
+	if NewEnvConfig.StorageDriver == "" {
+		if NewEnvConfig.AWSBucket != "" {
+			NewEnvConfig.StorageDriver = "s3"
+		} else {
+			NewEnvConfig.StorageDriver = "local"
+		}
 	}
 
-	NewEnvConfig.AWSRegion = os.Getenv("AWS_REGION")
-	if NewEnvConfig.AWSRegion == "" {
-		return NewEnvConfig, errors.NewBadRequestError("AWS_REGION environment variable is not set")
+	NewEnvConfig.LocalStorageDir = os.Getenv("LOCAL_STORAGE_DIR")
+	if NewEnvConfig.LocalStorageDir == "" {
+		NewEnvConfig.LocalStorageDir = "uploads"
 	}
 
-	NewEnvConfig.AWSAccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
-	if NewEnvConfig.AWSAccessKey == "" {
-		return NewEnvConfig, errors.NewBadRequestError("AWS_ACCESS_KEY_ID environment variable is not set")
+	NewEnvConfig.LocalStorageURL = strings.TrimSuffix(os.Getenv("LOCAL_STORAGE_URL"), "/")
+	if NewEnvConfig.LocalStorageURL == "" {
+		NewEnvConfig.LocalStorageURL = "http://localhost:" + NewEnvConfig.ServerPort + "/uploads"
 	}
 
+	NewEnvConfig.S3Endpoint = strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/")
+	NewEnvConfig.S3ForcePathStyle = os.Getenv("S3_FORCE_PATH_STYLE") == "true"
+	NewEnvConfig.S3PublicURL = strings.TrimSuffix(os.Getenv("S3_PUBLIC_URL"), "/")
+
+	// Signs direct upload URLs received by the API; without it they only work on the issuing instance
+	NewEnvConfig.UploadSigningSecret = os.Getenv("UPLOAD_SIGNING_SECRET")
+
+	// Credentials are optional so that instance roles and the shared AWS config keep working
+	NewEnvConfig.AWSRegion = os.Getenv("AWS_REGION")
+	NewEnvConfig.AWSAccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
 	NewEnvConfig.AWSSecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
-	if NewEnvConfig.AWSSecretKey == "" {
-		return NewEnvConfig, errors.NewBadRequestError("AWS_SECRET_ACCESS_KEY environment variable is not set")
+
+	if NewEnvConfig.StorageDriver == "s3" {
+		if NewEnvConfig.AWSBucket == "" {
+			return NewEnvConfig, errors.NewBadRequestError("AWS_BUCKET_NAME environment variable is not set")
+		}
+		if NewEnvConfig.AWSRegion == "" {
+			if NewEnvConfig.S3Endpoint == "" {
+				return NewEnvConfig, errors.NewBadRequestError("AWS_REGION environment variable is not set")
+			}
+			// S3-compatible servers such as MinIO accept any region
+			NewEnvConfig.AWSRegion = "us-east-1"
+		}
+	}
+
+	// API keys are optional; without them every request is treated as public
+	NewEnvConfig.AdminApiKey = os.Getenv("ADMIN_API_KEY")
+	NewEnvConfig.ModeratorApiKey = os.Getenv("MODERATOR_API_KEY")
+	if NewEnvConfig.AdminApiKey != "" && NewEnvConfig.AdminApiKey == NewEnvConfig.ModeratorApiKey {
+		return NewEnvConfig, errors.NewBadRequestError("ADMIN_API_KEY and MODERATOR_API_KEY must differ")
+	}
+
+	// Whether erased reviews keep their text once the reviewer is anonymized
+	NewEnvConfig.GdprKeepContent = os.Getenv("GDPR_KEEP_CONTENT") != "false"
+
+	// Keys the email hashes of the data subject request log and the reporter hashes of review
+	// reports; changing it orphans earlier entries
+	NewEnvConfig.GdprHashSecret = os.Getenv("GDPR_HASH_SECRET")
+	if NewEnvConfig.GdprHashSecret == "" {
+		NewEnvConfig.GdprHashSecret = fallbackGdprHashSecret()
+	}
+
+	NewEnvConfig.ReviewIgnoreGmailDots = os.Getenv("REVIEW_IGNORE_GMAIL_DOTS") != "false"
+
+	reviewRateLimitMax, err := strconv.Atoi(os.Getenv("REVIEW_RATE_LIMIT_MAX"))
+	if err != nil || reviewRateLimitMax <= 0 {
+		reviewRateLimitMax = 5 // Default to 5 reviews per window
+	}
+	NewEnvConfig.ReviewRateLimitMax = reviewRateLimitMax
+
+	reviewRateLimitWindow, err := time.ParseDuration(os.Getenv("REVIEW_RATE_LIMIT_WINDOW"))
+	if err != nil || reviewRateLimitWindow <= 0 {
+		reviewRateLimitWindow = time.Hour
+	}
+	NewEnvConfig.ReviewRateLimitWindow = reviewRateLimitWindow
+
+	NewEnvConfig.PowEnabled = os.Getenv("POW_ENABLED") != "false"
+
+	// Without a shared secret, challenges are only valid on the instance that issued them
+	NewEnvConfig.PowSecret = os.Getenv("POW_SECRET")
+
+	powBaseDifficulty, err := strconv.Atoi(os.Getenv("POW_BASE_DIFFICULTY"))
+	if err != nil || powBaseDifficulty <= 0 {
+		powBaseDifficulty = 16
+	}
+	NewEnvConfig.PowBaseDifficulty = powBaseDifficulty
+
+	powMaxDifficulty, err := strconv.Atoi(os.Getenv("POW_MAX_DIFFICULTY"))
+	if err != nil || powMaxDifficulty < powBaseDifficulty {
+		powMaxDifficulty = powBaseDifficulty + 8
+	}
+	NewEnvConfig.PowMaxDifficulty = powMaxDifficulty
+
+	reviewReportThreshold, err := strconv.Atoi(os.Getenv("REVIEW_REPORT_THRESHOLD"))
+	if err != nil || reviewReportThreshold <= 0 {
+		reviewReportThreshold = 3
 	}
+	NewEnvConfig.ReviewReportThreshold = reviewReportThreshold
+
+	NewEnvConfig.SmtpHost = os.Getenv("SMTP_HOST")
+	NewEnvConfig.SmtpPort = os.Getenv("SMTP_PORT")
+	if NewEnvConfig.SmtpPort == "" {
+		NewEnvConfig.SmtpPort = "587"
+	}
+	NewEnvConfig.SmtpUsername = os.Getenv("SMTP_USERNAME")
+	NewEnvConfig.SmtpPassword = os.Getenv("SMTP_PASSWORD")
+	NewEnvConfig.MailFrom = os.Getenv("MAIL_FROM")
+	if NewEnvConfig.MailFrom == "" {
+		NewEnvConfig.MailFrom = "reports@honya.local"
+	}
+
+	dashboardRefreshInterval, err := time.ParseDuration(os.Getenv("DASHBOARD_REFRESH_INTERVAL"))
+	if err != nil || dashboardRefreshInterval <= 0 {
+		dashboardRefreshInterval = time.Hour
+	}
+	NewEnvConfig.DashboardRefreshInterval = dashboardRefreshInterval
+
+	anomalyZThreshold, err := strconv.ParseFloat(os.Getenv("ANOMALY_Z_THRESHOLD"), 64)
+	if err != nil || anomalyZThreshold <= 0 {
+		anomalyZThreshold = 3
+	}
+	NewEnvConfig.AnomalyZThreshold = anomalyZThreshold
+
+	anomalyMinReviews, err := strconv.Atoi(os.Getenv("ANOMALY_MIN_REVIEWS"))
+	if err != nil || anomalyMinReviews <= 0 {
+		anomalyMinReviews = 5
+	}
+	NewEnvConfig.AnomalyMinReviews = anomalyMinReviews
+
+	anomalyHoldDuration, err := time.ParseDuration(os.Getenv("ANOMALY_HOLD_DURATION"))
+	if err != nil || anomalyHoldDuration <= 0 {
+		anomalyHoldDuration = 24 * time.Hour
+	}
+	NewEnvConfig.AnomalyHoldDuration = anomalyHoldDuration
+
+	// Shared gateways such as corporate or carrier NATs, on top of the trusted proxies
+	anomalyIgnoredRanges, err := parseNetworkList("ANOMALY_IGNORED_RANGES")
+	if err != nil {
+		return NewEnvConfig, err
+	}
+	NewEnvConfig.AnomalyIgnoredRanges = anomalyIgnoredRanges
+
+	trashRetention, err := time.ParseDuration(os.Getenv("TRASH_RETENTION"))
+	if err != nil || trashRetention <= 0 {
+		trashRetention = 30 * 24 * time.Hour
+	}
+	NewEnvConfig.TrashRetention = trashRetention
+
+	// Blobs younger than this are never collected, so a cover whose book is still being saved
+	// and an upload that is not finalized yet are safe
+	imageGCGracePeriod, err := time.ParseDuration(os.Getenv("IMAGE_GC_GRACE_PERIOD"))
+	if err != nil || imageGCGracePeriod <= 0 {
+		imageGCGracePeriod = 24 * time.Hour
+	}
+	NewEnvConfig.ImageGCGracePeriod = imageGCGracePeriod
+
+	NewEnvConfig.ImageGCDryRun = os.Getenv("IMAGE_GC_DRY_RUN") == "true"
+
+	// How long a book revision keeps its covers from being collected, so it can still be
+	// restored with them
+	imageGCRevisionRetention, err := time.ParseDuration(os.Getenv("IMAGE_GC_REVISION_RETENTION"))
+	if err != nil || imageGCRevisionRetention <= 0 {
+		imageGCRevisionRetention = 90 * 24 * time.Hour
+	}
+	NewEnvConfig.ImageGCRevisionRetention = imageGCRevisionRetention
 
 	return NewEnvConfig, nil
 }
+
+// parseNetworkList reads a comma-separated list of addresses and CIDR ranges
+func parseNetworkList(name string) ([]string, error) {
+	values := []string{}
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if _, err := utils.ParseNetwork(value); err != nil {
+			return nil, errors.NewBadRequestError(name + " contains an invalid address or range: " + value)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+var (
+	gdprHashSecretOnce      sync.Once
+	generatedGdprHashSecret string
+)
+
+// fallbackGdprHashSecret keeps deployments without GDPR_HASH_SECRET starting, with a random
+// secret that lasts until the process exits. Entries logged under it no longer match a search
+// by email after a restart, so the missing setting is reported loudly.
+func fallbackGdprHashSecret() string {
+	gdprHashSecretOnce.Do(func() {
+		key := make([]byte, 32)
+		_, _ = rand.Read(key)
+		generatedGdprHashSecret = hex.EncodeToString(key)
+		log.Printf("WARNING: GDPR_HASH_SECRET is not set. Using a random secret until the next restart; data subject requests logged until then cannot be searched by email afterwards. Set GDPR_HASH_SECRET to a long random value.")
+	})
+	return generatedGdprHashSecret
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AnomalyController interface {
+	GetAlerts(ctx *fiber.Ctx) error
+}
+
+type anomalyController struct {
+	service service.AnomalyService
+}
+
+func NewAnomalyController(service service.AnomalyService) AnomalyController {
+	return &anomalyController{service}
+}
+
+// GetAlerts godoc
+// @Summary Get review activity alerts
+// @Description Get review spikes and single-source bursts detected by the anomaly analyzer, newest first. Each alert holds new reviews of its book for moderation until held_until.
+// @Tags dashboard
+// @Produce json
+// @Param book_id query string false "Only alerts for this book"
+// @Param type query string false "volume_spike, email_domain_burst or ip_range_burst"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.ReviewAlertListResponse "Alerts fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /dashboard/alerts [get]
+func (c *anomalyController) GetAlerts(ctx *fiber.Ctx) error {
+	params := dto.ReviewAlertQueryParams{
+		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+		Type:   ctx.Query("type"),
+	}
+
+	if bookID := ctx.Query("book_id"); bookID != "" {
+		id, err := uuid.Parse(bookID)
+		if err != nil {
+			return errors.NewBadRequestError("Invalid book_id")
+		}
+		params.BookID = id
+	}
+
+	alerts, meta, err := c.service.GetAlerts(params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ReviewAlertListResponse{
+		Meta: *meta,
+		Data: alerts,
+	})
+}
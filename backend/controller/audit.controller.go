@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditController interface {
+	GetLogs(ctx *fiber.Ctx) error
+	Verify(ctx *fiber.Ctx) error
+}
+
+type auditController struct {
+	service service.AuditService
+}
+
+func NewAuditController(service service.AuditService) AuditController {
+	return &auditController{service}
+}
+
+// GetLogs godoc
+// @Summary List audit log entries
+// @Description List recorded mutating API calls, newest first: who made each one, from which IP and request, on which route, and what it changed.
+// @Tags audit
+// @Produce json
+// @Param actor query string false "public, moderator or admin"
+// @Param entity_type query string false "Entity type, e.g. book, review, scheduled_report"
+// @Param entity_id query string false "Entity ID"
+// @Param action query string false "Action, e.g. create, update, delete"
+// @Param from query integer false "Only entries at or after this Unix time"
+// @Param to query integer false "Only entries at or before this Unix time"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.AuditLogListResponse "Audit log fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 403 {object} errors.ErrorResponse "Insufficient permissions"
+// @Router /audit [get]
+func (c *auditController) GetLogs(ctx *fiber.Ctx) error {
+	params := dto.AuditQueryParams{
+		Actor:      ctx.Query("actor"),
+		EntityType: ctx.Query("entity_type"),
+		EntityID:   ctx.Query("entity_id"),
+		Action:     ctx.Query("action"),
+		Offset:     utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:      utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+	}
+	params.From, _ = strconv.ParseInt(ctx.Query("from"), 10, 64)
+	params.To, _ = strconv.ParseInt(ctx.Query("to"), 10, 64)
+
+	entries, meta, err := c.service.GetLogs(params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.AuditLogListResponse{
+		Meta: *meta,
+		Data: entries,
+	})
+}
+
+// Verify godoc
+// @Summary Verify the audit log
+// @Description Walk the hash chain from the first entry and report the first entry that was altered or whose predecessor was removed.
+// @Tags audit
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.AuditVerifyResponse "Audit log verified"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 403 {object} errors.ErrorResponse "Insufficient permissions"
+// @Router /audit/verify [get]
+func (c *auditController) Verify(ctx *fiber.Ctx) error {
+	result, err := c.service.Verify()
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
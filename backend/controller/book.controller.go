@@ -18,6 +18,11 @@ type BookController interface {
 	CreateBook(ctx *fiber.Ctx) error
 	UpdateBook(ctx *fiber.Ctx) error
 	DeleteBook(ctx *fiber.Ctx) error
+	GetDuplicates(ctx *fiber.Ctx) error
+	MergeBook(ctx *fiber.Ctx) error
+	GetRevisions(ctx *fiber.Ctx) error
+	RestoreRevision(ctx *fiber.Ctx) error
+	RestoreBook(ctx *fiber.Ctx) error
 }
 
 type bookController struct {
@@ -42,6 +47,7 @@ func NewBookController(service service.BookService) BookController {
 // @Param rating query number false "Filter by minimum rating"
 // @Param pages query int false "Filter by minimum number of pages"
 // @Param sort query string false "Sort by field (Options: title_asc, title_desc, year_asc, year_desc, rating_asc, rating_desc)" default(title_asc)
+// @Param status query string false "Admin/moderator only: draft, scheduled, published or archived. Public callers only see published books; archived books are left out unless asked for"
 // @Success 200 {object} dto.BookListResponse "List of books fetched successfully"
 // @Failure 400 {object} errors.ErrorResponse "Invalid query parameters"
 // @Router /books [get]
@@ -55,6 +61,12 @@ func (c *bookController) GetBooks(ctx *fiber.Ctx) error {
 		Rating:          utils.ParseFloat(ctx.Query("rating"), utils.DefaultRating),
 		Pages:           utils.ParseInt(ctx.Query("pages"), utils.DefaultPages),
 		Sort:            strings.ToLower(ctx.Query("sort")),
+		Status:          strings.ToLower(ctx.Query("status")),
+	}
+
+	// Drafts, scheduled and archived books are only listed for editors
+	if !utils.IsPrivileged(ctx) {
+		params.Status = utils.BookStatusPublished
 	}
 
 	books, meta, err := c.service.GetBooks(params)
@@ -75,6 +87,7 @@ func (c *bookController) GetBooks(ctx *fiber.Ctx) error {
 // @Param id path string true "Book ID"
 // @Success 200 {object} dto.BookResponse "Book details fetched successfully"
 // @Failure 400 {object} errors.ErrorResponse "Invalid ID format"
+// @Success 301 "The book was merged into another one; Location points at it"
 // @Failure 404 {object} errors.ErrorResponse "Book not found"
 // @Router /books/{id} [get]
 func (c *bookController) GetBookByID(ctx *fiber.Ctx) error {
@@ -85,9 +98,22 @@ func (c *bookController) GetBookByID(ctx *fiber.Ctx) error {
 
 	book, err := c.service.GetBookByID(id)
 	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == fiber.StatusNotFound {
+			redirect, redirectErr := c.service.FindRedirect(id)
+			if redirectErr != nil {
+				return redirectErr
+			}
+			if redirect != nil {
+				return ctx.Redirect("/api/books/"+redirect.ToID.String(), fiber.StatusMovedPermanently)
+			}
+		}
 		return err
 	}
 
+	if !utils.IsPrivileged(ctx) && !utils.IsBookVisible(book.Status) {
+		return errors.NewNotFoundError("Book not found")
+	}
+
 	result := dto.ToBookResponse(book)
 	return ctx.Status(fiber.StatusOK).JSON(result)
 }
@@ -106,7 +132,9 @@ func (c *bookController) GetBookByID(ctx *fiber.Ctx) error {
 // @Param pages formData int true "Number of pages"
 // @Param isbn formData string true "Book ISBN (must be unique)"
 // @Param author_name formData string true "Author name"
-// @Param image formData file false "Book cover image"
+// @Param status formData string false "draft, scheduled or published" default(published)
+// @Param publish_at formData int false "Unix time a scheduled book goes live"
+// @Param image formData file false "Book cover image (JPEG, PNG or WebP, at most 8 MB)"
 // @Success 201 {object} dto.BookResponse "Book created successfully"
 // @Failure 400 {object} errors.ErrorResponse "Invalid input data"
 // @Failure 409 {object} errors.ErrorResponse "A book with this ISBN already exists"
@@ -121,6 +149,8 @@ func (c *bookController) CreateBook(ctx *fiber.Ctx) error {
 	reqData.Pages, _ = strconv.Atoi(ctx.FormValue("pages"))
 	reqData.Isbn = ctx.FormValue("isbn")
 	reqData.AuthorName = ctx.FormValue("author_name")
+	reqData.Status = strings.ToLower(ctx.FormValue("status"))
+	reqData.PublishAt, _ = strconv.ParseInt(ctx.FormValue("publish_at"), 10, 64)
 
 	// Get uploaded file
 	var fileHeader *multipart.FileHeader
@@ -137,6 +167,8 @@ func (c *bookController) CreateBook(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	utils.SetAudit(ctx, utils.AuditEntityBook, book.ID.String(), utils.AuditActionCreate, "Created book")
+
 	result := dto.ToBookResponse(book)
 
 	return ctx.Status(fiber.StatusCreated).JSON(result)
@@ -155,6 +187,9 @@ func (c *bookController) CreateBook(ctx *fiber.Ctx) error {
 // @Param publication_year formData int false "Publication year"
 // @Param rating formData number false "Book rating"
 // @Param pages formData int false "Number of pages"
+// @Param status formData string false "New status: draft, scheduled, published or archived"
+// @Param publish_at formData int false "Unix time a scheduled book goes live"
+// @Param reason formData string false "Why the book is being changed, kept in its revision history"
 func (c *bookController) UpdateBook(ctx *fiber.Ctx) error {
 	id, err := utils.ParseUUIDParam(ctx, "id")
 	if err != nil {
@@ -192,6 +227,15 @@ func (c *bookController) UpdateBook(ctx *fiber.Ctx) error {
 		if author := ctx.FormValue("author_name"); author != "" {
 			requestData.AuthorName = &author
 		}
+		if status := ctx.FormValue("status"); status != "" {
+			status = strings.ToLower(status)
+			requestData.Status = &status
+		}
+		if publishAt := ctx.FormValue("publish_at"); publishAt != "" {
+			publishAtInt, _ := strconv.ParseInt(publishAt, 10, 64)
+			requestData.PublishAt = &publishAtInt
+		}
+		requestData.Reason = ctx.FormValue("reason")
 
 		file, err := ctx.FormFile("image")
 		if err == nil {
@@ -206,23 +250,32 @@ func (c *bookController) UpdateBook(ctx *fiber.Ctx) error {
 	if requestData.Isbn != nil {
 		return errors.NewBadRequestError("ISBN cannot be updated once set")
 	}
+	if requestData.Images != nil {
+		return errors.NewBadRequestError("Image variants are generated from an uploaded cover")
+	}
 
 	if err := utils.ValidateBookUpdateRequest(&requestData); err != nil {
 		return errors.NewBadRequestError(err.Error())
 	}
 
-	updatedBook, err := c.service.UpdateBook(id, &requestData, fileHeader)
+	updatedBook, err := c.service.UpdateBook(id, &requestData, fileHeader, utils.GetRole(ctx))
 	if err != nil {
 		return err
 	}
 
+	changed := []string{}
+	if fileHeader != nil {
+		changed = append(changed, "image")
+	}
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionUpdate, utils.AuditFields(&requestData, changed...))
+
 	result := dto.ToBookResponse(updatedBook)
 	return ctx.Status(fiber.StatusOK).JSON(result)
 }
 
 // DeleteBook godoc
 // @Summary Delete a book
-// @Description Delete a book by its ID
+// @Description Move a book and its reviews to the trash. They can be restored until the trash is purged.
 // @Tags books
 // @Accept json
 // @Produce json
@@ -241,7 +294,167 @@ func (c *bookController) DeleteBook(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionDelete, "Moved book to trash")
+
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Book deleted successfully",
 	})
 }
+
+// GetDuplicates godoc
+// @Summary Find duplicate books
+// @Description List pairs of books that share an ISBN (ISBN-10 and ISBN-13 compare equal) or have near-identical titles and authors, most likely duplicates first
+// @Tags books
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.BookDuplicateListResponse "Duplicate candidates"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /books/duplicates [get]
+func (c *bookController) GetDuplicates(ctx *fiber.Ctx) error {
+	duplicates, err := c.service.FindDuplicates()
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.BookDuplicateListResponse{Data: duplicates})
+}
+
+// MergeBook godoc
+// @Summary Merge a book into another
+// @Description Merge the source book into this one: its reviews move over, fields are reconciled by the strategy (keep_target, fill_empty or prefer_source), its ID redirects here, and its cover, unless taken over, is left to the image garbage collector
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID to merge into"
+// @Param request body dto.BookMergeRequest true "Book to merge and strategy"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.BookResponse "Merged book"
+// @Failure 400 {object} errors.ErrorResponse "Invalid request"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 404 {object} errors.ErrorResponse "Book not found"
+// @Router /books/{id}/merge [post]
+func (c *bookController) MergeBook(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.BookMergeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	book, err := c.service.MergeBooks(id, &req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	summary := "Merged book " + req.SourceID.String()
+	if req.Strategy != "" {
+		summary += " (" + req.Strategy + ")"
+	}
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionMerge, summary)
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookResponse(book))
+}
+
+// GetRevisions godoc
+// @Summary Get a book's revision history
+// @Description List the changes made to a book, newest first, each with its field-level before and after values, actor and reason
+// @Tags books
+// @Produce json
+// @Param id path string true "Book ID"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Success 200 {object} dto.BookRevisionListResponse "Revisions fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} errors.ErrorResponse "Book not found"
+// @Router /books/{id}/revisions [get]
+func (c *bookController) GetRevisions(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	revisions, meta, err := c.service.GetRevisions(id,
+		utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+		utils.IsPrivileged(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookRevisionListResponse(revisions, *meta))
+}
+
+// RestoreRevision godoc
+// @Summary Roll a book back to before a revision
+// @Description Undo the given revision and every later one, including cover image changes unless the image GC has deleted that cover since. The rollback is recorded as a new revision.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID"
+// @Param rev path integer true "Revision number"
+// @Param request body dto.BookRevisionRestoreRequest false "Why the book is being rolled back"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.BookResponse "Restored book"
+// @Failure 400 {object} errors.ErrorResponse "Invalid request"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 404 {object} errors.ErrorResponse "Book or revision not found"
+// @Router /books/{id}/revisions/{rev}/restore [post]
+func (c *bookController) RestoreRevision(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	number, err := strconv.Atoi(ctx.Params("rev"))
+	if err != nil || number < 1 {
+		return errors.NewBadRequestError("Invalid revision number")
+	}
+
+	var req dto.BookRevisionRestoreRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return errors.NewBadRequestError("Invalid JSON body")
+		}
+	}
+
+	book, err := c.service.RestoreRevision(id, number, &req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionRestore, "Restored revision "+strconv.Itoa(number))
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookResponse(book))
+}
+
+// RestoreBook godoc
+// @Summary Restore a book from the trash
+// @Description Restore a deleted book together with the reviews deleted along with it
+// @Tags books
+// @Produce json
+// @Param id path string true "Book ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.BookResponse "Restored book"
+// @Failure 400 {object} errors.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 404 {object} errors.ErrorResponse "Book not found in trash"
+// @Router /books/{id}/restore [post]
+func (c *bookController) RestoreBook(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	book, err := c.service.RestoreBook(id)
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionRestore, "Restored book from trash")
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookResponse(book))
+}
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type BookChangeRequestController interface {
+	CreateRequest(ctx *fiber.Ctx) error
+	GetRequest(ctx *fiber.Ctx) error
+	GetRequests(ctx *fiber.Ctx) error
+	AcceptRequest(ctx *fiber.Ctx) error
+	RejectRequest(ctx *fiber.Ctx) error
+}
+
+type bookChangeRequestController struct {
+	service    service.BookChangeRequestService
+	challenges service.ChallengeService
+}
+
+func NewBookChangeRequestController(service service.BookChangeRequestService, challenges service.ChallengeService) BookChangeRequestController {
+	return &bookChangeRequestController{service, challenges}
+}
+
+// CreateRequest godoc
+// @Summary Suggest an edit to a book
+// @Description Propose corrections to a book's title, description, category, publication year, rating, pages or author. Editors review the proposal; keep the returned ID to track its status. Requires a solved proof-of-work challenge from GET /reviews/challenge.
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID"
+// @Param request body dto.BookChangeRequestCreateRequest true "Proposed changes"
+// @Success 201 {object} dto.BookChangeRequestResponse "Change request created successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data"
+// @Failure 404 {object} errors.ErrorResponse "Book not found"
+// @Failure 429 {object} errors.ErrorResponse "Too many changes suggested"
+// @Router /books/{id}/change-requests [post]
+func (c *bookChangeRequestController) CreateRequest(ctx *fiber.Ctx) error {
+	bookID, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.BookChangeRequestCreateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	if err := c.challenges.VerifySolution(req.PowChallenge, req.PowSolution); err != nil {
+		return err
+	}
+
+	request, err := c.service.CreateRequest(bookID, &req)
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityChangeRequest, request.ID.String(), utils.AuditActionCreate, "Proposed changes to book "+bookID.String())
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ToBookChangeRequestResponse(request, utils.IsPrivileged(ctx)))
+}
+
+// GetRequest godoc
+// @Summary Get a change request
+// @Description Track a change request: its proposed changes, status and the editor's note. The proposer's name and email are only shown to admins and moderators.
+// @Tags change-requests
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Success 200 {object} dto.BookChangeRequestResponse "Change request fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid ID"
+// @Failure 404 {object} errors.ErrorResponse "Change request not found"
+// @Router /change-requests/{id} [get]
+func (c *bookChangeRequestController) GetRequest(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	request, err := c.service.GetRequest(id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookChangeRequestResponse(request, utils.IsPrivileged(ctx)))
+}
+
+// GetRequests godoc
+// @Summary List change requests
+// @Description List change requests with a given status, oldest first, each with the diff between the book's values when proposed and the proposed values.
+// @Tags change-requests
+// @Produce json
+// @Param status query string false "pending, accepted or rejected" default(pending)
+// @Param book_id query string false "Only change requests for this book"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.BookChangeRequestListResponse "Change requests fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /change-requests [get]
+func (c *bookChangeRequestController) GetRequests(ctx *fiber.Ctx) error {
+	params := dto.BookChangeRequestQueryParams{
+		Status: ctx.Query("status"),
+		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+	}
+	if bookID := ctx.Query("book_id"); bookID != "" {
+		id, err := uuid.Parse(bookID)
+		if err != nil {
+			return errors.NewBadRequestError("Invalid book_id")
+		}
+		params.BookID = &id
+	}
+
+	requests, meta, err := c.service.GetRequests(params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookChangeRequestListResponse(requests, *meta, true))
+}
+
+// AcceptRequest godoc
+// @Summary Accept a change request
+// @Description Apply a pending change request to its book. The change is recorded as a book revision that names the change request.
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param review body dto.BookChangeRequestReviewRequest false "Optional note for the proposer"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.BookChangeRequestResponse "Change request accepted"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 404 {object} errors.ErrorResponse "Change request or book not found"
+// @Failure 409 {object} errors.ErrorResponse "Change request already resolved"
+// @Router /change-requests/{id}/accept [post]
+func (c *bookChangeRequestController) AcceptRequest(ctx *fiber.Ctx) error {
+	id, req, err := parseChangeRequestReview(ctx)
+	if err != nil {
+		return err
+	}
+
+	request, err := c.service.AcceptRequest(id, req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityChangeRequest, id.String(), utils.AuditActionAccept, "Applied changes to book "+request.BookID.String())
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookChangeRequestResponse(request, true))
+}
+
+// RejectRequest godoc
+// @Summary Reject a change request
+// @Description Reject a pending change request with a note explaining why; the proposer sees the note when tracking it.
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param review body dto.BookChangeRequestReviewRequest true "Note for the proposer"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.BookChangeRequestResponse "Change request rejected"
+// @Failure 400 {object} errors.ErrorResponse "A note is required"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 404 {object} errors.ErrorResponse "Change request not found"
+// @Failure 409 {object} errors.ErrorResponse "Change request already resolved"
+// @Router /change-requests/{id}/reject [post]
+func (c *bookChangeRequestController) RejectRequest(ctx *fiber.Ctx) error {
+	id, req, err := parseChangeRequestReview(ctx)
+	if err != nil {
+		return err
+	}
+
+	request, err := c.service.RejectRequest(id, req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityChangeRequest, id.String(), utils.AuditActionReject, "Rejected changes to book "+request.BookID.String())
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookChangeRequestResponse(request, true))
+}
+
+func parseChangeRequestReview(ctx *fiber.Ctx) (uuid.UUID, *dto.BookChangeRequestReviewRequest, error) {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	var req dto.BookChangeRequestReviewRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return uuid.Nil, nil, errors.NewBadRequestError("Invalid JSON body")
+		}
+	}
+
+	return id, &req, nil
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BookImageController interface {
+	GetImages(ctx *fiber.Ctx) error
+	AddImage(ctx *fiber.Ctx) error
+	UpdateImage(ctx *fiber.Ctx) error
+	ReorderImages(ctx *fiber.Ctx) error
+	DeleteImage(ctx *fiber.Ctx) error
+}
+
+type bookImageController struct {
+	service service.BookImageService
+}
+
+func NewBookImageController(service service.BookImageService) BookImageController {
+	return &bookImageController{service}
+}
+
+// GetImages godoc
+// @Summary List a book's images
+// @Description List the gallery of a book in order: front, back and spine covers and sample pages, each with its alt text and generated variants.
+// @Tags book-images
+// @Produce json
+// @Param id path string true "Book ID"
+// @Success 200 {object} dto.BookImageListResponse "Images fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} errors.ErrorResponse "Book not found"
+// @Router /books/{id}/images [get]
+func (c *bookImageController) GetImages(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	images, err := c.service.GetImages(id, utils.IsPrivileged(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookImageListResponse(images))
+}
+
+// AddImage godoc
+// @Summary Add an image to a book
+// @Description Upload an image to a book's gallery. It is validated and processed like a cover. The first front cover of the gallery becomes the book's image.
+// @Tags book-images
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Book ID"
+// @Param image formData file true "JPEG, PNG or WebP image"
+// @Param role formData string true "front, back, spine or sample"
+// @Param alt_en formData string false "English alt text"
+// @Param alt_ja formData string false "Japanese alt text"
+// @Param position formData integer false "Position to insert the image at; appended when omitted"
+// @Success 201 {object} dto.BookImageResponse "Image added successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data or image"
+// @Failure 404 {object} errors.ErrorResponse "Book not found"
+// @Router /books/{id}/images [post]
+func (c *bookImageController) AddImage(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := dto.BookImageCreateRequest{
+		Role:  ctx.FormValue("role"),
+		AltEn: ctx.FormValue("alt_en"),
+		AltJa: ctx.FormValue("alt_ja"),
+	}
+	if position := ctx.FormValue("position"); position != "" {
+		positionInt, err := strconv.Atoi(position)
+		if err != nil {
+			return errors.NewBadRequestError("position must be an integer")
+		}
+		req.Position = &positionInt
+	}
+
+	fileHeader, err := ctx.FormFile("image")
+	if err != nil {
+		return errors.NewBadRequestError("image is required")
+	}
+
+	image, err := c.service.AddImage(id, &req, fileHeader, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionUpdate, "Added "+image.Role+" image "+image.ID.String())
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ToBookImageResponse(image))
+}
+
+// UpdateImage godoc
+// @Summary Update a book image
+// @Description Change the role or alt text of an image in a book's gallery.
+// @Tags book-images
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID"
+// @Param imageId path string true "Image ID"
+// @Param image body dto.BookImageUpdateRequest true "Fields to change"
+// @Success 200 {object} dto.BookImageResponse "Image updated successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data"
+// @Failure 404 {object} errors.ErrorResponse "Book or image not found"
+// @Router /books/{id}/images/{imageId} [patch]
+func (c *bookImageController) UpdateImage(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+	imageID, err := utils.ParseUUIDParam(ctx, "imageId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.BookImageUpdateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	image, err := c.service.UpdateImage(id, imageID, &req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionUpdate, "Updated image "+imageID.String())
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookImageResponse(image))
+}
+
+// ReorderImages godoc
+// @Summary Reorder a book's images
+// @Description Put a book's gallery in a new order. The IDs must list every image of the book exactly once.
+// @Tags book-images
+// @Accept json
+// @Produce json
+// @Param id path string true "Book ID"
+// @Param order body dto.BookImageOrderRequest true "Image IDs in their new order"
+// @Success 200 {object} dto.BookImageListResponse "Images reordered successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data"
+// @Failure 404 {object} errors.ErrorResponse "Book not found"
+// @Router /books/{id}/images/order [put]
+func (c *bookImageController) ReorderImages(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.BookImageOrderRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	images, err := c.service.ReorderImages(id, &req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionUpdate, "Reordered images")
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookImageListResponse(images))
+}
+
+// DeleteImage godoc
+// @Summary Delete a book image
+// @Description Remove an image from a book's gallery. Removing the primary front cover makes the next front cover the book's image.
+// @Tags book-images
+// @Produce json
+// @Param id path string true "Book ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} map[string]string "Image deleted successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} errors.ErrorResponse "Book or image not found"
+// @Router /books/{id}/images/{imageId} [delete]
+func (c *bookImageController) DeleteImage(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+	imageID, err := utils.ParseUUIDParam(ctx, "imageId")
+	if err != nil {
+		return err
+	}
+
+	if err := c.service.DeleteImage(id, imageID, utils.GetRole(ctx)); err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityBook, id.String(), utils.AuditActionUpdate, "Deleted image "+imageID.String())
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Image deleted successfully",
+	})
+}
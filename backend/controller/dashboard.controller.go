@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"fmt"
+	"honya/backend/dto"
+	"honya/backend/errors"
 	"honya/backend/service"
 	"honya/backend/utils"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,6 +15,8 @@ import (
 type DashboardController interface {
 	GetBooksData(ctx *fiber.Ctx) error
 	GetReviewsData(ctx *fiber.Ctx) error
+	GetTimeSeries(ctx *fiber.Ctx) error
+	RunQuery(ctx *fiber.Ctx) error
 }
 
 type dashboardController struct {
@@ -27,8 +33,12 @@ func NewDashboardController(service service.DashboardService) DashboardControlle
 // @Tags dashboard
 // @Accept json
 // @Produce json
-// @Param filter_by query string false "Filter by"
-// @Success 200 {object} model.Book
+// @Param filter_by query string false "Filter by (category, rating, author, publication_year, pages)"
+// @Param bucket query string false "Bucketing for numeric fields (width, edges, decade)"
+// @Param width query number false "Bucket width for width bucketing"
+// @Param edges query string false "Comma-separated bucket edges for edges bucketing"
+// @Param format query string false "Response format (json, csv, xlsx)" default(json)
+// @Success 200 {object} dto.DonutChartData
 // @Router /dashboard/books [get]
 func (c *dashboardController) GetBooksData(ctx *fiber.Ctx) error {
 	// GetBooksData godoc
@@ -40,9 +50,14 @@ func (c *dashboardController) GetBooksData(ctx *fiber.Ctx) error {
 	// @Param filter_by query string false "Filter by"
 	// @Success 200 {object} model.Book
 	// @Router /dashboard/books [get]
-	filterBy := ctx.Query("filter_by", utils.DefaultDonutChartFilterBy)
+	query := dto.BooksDataQuery{
+		FilterBy: ctx.Query("filter_by", utils.DefaultDonutChartFilterBy),
+		Bucket:   ctx.Query("bucket"),
+		Width:    utils.ParseFloat(ctx.Query("width"), 0),
+		Edges:    ctx.Query("edges"),
+	}
 
-	data, err := c.service.GetBooksData(filterBy)
+	data, err := c.service.GetBooksData(query)
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":   "Failed to retrieve dashboard data",
@@ -50,7 +65,7 @@ func (c *dashboardController) GetBooksData(ctx *fiber.Ctx) error {
 		})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(data)
+	return sendDashboardData(ctx, data, "books-data")
 }
 
 func (c *dashboardController) GetReviewsData(ctx *fiber.Ctx) error {
@@ -61,6 +76,7 @@ func (c *dashboardController) GetReviewsData(ctx *fiber.Ctx) error {
 	// @Accept json
 	// @Produce json
 	// @Param limit query string false "Limit"
+	// @Param format query string false "Response format (json, csv, xlsx)"
 	// @Success 200 {object} model.Review
 	limitStr := ctx.Query("limit", "10")
 	limit, err := strconv.Atoi(limitStr)
@@ -81,5 +97,89 @@ func (c *dashboardController) GetReviewsData(ctx *fiber.Ctx) error {
 		})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(data)
+	return sendDashboardData(ctx, data, "reviews-data")
+}
+
+// GetTimeSeries godoc
+// @Summary Get time-series data
+// @Description Count books or reviews created per day, week or month, with empty buckets filled with zero
+// @Tags dashboard
+// @Produce json
+// @Param metric query string true "Metric (books_created, reviews_created)"
+// @Param interval query string false "Bucket size (day, week, month)" default(day)
+// @Param from query string false "First day included (YYYY-MM-DD), defaults to 30 buckets before to"
+// @Param to query string false "Last day included (YYYY-MM-DD), defaults to today"
+// @Param tz query string false "IANA timezone used for bucketing" default(UTC)
+// @Param format query string false "Response format (json, csv, xlsx)" default(json)
+// @Success 200 {object} dto.TimeSeriesData "Time-series data fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid query parameters"
+// @Router /dashboard/timeseries [get]
+func (c *dashboardController) GetTimeSeries(ctx *fiber.Ctx) error {
+	query := dto.TimeSeriesQuery{
+		Metric:   ctx.Query("metric"),
+		Interval: ctx.Query("interval"),
+		From:     ctx.Query("from"),
+		To:       ctx.Query("to"),
+		Timezone: ctx.Query("tz"),
+	}
+
+	data, err := c.service.GetTimeSeries(query)
+	if err != nil {
+		return err
+	}
+
+	return sendDashboardData(ctx, data, "timeseries")
+}
+
+// RunQuery godoc
+// @Summary Run an analytics query
+// @Description Aggregate books by up to two whitelisted dimensions with the requested metrics and filters. Results are returned as rows and as chart-ready labels and series.
+// @Tags dashboard
+// @Accept json
+// @Produce json
+// @Param query body dto.AnalyticsQueryRequest true "Analytics query"
+// @Param format query string false "Response format (json, csv, xlsx)" default(json)
+// @Success 200 {object} dto.AnalyticsQueryResponse "Analytics query executed successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid query"
+// @Router /dashboard/query [post]
+func (c *dashboardController) RunQuery(ctx *fiber.Ctx) error {
+	var req dto.AnalyticsQueryRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	data, err := c.service.RunQuery(&req)
+	if err != nil {
+		return err
+	}
+
+	return sendDashboardData(ctx, data, "query")
+}
+
+// sendDashboardData writes dashboard data as JSON, or as a CSV/XLSX download when ?format= asks for it
+func sendDashboardData(ctx *fiber.Ctx, data dto.Tabular, name string) error {
+	format := ctx.Query("format", utils.ExportFormatJSON)
+
+	var body []byte
+	var contentType string
+	var err error
+	switch format {
+	case utils.ExportFormatJSON:
+		return ctx.Status(fiber.StatusOK).JSON(data)
+	case utils.ExportFormatCSV:
+		body, err = utils.WriteCSV(data.Table())
+		contentType = utils.ContentTypeCSV
+	case utils.ExportFormatXLSX:
+		body, err = utils.WriteXLSX(data.Table())
+		contentType = utils.ContentTypeXLSX
+	default:
+		return errors.NewBadRequestError(fmt.Sprintf("Invalid format value '%s'. Allowed values: json, csv, xlsx", format))
+	}
+	if err != nil {
+		return errors.NewInternalError(fmt.Errorf("failed to export dashboard data: %w", err))
+	}
+
+	ctx.Attachment(fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102"), format))
+	ctx.Set(fiber.HeaderContentType, contentType)
+	return ctx.Status(fiber.StatusOK).Send(body)
 }
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"honya/backend/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataQualityController interface {
+	GetReport(ctx *fiber.Ctx) error
+}
+
+type dataQualityController struct {
+	service service.DataQualityService
+}
+
+func NewDataQualityController(service service.DataQualityService) DataQualityController {
+	return &dataQualityController{service}
+}
+
+// GetReport godoc
+// @Summary Get the catalog data-quality report
+// @Description Scan the catalog for books missing covers, descriptions or ISBNs, invalid ISBNs, suspicious page counts, likely duplicates, covers pointing to missing storage objects and orphan reviews. Each check has a count and sample IDs.
+// @Tags dashboard
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "Response format: json, csv or xlsx" default(json)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.DataQualityReport "Data-quality report generated successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid format"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /dashboard/data-quality [get]
+func (c *dataQualityController) GetReport(ctx *fiber.Ctx) error {
+	report, err := c.service.GetReport()
+	if err != nil {
+		return err
+	}
+
+	return sendDashboardData(ctx, report, "data_quality")
+}
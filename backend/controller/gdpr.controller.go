@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"fmt"
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type GdprController interface {
+	ExportByEmail(ctx *fiber.Ctx) error
+	EraseByEmail(ctx *fiber.Ctx) error
+	GetRequests(ctx *fiber.Ctx) error
+}
+
+type gdprController struct {
+	service service.GdprService
+}
+
+func NewGdprController(service service.GdprService) GdprController {
+	return &gdprController{service}
+}
+
+// ExportByEmail godoc
+// @Summary Export a data subject's personal data
+// @Description Download every review and personal datum tied to an email as a JSON archive
+// @Tags gdpr
+// @Produce json
+// @Param email query string true "Data subject email"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.DataSubjectExportResponse "Personal data exported successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid email"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /admin/gdpr/export [get]
+func (c *gdprController) ExportByEmail(ctx *fiber.Ctx) error {
+	archive, err := c.service.ExportByEmail(ctx.Query("email"), utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	ctx.Attachment(fmt.Sprintf("data-export-%d.json", time.Now().Unix()))
+	return ctx.Status(fiber.StatusOK).JSON(archive)
+}
+
+// EraseByEmail godoc
+// @Summary Erase a data subject's personal data
+// @Description Anonymize every review written with an email, optionally removing the review text
+// @Tags gdpr
+// @Accept json
+// @Produce json
+// @Param request body dto.DataSubjectErasureRequest true "Erasure payload"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.DataSubjectErasureResponse "Personal data erased successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid email"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /admin/gdpr/erase [post]
+func (c *gdprController) EraseByEmail(ctx *fiber.Ctx) error {
+	var req dto.DataSubjectErasureRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	result, err := c.service.EraseByEmail(&req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityDataSubjectRequest, result.RequestID, utils.AuditActionErase,
+		fmt.Sprintf("Erased reviewer data from %d reviews (keep_content=%t)", result.ReviewsAffected, result.KeepContent))
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
+
+// GetRequests godoc
+// @Summary List data subject requests
+// @Description Get the paginated audit trail of export and erasure requests
+// @Tags gdpr
+// @Produce json
+// @Param query query string false "Filter by request type (export, erase) or email"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.DataSubjectRequestListResponse "Requests fetched successfully"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /admin/gdpr/requests [get]
+func (c *gdprController) GetRequests(ctx *fiber.Ctx) error {
+	params := dto.QueryParams{
+		Query:  ctx.Query("query"),
+		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+	}
+
+	requests, meta, err := c.service.GetRequests(params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.DataSubjectRequestListResponse{
+		Meta: *meta,
+		Data: requests,
+	})
+}
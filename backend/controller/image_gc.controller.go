@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"fmt"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImageGCController interface {
+	Collect(ctx *fiber.Ctx) error
+}
+
+type imageGCController struct {
+	service service.ImageGCService
+}
+
+func NewImageGCController(service service.ImageGCService) ImageGCController {
+	return &imageGCController{service}
+}
+
+// Collect godoc
+// @Summary Collect orphaned images
+// @Description Delete covers and staged uploads that no book or book revision refers to and that are older than the grace period, and retry queued deletions that failed before. With dry_run the orphans are only listed.
+// @Tags storage
+// @Produce json
+// @Param dry_run query boolean false "Only list the orphans" default(false)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.ImageGCReport "Garbage collection finished"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Failure 403 {object} errors.ErrorResponse "Admin role required"
+// @Router /admin/storage/gc [post]
+func (c *imageGCController) Collect(ctx *fiber.Ctx) error {
+	dryRun := ctx.QueryBool("dry_run", false)
+
+	report, err := c.service.Collect(time.Now(), dryRun)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		utils.SetAudit(ctx, utils.AuditEntityStorage, "", utils.AuditActionDelete, fmt.Sprintf("Deleted %d orphaned images", report.Deleted))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
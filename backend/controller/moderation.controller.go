@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ModerationController interface {
+	ReportReview(ctx *fiber.Ctx) error
+	GetQueue(ctx *fiber.Ctx) error
+	ApproveReview(ctx *fiber.Ctx) error
+	RemoveReview(ctx *fiber.Ctx) error
+}
+
+type moderationController struct {
+	service service.ModerationService
+}
+
+func NewModerationController(service service.ModerationService) ModerationController {
+	return &moderationController{service}
+}
+
+// ReportReview godoc
+// @Summary Report a review
+// @Description Flag a review as spam, offensive, spoilers or off-topic. Each reporter can report a review once; reviews are hidden once the report threshold is reached.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "Review ID"
+// @Param report body dto.ReviewReportRequest true "Report payload"
+// @Success 201 {object} dto.ReviewReportResponse "Review reported successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data"
+// @Failure 404 {object} errors.ErrorResponse "Review not found"
+// @Failure 409 {object} errors.ErrorResponse "Review already reported"
+// @Router /reviews/{id}/reports [post]
+func (c *moderationController) ReportReview(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.ReviewReportRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	result, err := c.service.ReportReview(id, &req, ctx.IP())
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityReview, id.String(), utils.AuditActionReport, "Reported as "+result.Reason)
+
+	return ctx.Status(fiber.StatusCreated).JSON(result)
+}
+
+// GetQueue godoc
+// @Summary Get the moderation queue
+// @Description Get hidden and reported reviews with their report counts and reasons, most reported first
+// @Tags moderation
+// @Produce json
+// @Param query query string false "Search query"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.ModerationQueueResponse "Moderation queue fetched successfully"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /moderation/reviews [get]
+func (c *moderationController) GetQueue(ctx *fiber.Ctx) error {
+	params := dto.QueryParams{
+		Query:  ctx.Query("query"),
+		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+	}
+
+	items, meta, err := c.service.GetQueue(params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ModerationQueueResponse{
+		Meta: *meta,
+		Data: items,
+	})
+}
+
+// ApproveReview godoc
+// @Summary Approve a review
+// @Description Republish a hidden review and dismiss its reports
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Review ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.ReviewResponse "Review approved successfully"
+// @Failure 404 {object} errors.ErrorResponse "Review not found"
+// @Router /moderation/reviews/{id}/approve [post]
+func (c *moderationController) ApproveReview(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	review, err := c.service.ApproveReview(id)
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityReview, id.String(), utils.AuditActionApprove, "Approved review and resolved its reports")
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewResponse(review, true))
+}
+
+// RemoveReview godoc
+// @Summary Remove a review
+// @Description Move a reported review to the trash, from where it can be restored until it is purged
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Review ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]string "Review removed successfully"
+// @Failure 404 {object} errors.ErrorResponse "Review not found"
+// @Router /moderation/reviews/{id} [delete]
+func (c *moderationController) RemoveReview(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := c.service.RemoveReview(id); err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityReview, id.String(), utils.AuditActionRemove, "Removed review")
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Review removed successfully",
+	})
+}
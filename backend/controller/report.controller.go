@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportController interface {
+	GetReports(ctx *fiber.Ctx) error
+	GetReportByID(ctx *fiber.Ctx) error
+	CreateReport(ctx *fiber.Ctx) error
+	UpdateReport(ctx *fiber.Ctx) error
+	DeleteReport(ctx *fiber.Ctx) error
+	SendNow(ctx *fiber.Ctx) error
+	GetRuns(ctx *fiber.Ctx) error
+}
+
+type reportController struct {
+	service service.ReportService
+}
+
+func NewReportController(service service.ReportService) ReportController {
+	return &reportController{service}
+}
+
+// GetReports godoc
+// @Summary List scheduled reports
+// @Description Get a paginated list of scheduled email reports
+// @Tags reports
+// @Produce json
+// @Param query query string false "Search by name"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.ScheduledReportListResponse "Reports fetched successfully"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /admin/reports [get]
+func (c *reportController) GetReports(ctx *fiber.Ctx) error {
+	params := dto.QueryParams{
+		Query:  ctx.Query("query"),
+		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+	}
+
+	reports, meta, err := c.service.GetReports(params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ScheduledReportListResponse{
+		Meta: *meta,
+		Data: reports,
+	})
+}
+
+// GetReportByID godoc
+// @Summary Get a scheduled report
+// @Tags reports
+// @Produce json
+// @Param id path string true "Report ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} model.ScheduledReport "Report fetched successfully"
+// @Failure 404 {object} errors.ErrorResponse "Report not found"
+// @Router /admin/reports/{id} [get]
+func (c *reportController) GetReportByID(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	report, err := c.service.GetReportByID(id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
+
+// CreateReport godoc
+// @Summary Create a scheduled report
+// @Description Email a summary of the selected dashboard charts to the recipients on a cron schedule
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param report body dto.ScheduledReportCreateRequest true "Report payload"
+// @Security ApiKeyAuth
+// @Success 201 {object} model.ScheduledReport "Report created successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data"
+// @Router /admin/reports [post]
+func (c *reportController) CreateReport(ctx *fiber.Ctx) error {
+	var req dto.ScheduledReportCreateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	report, err := c.service.CreateReport(&req)
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityScheduledReport, report.ID.String(), utils.AuditActionCreate, "Created report")
+
+	return ctx.Status(fiber.StatusCreated).JSON(report)
+}
+
+// UpdateReport godoc
+// @Summary Update a scheduled report
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param report body dto.ScheduledReportUpdateRequest true "Report payload"
+// @Security ApiKeyAuth
+// @Success 200 {object} model.ScheduledReport "Report updated successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data"
+// @Failure 404 {object} errors.ErrorResponse "Report not found"
+// @Router /admin/reports/{id} [patch]
+func (c *reportController) UpdateReport(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.ScheduledReportUpdateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	report, err := c.service.UpdateReport(id, &req)
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityScheduledReport, id.String(), utils.AuditActionUpdate, utils.AuditFields(&req))
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
+
+// DeleteReport godoc
+// @Summary Delete a scheduled report
+// @Tags reports
+// @Produce json
+// @Param id path string true "Report ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]string "Report deleted successfully"
+// @Failure 404 {object} errors.ErrorResponse "Report not found"
+// @Router /admin/reports/{id} [delete]
+func (c *reportController) DeleteReport(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := c.service.DeleteReport(id); err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityScheduledReport, id.String(), utils.AuditActionDelete, "Deleted report")
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Report deleted successfully",
+	})
+}
+
+// SendNow godoc
+// @Summary Send a report now
+// @Description Deliver a report immediately. Delivery failures are returned as a run with status "failed".
+// @Tags reports
+// @Produce json
+// @Param id path string true "Report ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} model.ReportRun "Report run recorded"
+// @Failure 404 {object} errors.ErrorResponse "Report not found"
+// @Router /admin/reports/{id}/send [post]
+func (c *reportController) SendNow(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	run, err := c.service.SendNow(id)
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityScheduledReport, id.String(), utils.AuditActionSend, "Sent report, run "+run.Status)
+
+	return ctx.Status(fiber.StatusOK).JSON(run)
+}
+
+// GetRuns godoc
+// @Summary List report runs
+// @Description Get the delivery history of a report, most recent first
+// @Tags reports
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.ReportRunListResponse "Runs fetched successfully"
+// @Failure 404 {object} errors.ErrorResponse "Report not found"
+// @Router /admin/reports/{id}/runs [get]
+func (c *reportController) GetRuns(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	params := dto.QueryParams{
+		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+	}
+
+	runs, meta, err := c.service.GetRuns(id, params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ReportRunListResponse{
+		Meta: *meta,
+		Data: runs,
+	})
+}
@@ -16,14 +16,16 @@ type ReviewController interface {
 	CreateReview(ctx *fiber.Ctx) error
 	UpdateReview(ctx *fiber.Ctx) error
 	DeleteReview(ctx *fiber.Ctx) error
+	GetChallenge(ctx *fiber.Ctx) error
 }
 
 type reviewController struct {
-	service service.ReviewService
+	service    service.ReviewService
+	challenges service.ChallengeService
 }
 
-func NewReviewController(service service.ReviewService) ReviewController {
-	return &reviewController{service}
+func NewReviewController(service service.ReviewService, challenges service.ChallengeService) ReviewController {
+	return &reviewController{service, challenges}
 }
 
 // GetAllReviews godoc
@@ -35,13 +37,15 @@ func NewReviewController(service service.ReviewService) ReviewController {
 // @Param query query string false "Search query"
 // @Param offset query integer false "Offset for pagination" default(0)
 // @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
 // @Success 200 {object} dto.ReviewListResponse "Reviews fetched successfully"
 // @Router /reviews [get]
 func (c *reviewController) GetAllReviews(ctx *fiber.Ctx) error {
 	params := dto.QueryParams{
-		Query:  ctx.Query("query"),
-		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
-		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+		Query:         ctx.Query("query"),
+		Offset:        utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:         utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+		IncludeHidden: utils.IsPrivileged(ctx),
 	}
 
 	reviews, meta, err := c.service.GetAllReviews(params)
@@ -49,7 +53,7 @@ func (c *reviewController) GetAllReviews(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewListResponse(reviews, *meta))
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewListResponse(reviews, *meta, utils.IsPrivileged(ctx)))
 }
 
 // GetReviewByID godoc
@@ -59,6 +63,7 @@ func (c *reviewController) GetAllReviews(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Review ID"
+// @Security ApiKeyAuth
 // @Success 200 {object} dto.ReviewResponse "Review fetched successfully"
 // @Failure 400 {object} errors.ErrorResponse "Invalid ID format"
 // @Failure 404 {object} errors.ErrorResponse "Review not found"
@@ -74,7 +79,12 @@ func (c *reviewController) GetReviewByID(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewResponse(review))
+	// Hidden and pending reviews are only visible to moderators
+	if (review.Status == utils.ReviewStatusHidden || review.Status == utils.ReviewStatusPending) && !utils.IsPrivileged(ctx) {
+		return errors.NewNotFoundError("Review not found")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewResponse(review, utils.IsPrivileged(ctx)))
 }
 
 // GetReviewsByBookID godoc
@@ -87,6 +97,7 @@ func (c *reviewController) GetReviewByID(ctx *fiber.Ctx) error {
 // @Param query query string false "Search query"
 // @Param offset query integer false "Offset for pagination" default(0)
 // @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
 // @Success 200 {object} dto.ReviewListResponse "Reviews fetched successfully"
 // @Failure 400 {object} errors.ErrorResponse "Invalid ID format"
 // @Failure 404 {object} errors.ErrorResponse "Book not found"
@@ -98,9 +109,10 @@ func (c *reviewController) GetReviewsByBookID(ctx *fiber.Ctx) error {
 	}
 
 	params := dto.QueryParams{
-		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
-		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
-		Query:  ctx.Query("query"),
+		Offset:        utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:         utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+		Query:         ctx.Query("query"),
+		IncludeHidden: utils.IsPrivileged(ctx),
 	}
 
 	reviews, meta, err := c.service.GetReviewsByBookID(bookID, params)
@@ -108,12 +120,12 @@ func (c *reviewController) GetReviewsByBookID(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewListResponse(reviews, *meta))
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewListResponse(reviews, *meta, utils.IsPrivileged(ctx)))
 }
 
 // CreateReview godoc
 // @Summary Create a new review
-// @Description Create a new review for a book
+// @Description Create a new review for a book. Requires a solved proof-of-work challenge from GET /reviews/challenge.
 // @Tags reviews
 // @Accept json
 // @Produce json
@@ -121,6 +133,8 @@ func (c *reviewController) GetReviewsByBookID(ctx *fiber.Ctx) error {
 // @Success 201 {object} dto.ReviewResponse "Review created successfully"
 // @Failure 400 {object} errors.ErrorResponse "Invalid input data"
 // @Failure 404 {object} errors.ErrorResponse "Book not found"
+// @Failure 409 {object} errors.ErrorResponse "This email has already reviewed the book"
+// @Failure 429 {object} errors.ErrorResponse "Too many reviews submitted"
 // @Router /reviews [post]
 func (c *reviewController) CreateReview(ctx *fiber.Ctx) error {
 	var req dto.ReviewCreateRequest
@@ -128,12 +142,14 @@ func (c *reviewController) CreateReview(ctx *fiber.Ctx) error {
 		return errors.NewBadRequestError("Invalid JSON body")
 	}
 
-	review, err := c.service.CreateReview(&req)
+	review, err := c.service.CreateReview(&req, ctx.IP())
 	if err != nil {
 		return err
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(dto.ToReviewResponse(review))
+	utils.SetAudit(ctx, utils.AuditEntityReview, review.ID.String(), utils.AuditActionCreate, "Reviewed book "+review.BookID.String())
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ToReviewResponse(review, utils.IsPrivileged(ctx)))
 }
 
 // UpdateReview godoc
@@ -164,7 +180,9 @@ func (c *reviewController) UpdateReview(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewResponse(updated))
+	utils.SetAudit(ctx, utils.AuditEntityReview, id.String(), utils.AuditActionUpdate, utils.AuditFields(&req))
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToReviewResponse(updated, utils.IsPrivileged(ctx)))
 }
 
 // DeleteReview godoc
@@ -188,7 +206,25 @@ func (c *reviewController) DeleteReview(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	utils.SetAudit(ctx, utils.AuditEntityReview, id.String(), utils.AuditActionDelete, "Moved review to trash")
+
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Review deleted successfully",
 	})
 }
+
+// GetChallenge godoc
+// @Summary Get a proof-of-work challenge
+// @Description Issue a signed challenge that must be solved before creating a review. Difficulty rises automatically when submissions spike.
+// @Tags reviews
+// @Produce json
+// @Success 200 {object} dto.ChallengeResponse "Challenge issued successfully"
+// @Router /reviews/challenge [get]
+func (c *reviewController) GetChallenge(ctx *fiber.Ctx) error {
+	challenge, err := c.challenges.IssueChallenge()
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(challenge)
+}
@@ -24,6 +24,8 @@ func SeedBooksAPI(ctx *fiber.Ctx) error {
 		})
 	}
 
+	utils.SetAudit(ctx, utils.AuditEntityCatalog, "", utils.AuditActionSeed, "Seeded sample books and reviews")
+
 	return ctx.JSON(fiber.Map{
 		"message": "Books seeded successfully or already exist",
 	})
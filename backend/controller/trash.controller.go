@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrashController interface {
+	GetTrash(ctx *fiber.Ctx) error
+}
+
+type trashController struct {
+	service service.TrashService
+}
+
+func NewTrashController(service service.TrashService) TrashController {
+	return &trashController{service}
+}
+
+// GetTrash godoc
+// @Summary List the trash
+// @Description List soft-deleted books or reviews, most recently deleted first, with when each will be purged for good. Purging a book also deletes its images from storage once nothing else refers to them. Reviews deleted along with their book are listed under the book.
+// @Tags trash
+// @Produce json
+// @Param type query string false "book or review" default(book)
+// @Param offset query integer false "Offset for pagination" default(0)
+// @Param limit query integer false "Limit for pagination" default(10)
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.TrashListResponse "Trash fetched successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} errors.ErrorResponse "Authentication required"
+// @Router /trash [get]
+func (c *trashController) GetTrash(ctx *fiber.Ctx) error {
+	params := dto.TrashQueryParams{
+		Type:   ctx.Query("type"),
+		Offset: utils.ParseInt(ctx.Query("offset"), utils.DefaultOffset),
+		Limit:  utils.ParseInt(ctx.Query("limit"), utils.DefaultLimit),
+	}
+
+	items, meta, err := c.service.GetTrash(params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.TrashListResponse{
+		Meta: *meta,
+		Data: items,
+	})
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UploadController interface {
+	CreateUpload(ctx *fiber.Ctx) error
+	FinalizeUpload(ctx *fiber.Ctx) error
+	ReceiveUpload(ctx *fiber.Ctx) error
+}
+
+type uploadController struct {
+	service service.UploadService
+}
+
+func NewUploadController(service service.UploadService) UploadController {
+	return &uploadController{service}
+}
+
+// CreateUpload godoc
+// @Summary Start a direct cover upload
+// @Description Get a presigned URL to PUT a cover to. The URL only accepts the given content type and exact size, and expires after 15 minutes. Send the returned headers with the upload, then finalize it.
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param upload body dto.UploadCreateRequest true "Content type and size of the file"
+// @Success 201 {object} dto.UploadResponse "Upload URL created successfully"
+// @Failure 400 {object} errors.ErrorResponse "Unsupported content type or size"
+// @Router /uploads [post]
+func (c *uploadController) CreateUpload(ctx *fiber.Ctx) error {
+	var req dto.UploadCreateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	upload, err := c.service.CreateUpload(&req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(upload)
+}
+
+// FinalizeUpload godoc
+// @Summary Attach an uploaded cover to a book
+// @Description Check the uploaded file, generate the cover variants and set it as the book's cover. The change is recorded as a book revision.
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Param finalize body dto.UploadFinalizeRequest true "Book to attach the cover to"
+// @Success 200 {object} dto.BookResponse "Cover attached successfully"
+// @Failure 400 {object} errors.ErrorResponse "Invalid input data or image"
+// @Failure 404 {object} errors.ErrorResponse "Upload or book not found"
+// @Router /uploads/{id}/finalize [post]
+func (c *uploadController) FinalizeUpload(ctx *fiber.Ctx) error {
+	id, err := utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.UploadFinalizeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	book, err := c.service.FinalizeUpload(id, &req, utils.GetRole(ctx))
+	if err != nil {
+		return err
+	}
+
+	utils.SetAudit(ctx, utils.AuditEntityBook, book.ID.String(), utils.AuditActionUpdate, "Changed image")
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ToBookResponse(book))
+}
+
+// ReceiveUpload receives the PUT of a presigned URL when the blob store is served by the API
+// itself. It is mounted next to the stored files rather than under /api, so it is not
+// documented as an API endpoint.
+func (c *uploadController) ReceiveUpload(ctx *fiber.Ctx) error {
+	expires, err := strconv.ParseInt(ctx.Query("expires"), 10, 64)
+	if err != nil {
+		return errors.NewForbiddenError("Invalid or expired upload URL")
+	}
+
+	if err := c.service.ReceiveUpload(ctx.Params("*"), ctx.Get(fiber.HeaderContentType), expires, ctx.Query("signature"), ctx.Body()); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+)
+
+type ReviewAlertQueryParams struct {
+	Offset int       `query:"offset"`
+	Limit  int       `query:"limit"`
+	BookID uuid.UUID `query:"book_id"`
+	Type   string    `query:"type"`
+}
+
+type ReviewAlertListResponse struct {
+	Meta PaginationMeta      `json:"meta"`
+	Data []model.ReviewAlert `json:"data"`
+}
+
+// Reviews a book received in one hour; Hour counts from the start of the queried range
+type BookHourlyCount struct {
+	BookID uuid.UUID
+	Hour   int64
+	Count  int64
+}
+
+// Reviews a book received from one email domain or IP range
+type ReviewSourceCount struct {
+	BookID uuid.UUID
+	Source string
+	Count  int64
+}
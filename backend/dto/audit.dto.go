@@ -0,0 +1,37 @@
+package dto
+
+import "honya/backend/model"
+
+// AuditEvent is what a handler reports about the change it made; the audit middleware adds
+// who made it and how before writing it to the log
+type AuditEvent struct {
+	EntityType string
+	EntityID   string
+	Action     string
+	Summary    string
+}
+
+type AuditQueryParams struct {
+	Actor      string `query:"actor"`
+	EntityType string `query:"entity_type"`
+	EntityID   string `query:"entity_id"`
+	Action     string `query:"action"`
+	// Unix seconds, inclusive
+	From   int64 `query:"from"`
+	To     int64 `query:"to"`
+	Offset int   `query:"offset"`
+	Limit  int   `query:"limit"`
+}
+
+type AuditLogListResponse struct {
+	Meta PaginationMeta   `json:"meta"`
+	Data []model.AuditLog `json:"data"`
+}
+
+// AuditVerifyResponse is the result of walking the hash chain from the first entry
+type AuditVerifyResponse struct {
+	Valid   bool  `json:"valid"`
+	Checked int64 `json:"checked"`
+	// First entry whose hash or link does not match, if any
+	BrokenAt *uint64 `json:"broken_at,omitempty"`
+}
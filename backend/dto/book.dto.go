@@ -15,6 +15,8 @@ type BookQueryParams struct {
 	Rating          float64 `query:"rating"`
 	Pages           int     `query:"pages"`
 	Sort            string  `query:"sort"`
+	// Only books with this status; archived books are left out when empty
+	Status string `query:"status"`
 }
 
 type BookCreateRequest struct {
@@ -27,6 +29,10 @@ type BookCreateRequest struct {
 	Pages           int     `json:"pages"`
 	Isbn            string  `json:"isbn"`
 	AuthorName      string  `json:"author_name" validate:"required"`
+	// draft, scheduled or published (default)
+	Status string `json:"status,omitempty"`
+	// Unix time a scheduled book goes live
+	PublishAt int64 `json:"publish_at,omitempty"`
 }
 
 type BookUpdateRequest struct {
@@ -39,6 +45,31 @@ type BookUpdateRequest struct {
 	Pages           *int     `json:"pages,omitempty"`
 	AuthorName      *string  `json:"author_name,omitempty"`
 	Isbn            *string  `json:"isbn,omitempty"`
+	Status          *string  `json:"status,omitempty"`
+	PublishAt       *int64   `json:"publish_at,omitempty"`
+	// Generated from an uploaded cover, never by clients; restoring a revision brings them back
+	Images *model.BookImages `json:"images,omitempty"`
+	// Why the change was made, kept with the revision it creates
+	Reason string `json:"reason,omitempty"`
+}
+
+// BookMergeRequest merges the source book into the book in the URL
+type BookMergeRequest struct {
+	SourceID uuid.UUID `json:"source_id"`
+	// keep_target (default), fill_empty or prefer_source
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// BookDuplicate is a pair of books that look like the same title, the older one first
+type BookDuplicate struct {
+	Book      BookResponse `json:"book"`
+	Duplicate BookResponse `json:"duplicate"`
+	Score     float64      `json:"score"`
+	Reasons   []string     `json:"reasons"`
+}
+
+type BookDuplicateListResponse struct {
+	Data []BookDuplicate `json:"data"`
 }
 
 type BookResponse struct {
@@ -52,8 +83,12 @@ type BookResponse struct {
 	Pages           int       `json:"pages"`
 	Isbn            string    `json:"isbn"`
 	AuthorName      string    `json:"author_name"`
+	Status          string    `json:"status"`
+	PublishAt       int64     `json:"publish_at,omitempty"`
 	CreatedAt       int64     `json:"created_at"`
 	UpdatedAt       int64     `json:"updated_at"`
+	// Variant URLs, size and placeholder of an uploaded cover; absent for external images
+	Images *model.BookImages `json:"images,omitempty"`
 }
 
 type BookListResponse struct {
@@ -73,8 +108,11 @@ func ToBookResponse(book *model.Book) *BookResponse {
 		Pages:           book.Pages,
 		Isbn:            book.Isbn,
 		AuthorName:      book.AuthorName,
+		Status:          book.Status,
+		PublishAt:       book.PublishAt,
 		CreatedAt:       book.CreatedAt,
 		UpdatedAt:       book.UpdatedAt,
+		Images:          book.Images,
 	}
 }
 
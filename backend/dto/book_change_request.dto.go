@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+)
+
+// BookChangeRequestCreateRequest proposes corrections to a book's metadata. Only content
+// fields can be proposed: ISBN, cover, status and publish time are left to editors.
+type BookChangeRequestCreateRequest struct {
+	Changes BookUpdateRequest `json:"changes"`
+	// Why the change is needed, e.g. a source for the corrected year
+	Comment string `json:"comment,omitempty"`
+	Name    string `json:"name,omitempty"`
+	// Optional, so the proposer can be reached; covered by GDPR export and erasure
+	Email string `json:"email,omitempty" validate:"omitempty,email"`
+	// Proof-of-work obtained from GET /reviews/challenge
+	PowChallenge string `json:"pow_challenge,omitempty"`
+	PowSolution  string `json:"pow_solution,omitempty"`
+}
+
+type BookChangeRequestQueryParams struct {
+	// pending (default), accepted or rejected
+	Status string     `query:"status"`
+	BookID *uuid.UUID `query:"book_id"`
+	Offset int        `query:"offset"`
+	Limit  int        `query:"limit"`
+}
+
+// BookChangeRequestReviewRequest accepts or rejects a change request; rejecting needs a note
+type BookChangeRequestReviewRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// Response payload for a change request.
+// Name and email are only populated for admin/moderator callers.
+type BookChangeRequestResponse struct {
+	ID           uuid.UUID     `json:"id"`
+	BookID       uuid.UUID     `json:"book_id"`
+	Status       string        `json:"status"`
+	Changes      []FieldChange `json:"changes"`
+	Comment      string        `json:"comment"`
+	Name         string        `json:"name,omitempty"`
+	Email        string        `json:"email,omitempty"`
+	ReviewerNote string        `json:"reviewer_note,omitempty"`
+	ReviewedBy   string        `json:"reviewed_by,omitempty"`
+	ReviewedAt   int64         `json:"reviewed_at,omitempty"`
+	CreatedAt    int64         `json:"created_at"`
+}
+
+type BookChangeRequestListResponse struct {
+	Meta PaginationMeta              `json:"meta"`
+	Data []BookChangeRequestResponse `json:"data"`
+}
+
+func ToBookChangeRequestResponse(request *model.BookChangeRequest, includePersonal bool) BookChangeRequestResponse {
+	response := BookChangeRequestResponse{
+		ID:           request.ID,
+		BookID:       request.BookID,
+		Status:       request.Status,
+		Changes:      toFieldChanges(request.Before, request.After),
+		Comment:      request.Comment,
+		ReviewerNote: request.ReviewerNote,
+		ReviewedBy:   request.ReviewedBy,
+		ReviewedAt:   request.ReviewedAt,
+		CreatedAt:    request.CreatedAt,
+	}
+	if includePersonal {
+		response.Name = request.Name
+		response.Email = request.Email
+	}
+	return response
+}
+
+func ToBookChangeRequestListResponse(requests []model.BookChangeRequest, meta PaginationMeta, includePersonal bool) BookChangeRequestListResponse {
+	data := make([]BookChangeRequestResponse, 0, len(requests))
+	for _, request := range requests {
+		data = append(data, ToBookChangeRequestResponse(&request, includePersonal))
+	}
+
+	return BookChangeRequestListResponse{
+		Meta: meta,
+		Data: data,
+	}
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+)
+
+// BookImageCreateRequest holds the form fields sent along with a gallery image
+type BookImageCreateRequest struct {
+	// front, back, spine or sample
+	Role  string
+	AltEn string
+	AltJa string
+	// Where to insert the image; appended to the gallery when omitted
+	Position *int
+}
+
+type BookImageUpdateRequest struct {
+	Role  *string `json:"role,omitempty"`
+	AltEn *string `json:"alt_en,omitempty"`
+	AltJa *string `json:"alt_ja,omitempty"`
+}
+
+// BookImageOrderRequest lists every image of a book's gallery in its new order
+type BookImageOrderRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type BookImageResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	BookID    uuid.UUID         `json:"book_id"`
+	Role      string            `json:"role"`
+	Position  int               `json:"position"`
+	AltEn     string            `json:"alt_en"`
+	AltJa     string            `json:"alt_ja"`
+	URL       string            `json:"url"`
+	Metadata  *model.BookImages `json:"metadata"`
+	CreatedAt int64             `json:"created_at"`
+	UpdatedAt int64             `json:"updated_at"`
+}
+
+type BookImageListResponse struct {
+	Data []BookImageResponse `json:"data"`
+}
+
+func ToBookImageResponse(image *model.BookImage) *BookImageResponse {
+	return &BookImageResponse{
+		ID:        image.ID,
+		BookID:    image.BookID,
+		Role:      image.Role,
+		Position:  image.Position,
+		AltEn:     image.AltEn,
+		AltJa:     image.AltJa,
+		URL:       image.URL,
+		Metadata:  image.Metadata,
+		CreatedAt: image.CreatedAt,
+		UpdatedAt: image.UpdatedAt,
+	}
+}
+
+func ToBookImageListResponse(images []model.BookImage) BookImageListResponse {
+	responses := make([]BookImageResponse, 0, len(images))
+	for _, image := range images {
+		responses = append(responses, *ToBookImageResponse(&image))
+	}
+	return BookImageListResponse{Data: responses}
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"honya/backend/model"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// FieldChange is one column a revision or change request changes
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type BookRevisionResponse struct {
+	ID        uuid.UUID     `json:"id"`
+	Number    int           `json:"number"`
+	Actor     string        `json:"actor"`
+	Reason    string        `json:"reason"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt int64         `json:"created_at"`
+}
+
+type BookRevisionListResponse struct {
+	Meta PaginationMeta         `json:"meta"`
+	Data []BookRevisionResponse `json:"data"`
+}
+
+type BookRevisionRestoreRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// toFieldChanges pairs up the before and after values of each changed field, sorted by field
+func toFieldChanges(before, after map[string]interface{}) []FieldChange {
+	changes := make([]FieldChange, 0, len(after))
+	for field, value := range after {
+		changes = append(changes, FieldChange{
+			Field:  field,
+			Before: before[field],
+			After:  value,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func ToBookRevisionResponse(revision *model.BookRevision) BookRevisionResponse {
+	return BookRevisionResponse{
+		ID:        revision.ID,
+		Number:    revision.Number,
+		Actor:     revision.Actor,
+		Reason:    revision.Reason,
+		Changes:   toFieldChanges(revision.Before, revision.After),
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+func ToBookRevisionListResponse(revisions []model.BookRevision, meta PaginationMeta) BookRevisionListResponse {
+	data := make([]BookRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		data = append(data, ToBookRevisionResponse(&revision))
+	}
+
+	return BookRevisionListResponse{
+		Meta: meta,
+		Data: data,
+	}
+}
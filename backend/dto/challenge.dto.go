@@ -0,0 +1,11 @@
+package dto
+
+// Proof-of-work challenge issued before submitting a review.
+// Clients must find a solution such that SHA-256("<challenge>:<solution>")
+// starts with at least `difficulty` zero bits.
+type ChallengeResponse struct {
+	Challenge  string `json:"challenge"`
+	Difficulty int    `json:"difficulty"`
+	Algorithm  string `json:"algorithm"`
+	ExpiresAt  int64  `json:"expires_at"`
+}
@@ -1,15 +1,177 @@
 package dto
 
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 type DonutChartData struct {
 	FilterBy string           `json:"filter_by"`
 	Data     map[string]int64 `json:"data"`
+	// Set when a numeric field is bucketed; buckets are ordered for histograms
+	Bucket  string            `json:"bucket,omitempty"`
+	Buckets []HistogramBucket `json:"buckets,omitempty"`
+	AggregateFreshness
+}
+
+// Set when a chart is served from precomputed aggregates: when they were last fully
+// rebuilt and when the next rebuild is due (both unix seconds)
+type AggregateFreshness struct {
+	RefreshedAt int64 `json:"refreshed_at,omitempty"`
+	StaleAfter  int64 `json:"stale_after,omitempty"`
+}
+
+type BooksDataQuery struct {
+	FilterBy string
+	Bucket   string
+	Width    float64
+	// Comma-separated, strictly increasing bucket edges
+	Edges string
+}
+
+// A [Min, Max) range of values; Min or Max is omitted for open-ended buckets
+type HistogramBucket struct {
+	Label string   `json:"label"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
 }
 
 type BarChartData struct {
 	Data []ReviewerStats `json:"data"`
+	AggregateFreshness
 }
 
 type ReviewerStats struct {
 	Name  string `json:"name"`
 	Count int64  `json:"count"`
 }
+
+type TimeSeriesQuery struct {
+	Metric   string
+	Interval string
+	From     string
+	To       string
+	Timezone string
+}
+
+type TimeSeriesData struct {
+	Metric   string            `json:"metric"`
+	Interval string            `json:"interval"`
+	Timezone string            `json:"timezone"`
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Data     []TimeSeriesPoint `json:"data"`
+}
+
+type TimeSeriesPoint struct {
+	Bucket string `json:"bucket"`
+	Count  int64  `json:"count"`
+}
+
+// Request payload for the generic analytics query; every name is checked against a whitelist
+type AnalyticsQueryRequest struct {
+	Dimensions []string          `json:"dimensions"`
+	Metrics    []string          `json:"metrics"`
+	Filters    []AnalyticsFilter `json:"filters"`
+}
+
+type AnalyticsFilter struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	// A string or number, or a list of strings for the "in" operator
+	Value interface{} `json:"value" swaggertype:"string"`
+}
+
+type AnalyticsQueryResponse struct {
+	Dimensions []string `json:"dimensions"`
+	Metrics    []string `json:"metrics"`
+	// One entry per group, keyed by dimension and metric names
+	Rows []map[string]interface{} `json:"rows"`
+	// Chart-ready view: values of the first dimension as labels,
+	// one series per metric (and per value of the second dimension, if any)
+	Labels []string          `json:"labels"`
+	Series []AnalyticsSeries `json:"series"`
+}
+
+type AnalyticsSeries struct {
+	Name   string    `json:"name"`
+	Metric string    `json:"metric"`
+	Data   []float64 `json:"data"`
+}
+
+// Table is a flat, export-friendly view of a dashboard response
+type Table struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+}
+
+// Tabular is implemented by dashboard responses that can be exported as CSV or XLSX
+type Tabular interface {
+	Table() Table
+}
+
+func (d *DonutChartData) Table() Table {
+	table := Table{
+		Title:   "Books by " + d.FilterBy,
+		Headers: []string{d.FilterBy, "count"},
+	}
+
+	if len(d.Buckets) > 0 {
+		for _, bucket := range d.Buckets {
+			table.Rows = append(table.Rows, []string{bucket.Label, strconv.FormatInt(bucket.Count, 10)})
+		}
+		return table
+	}
+
+	keys := make([]string, 0, len(d.Data))
+	for key := range d.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		table.Rows = append(table.Rows, []string{key, strconv.FormatInt(d.Data[key], 10)})
+	}
+
+	return table
+}
+
+func (d *BarChartData) Table() Table {
+	table := Table{
+		Title:   "Top reviewers",
+		Headers: []string{"name", "count"},
+	}
+	for _, reviewer := range d.Data {
+		table.Rows = append(table.Rows, []string{reviewer.Name, strconv.FormatInt(reviewer.Count, 10)})
+	}
+	return table
+}
+
+func (d *TimeSeriesData) Table() Table {
+	table := Table{
+		Title:   strings.ReplaceAll(d.Metric, "_", " ") + " per " + d.Interval,
+		Headers: []string{d.Interval, "count"},
+	}
+	for _, point := range d.Data {
+		table.Rows = append(table.Rows, []string{point.Bucket, strconv.FormatInt(point.Count, 10)})
+	}
+	return table
+}
+
+func (d *AnalyticsQueryResponse) Table() Table {
+	table := Table{
+		Title:   "Analytics query",
+		Headers: append(append([]string{}, d.Dimensions...), d.Metrics...),
+	}
+	for _, row := range d.Rows {
+		cells := make([]string, 0, len(table.Headers))
+		for _, header := range table.Headers {
+			cells = append(cells, fmt.Sprint(row[header]))
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type DataQualityReport struct {
+	GeneratedAt int64              `json:"generated_at"`
+	TotalBooks  int64              `json:"total_books"`
+	Checks      []DataQualityCheck `json:"checks"`
+}
+
+// One catalog check with how many records fail it and a sample of their IDs.
+// Duplicate books are also sampled as groups that belong together.
+type DataQualityCheck struct {
+	Check        string        `json:"check"`
+	Description  string        `json:"description"`
+	Count        int64         `json:"count"`
+	SampleIDs    []uuid.UUID   `json:"sample_ids"`
+	SampleGroups [][]uuid.UUID `json:"sample_groups,omitempty"`
+	// Set when the check could not run, e.g. because storage was unreachable
+	Error string `json:"error,omitempty"`
+}
+
+func (d *DataQualityReport) Table() Table {
+	table := Table{
+		Title:   "Data quality",
+		Headers: []string{"check", "description", "count", "sample_ids"},
+	}
+	for _, check := range d.Checks {
+		ids := make([]string, 0, len(check.SampleIDs))
+		for _, id := range check.SampleIDs {
+			ids = append(ids, id.String())
+		}
+		table.Rows = append(table.Rows, []string{check.Check, check.Description, strconv.FormatInt(check.Count, 10), strings.Join(ids, " ")})
+	}
+	return table
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "honya/backend/model"
+
+// Request payload for erasing a data subject's reviews
+type DataSubjectErasureRequest struct {
+	Email       string `json:"email" validate:"required,email"`
+	KeepContent *bool  `json:"keep_content,omitempty"`
+}
+
+// JSON archive of every personal datum tied to an email
+type DataSubjectExportResponse struct {
+	Email       string           `json:"email"`
+	GeneratedAt int64            `json:"generated_at"`
+	ReviewCount int              `json:"review_count"`
+	Reviews     []ReviewResponse `json:"reviews"`
+	// Suggested edits made with the email
+	ChangeRequests []BookChangeRequestResponse `json:"change_requests"`
+}
+
+// Response payload for an erasure request
+type DataSubjectErasureResponse struct {
+	RequestID              string `json:"request_id"`
+	ReviewsAffected        int64  `json:"reviews_affected"`
+	ChangeRequestsAffected int64  `json:"change_requests_affected"`
+	KeepContent            bool   `json:"keep_content"`
+}
+
+type DataSubjectRequestListResponse struct {
+	Meta PaginationMeta             `json:"meta"`
+	Data []model.DataSubjectRequest `json:"data"`
+}
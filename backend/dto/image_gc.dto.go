@@ -0,0 +1,26 @@
+package dto
+
+// ImageGCReport is the outcome of one image garbage collection run. In a dry run orphans are
+// only listed; nothing is deleted and the retry queue is left alone.
+type ImageGCReport struct {
+	DryRun bool `json:"dry_run"`
+	// Blobs listed under the cover and upload prefixes
+	Scanned    int `json:"scanned"`
+	Referenced int `json:"referenced"`
+	// Unreferenced blobs still within the grace period
+	Recent  int             `json:"recent"`
+	Orphans []ImageGCOrphan `json:"orphans"`
+	Deleted int             `json:"deleted"`
+	// Deletions that failed and were queued for a retry
+	Failed int `json:"failed"`
+	// Queued deletions that went through on this run
+	Retried        int `json:"retried"`
+	PendingRetries int `json:"pending_retries"`
+}
+
+type ImageGCOrphan struct {
+	Key        string `json:"key"`
+	URL        string `json:"url"`
+	Size       int64  `json:"size"`
+	ModifiedAt int64  `json:"modified_at"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/google/uuid"
+
+// Request payload for reporting a review
+type ReviewReportRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=spam offensive spoilers off_topic"`
+	Details string `json:"details,omitempty"`
+}
+
+// Response payload after a review has been reported
+type ReviewReportResponse struct {
+	ReviewID    uuid.UUID `json:"review_id"`
+	Reason      string    `json:"reason"`
+	ReportCount int64     `json:"report_count"`
+	Hidden      bool      `json:"hidden"`
+}
+
+// A reported or hidden review awaiting moderation
+type ModerationQueueItem struct {
+	Review      ReviewResponse   `json:"review"`
+	ReportCount int64            `json:"report_count"`
+	Reasons     map[string]int64 `json:"reasons"`
+}
+
+type ModerationQueueResponse struct {
+	Meta PaginationMeta        `json:"meta"`
+	Data []ModerationQueueItem `json:"data"`
+}
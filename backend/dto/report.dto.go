@@ -0,0 +1,50 @@
+package dto
+
+import "honya/backend/model"
+
+// Request payload for creating a scheduled report
+type ScheduledReportCreateRequest struct {
+	Name       string   `json:"name" validate:"required"`
+	Cron       string   `json:"cron" validate:"required"`
+	Timezone   string   `json:"timezone,omitempty"`
+	Recipients []string `json:"recipients" validate:"required,min=1,dive,email"`
+	Charts     []string `json:"charts" validate:"required,min=1"`
+	Format     string   `json:"format,omitempty" validate:"omitempty,oneof=csv xlsx"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+// Request payload for updating a scheduled report
+type ScheduledReportUpdateRequest struct {
+	Name       *string   `json:"name,omitempty"`
+	Cron       *string   `json:"cron,omitempty"`
+	Timezone   *string   `json:"timezone,omitempty"`
+	Recipients *[]string `json:"recipients,omitempty"`
+	Charts     *[]string `json:"charts,omitempty"`
+	Format     *string   `json:"format,omitempty"`
+	Enabled    *bool     `json:"enabled,omitempty"`
+}
+
+type ScheduledReportListResponse struct {
+	Meta PaginationMeta          `json:"meta"`
+	Data []model.ScheduledReport `json:"data"`
+}
+
+type ReportRunListResponse struct {
+	Meta PaginationMeta    `json:"meta"`
+	Data []model.ReportRun `json:"data"`
+}
+
+// EmailMessage is what the mailer sends; HTML is the body and Text a plain-text fallback
+type EmailMessage struct {
+	To          []string
+	Subject     string
+	HTML        string
+	Text        string
+	Attachments []EmailAttachment
+}
+
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
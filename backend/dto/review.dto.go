@@ -1,17 +1,25 @@
 package dto
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"honya/backend/model"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
-// Request payload for creating a review
+// Request payload for creating a review.
+// Content accepts the Markdown subset described in utils.RenderMarkdown.
 type ReviewCreateRequest struct {
 	BookID  uuid.UUID `json:"book_id" validate:"required"`
 	Name    string    `json:"name" validate:"required"`
 	Email   string    `json:"email" validate:"required,email"`
 	Content string    `json:"content" validate:"required"`
+
+	// Proof-of-work obtained from GET /reviews/challenge
+	PowChallenge string `json:"pow_challenge,omitempty"`
+	PowSolution  string `json:"pow_solution,omitempty"`
 }
 
 // Request payload for updating a review
@@ -21,15 +29,19 @@ type ReviewUpdateRequest struct {
 	Content *string `json:"content,omitempty"`
 }
 
-// Response payload for a single review
+// Response payload for a single review.
+// Email is only populated for admin/moderator callers.
 type ReviewResponse struct {
-	ID        uuid.UUID `json:"id"`
-	BookID    uuid.UUID `json:"book_id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Content   string    `json:"content"`
-	CreatedAt int64     `json:"created_at"`
-	UpdatedAt int64     `json:"updated_at"`
+	ID          uuid.UUID `json:"id"`
+	BookID      uuid.UUID `json:"book_id"`
+	Name        string    `json:"name"`
+	AvatarHash  string    `json:"avatar_hash"`
+	Email       string    `json:"email,omitempty"`
+	Content     string    `json:"content"`
+	ContentHTML string    `json:"content_html"`
+	Status      string    `json:"status"`
+	CreatedAt   int64     `json:"created_at"`
+	UpdatedAt   int64     `json:"updated_at"`
 }
 
 // Response for list of reviews
@@ -39,23 +51,31 @@ type ReviewListResponse struct {
 }
 
 // Convert Review model -> ReviewResponse
-func ToReviewResponse(review *model.Review) *ReviewResponse {
-	return &ReviewResponse{
-		ID:        review.ID,
-		BookID:    review.BookID,
-		Name:      review.Name,
-		Email:     review.Email,
-		Content:   review.Content,
-		CreatedAt: review.CreatedAt,
-		UpdatedAt: review.UpdatedAt,
+func ToReviewResponse(review *model.Review, withEmail bool) *ReviewResponse {
+	response := &ReviewResponse{
+		ID:          review.ID,
+		BookID:      review.BookID,
+		Name:        review.Name,
+		AvatarHash:  AvatarHash(review.Email),
+		Content:     review.Content,
+		ContentHTML: review.ContentHTML,
+		Status:      review.Status,
+		CreatedAt:   review.CreatedAt,
+		UpdatedAt:   review.UpdatedAt,
+	}
+
+	if withEmail {
+		response.Email = review.Email
 	}
+
+	return response
 }
 
 // Convert slice of Reviews -> ReviewListResponse
-func ToReviewListResponse(reviews []model.Review, meta PaginationMeta) ReviewListResponse {
+func ToReviewListResponse(reviews []model.Review, meta PaginationMeta, withEmail bool) ReviewListResponse {
 	responses := make([]ReviewResponse, 0, len(reviews))
 	for _, r := range reviews {
-		responses = append(responses, *ToReviewResponse(&r))
+		responses = append(responses, *ToReviewResponse(&r, withEmail))
 	}
 
 	return ReviewListResponse{
@@ -63,3 +83,14 @@ func ToReviewListResponse(reviews []model.Review, meta PaginationMeta) ReviewLis
 		Data: responses,
 	}
 }
+
+// AvatarHash returns the Gravatar-compatible SHA-256 hash of an email
+func AvatarHash(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
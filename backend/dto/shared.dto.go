@@ -4,6 +4,8 @@ type QueryParams struct {
 	Query  string
 	Limit  int
 	Offset int
+	// IncludeHidden also returns records hidden by moderation
+	IncludeHidden bool
 }
 
 type PaginationMeta struct {
@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/google/uuid"
+
+type TrashQueryParams struct {
+	// book (default) or review
+	Type   string `query:"type"`
+	Offset int    `query:"offset"`
+	Limit  int    `query:"limit"`
+}
+
+// TrashItem is a soft-deleted book or review and when it will be purged for good
+type TrashItem struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+	// Book a trashed review belongs to
+	BookID *uuid.UUID `json:"book_id,omitempty"`
+	// Book title or reviewer name
+	Label     string `json:"label"`
+	DeletedAt int64  `json:"deleted_at"`
+	PurgeAt   int64  `json:"purge_at"`
+}
+
+type TrashListResponse struct {
+	Meta PaginationMeta `json:"meta"`
+	Data []TrashItem    `json:"data"`
+}
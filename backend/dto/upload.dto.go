@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+// UploadCreateRequest describes the file the client is about to upload
+type UploadCreateRequest struct {
+	ContentType string `json:"content_type"`
+	// Exact size in bytes; the upload URL only accepts a body of this size
+	Size int64 `json:"size"`
+}
+
+// UploadResponse tells the client where to send the file. The request must use the given
+// method and headers before the URL expires.
+type UploadResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+// UploadFinalizeRequest attaches an uploaded cover to a book
+type UploadFinalizeRequest struct {
+	BookID uuid.UUID `json:"book_id"`
+	// Recorded on the book revision, like the reason of a book update
+	Reason string `json:"reason,omitempty"`
+}
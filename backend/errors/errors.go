@@ -38,6 +38,22 @@ func NewBadRequestError(message string) *AppError {
 	}
 }
 
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Code:    401,
+		Message: message,
+		Err:     errors.New("unauthorized"),
+	}
+}
+
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:    403,
+		Message: message,
+		Err:     errors.New("forbidden"),
+	}
+}
+
 func NewConflictError(message string) *AppError {
 	return &AppError{
 		Code:    409,
@@ -46,6 +62,14 @@ func NewConflictError(message string) *AppError {
 	}
 }
 
+func NewTooManyRequestsError(message string) *AppError {
+	return &AppError{
+		Code:    429,
+		Message: message,
+		Err:     errors.New("too many requests"),
+	}
+}
+
 func NewInternalError(err error) *AppError {
 	return &AppError{
 		Code:    500,
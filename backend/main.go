@@ -4,20 +4,27 @@ import (
 	_ "embed"
 	"log"
 	"os"
+	"strings"
 
 	"honya/backend/config"
 	"honya/backend/middleware"
+	"honya/backend/repository"
 	"honya/backend/router"
+	"honya/backend/utils"
 
 	"github.com/gofiber/contrib/swagger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 // @title Honya API
 // @version 1.0
 // @description API for managing books and reviews
 // @BasePath /api
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 
 //go:embed docs/swagger.json
 var swaggerJson []byte
@@ -31,6 +38,14 @@ func main() {
 	app := fiber.New(fiber.Config{
 		AppName:      "Honya API",
 		ErrorHandler: middleware.ErrorHandler,
+		// Room for a full-size cover plus the other form fields
+		BodyLimit: utils.CoverMaxBytes + 1<<20,
+		// ctx.IP() is the address in X-Forwarded-For only for requests coming from a trusted
+		// proxy such as the frontend; anyone else gets their own address
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          env.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	cfg := swagger.Config{
@@ -43,10 +58,27 @@ func main() {
 
 	app.Use(swagger.New(cfg))
 	app.Use(cors.New())
+	app.Use(requestid.New())
 
 	app.Use(config.SetupLogger(env.LogStack, env.LogRetention))
 
 	config.ConnectToDatabase(env.DatabaseURL)
+	repository.ConnectToStorage(env)
+
+	// Covers stored on local disk are served by the API itself. Content-addressed covers never
+	// change under their key, so browsers and CDNs may keep them for good.
+	if env.StorageDriver == utils.StorageDriverLocal {
+		storagePath := utils.StoragePath(env.LocalStorageURL)
+		app.Static(storagePath, env.LocalStorageDir, fiber.Static{
+			MaxAge: 3600,
+			ModifyResponse: func(c *fiber.Ctx) error {
+				if utils.IsContentKey(strings.TrimPrefix(c.Path(), strings.TrimSuffix(storagePath, "/")+"/")) {
+					c.Set(fiber.HeaderCacheControl, utils.ImmutableCacheControl)
+				}
+				return nil
+			},
+		})
+	}
 
 	router.Setup(app)
 
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"honya/backend/model"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Audit writes an audit log entry for each successful request whose handler reported a
+// change with utils.SetAudit. Failed requests changed nothing and are not logged.
+func Audit(service service.AuditService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		event := utils.GetAudit(c)
+		status := c.Response().StatusCode()
+		if event == nil || status >= fiber.StatusBadRequest {
+			return nil
+		}
+
+		entry := &model.AuditLog{
+			Actor:      utils.GetRole(c),
+			IP:         c.IP(),
+			RequestID:  c.GetRespHeader(fiber.HeaderXRequestID),
+			Method:     c.Method(),
+			Route:      c.Route().Path,
+			Status:     status,
+			EntityType: event.EntityType,
+			EntityID:   event.EntityID,
+			Action:     event.Action,
+			Summary:    event.Summary,
+			CreatedAt:  time.Now().Unix(),
+		}
+
+		// The change is already committed, so a failed write is logged rather than
+		// turned into an error response
+		if err := service.Record(entry); err != nil {
+			log.Printf("Audit: failed to record %s %s %s: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+		}
+
+		return nil
+	}
+}
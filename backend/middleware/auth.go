@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"honya/backend/config"
+	"honya/backend/errors"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Authenticate resolves the caller's role from the X-API-Key header.
+// Requests without a key are treated as public.
+func Authenticate() fiber.Handler {
+	env, _ := config.GetEnvConfig()
+
+	// Checked in order, admin first, so a key can only ever resolve to one role
+	type roleKey struct {
+		role string
+		key  string
+	}
+	apiKeys := []roleKey{}
+	if env.AdminApiKey != "" {
+		apiKeys = append(apiKeys, roleKey{utils.RoleAdmin, env.AdminApiKey})
+	}
+	if env.ModeratorApiKey != "" {
+		apiKeys = append(apiKeys, roleKey{utils.RoleModerator, env.ModeratorApiKey})
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(utils.ApiKeyHeader)
+		if key == "" {
+			c.Locals(utils.RoleLocalsKey, utils.RolePublic)
+			return c.Next()
+		}
+
+		for _, apiKey := range apiKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey.key)) == 1 {
+				c.Locals(utils.RoleLocalsKey, apiKey.role)
+				return c.Next()
+			}
+		}
+
+		return errors.NewUnauthorizedError("Invalid API key")
+	}
+}
+
+// RequireRole rejects callers whose role is not one of the given roles
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role := utils.GetRole(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+
+		if role == utils.RolePublic {
+			return errors.NewUnauthorizedError("Authentication required")
+		}
+		return errors.NewForbiddenError("Insufficient permissions")
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// ChangeRequestRateLimiter limits suggested book edits per client IP, so one visitor cannot
+// flood the editors' queue.
+func ChangeRequestRateLimiter() fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        utils.ChangeRequestRateLimitMax,
+		Expiration: utils.ChangeRequestRateLimitWindow,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "change-request:" + c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many changes suggested. Please try again later.",
+			})
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// ReportRateLimiter limits reader reports per client IP, so one reporter cannot
+// hide reviews by cycling through them faster than moderators can approve them.
+func ReportRateLimiter() fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        utils.ReportRateLimitMax,
+		Expiration: utils.ReportRateLimitWindow,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "report:" + c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many reports submitted. Please try again later.",
+			})
+		},
+	})
+}
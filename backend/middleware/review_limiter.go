@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"honya/backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// ReviewRateLimiter limits review submissions per client IP,
+// independently of the global API rate limiter. Reviews arrive through the frontend, so the
+// client IP is only right when the frontend is listed in TRUSTED_PROXIES.
+func ReviewRateLimiter() fiber.Handler {
+	env, _ := config.GetEnvConfig()
+
+	return limiter.New(limiter.Config{
+		Max:        env.ReviewRateLimitMax,
+		Expiration: env.ReviewRateLimitWindow,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "review:" + c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many reviews submitted. Please try again later.",
+			})
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// AuditLog records one mutating API call: who made it, from where, and what it changed.
+// Rows are append-only and chained: each hash covers the row's fields and the hash of the
+// row before it, so editing or removing an entry breaks every hash after it.
+type AuditLog struct {
+	ID         uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	Actor      string `gorm:"type:varchar(20);not null;index" json:"actor"`
+	IP         string `gorm:"type:varchar(45)" json:"ip"`
+	RequestID  string `gorm:"type:text" json:"request_id"`
+	Method     string `gorm:"type:varchar(10);not null" json:"method"`
+	Route      string `gorm:"type:varchar(255);not null" json:"route"`
+	Status     int    `gorm:"not null" json:"status"`
+	EntityType string `gorm:"type:varchar(30);not null;index:idx_audit_logs_entity" json:"entity_type"`
+	EntityID   string `gorm:"type:varchar(64);index:idx_audit_logs_entity" json:"entity_id"`
+	Action     string `gorm:"type:varchar(20);not null;index" json:"action"`
+	Summary    string `gorm:"type:text" json:"summary"`
+	CreatedAt  int64  `gorm:"not null;index" json:"created_at"`
+	PrevHash   string `gorm:"type:varchar(64)" json:"prev_hash"`
+	Hash       string `gorm:"type:varchar(64);not null;uniqueIndex" json:"hash"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// ComputeHash returns the hex-encoded SHA-256 of the previous hash and the entry's fields.
+// The ID is left out because it is only known once the row is inserted.
+func (l *AuditLog) ComputeHash() string {
+	payload, _ := json.Marshal([]interface{}{
+		l.PrevHash, l.Actor, l.IP, l.RequestID, l.Method, l.Route, l.Status,
+		l.EntityType, l.EntityID, l.Action, l.Summary, l.CreatedAt,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,16 @@
+package model
+
+// BlobDeletion is a blob the image garbage collector failed to delete. It is retried with a
+// growing delay until the delete succeeds or the blob is referenced again.
+type BlobDeletion struct {
+	Key           string `gorm:"type:varchar(512);primaryKey" json:"key"`
+	Attempts      int    `gorm:"not null;default:0" json:"attempts"`
+	LastError     string `gorm:"type:text" json:"last_error"`
+	NextAttemptAt int64  `gorm:"not null;index" json:"next_attempt_at"`
+	CreatedAt     int64  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     int64  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (BlobDeletion) TableName() string {
+	return "blob_deletions"
+}
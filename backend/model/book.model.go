@@ -1,6 +1,10 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -18,6 +22,16 @@ type Book struct {
 	CreatedAt       int64     `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       int64     `gorm:"autoUpdateTime" json:"updated_at"`
 	AuthorName      string    `gorm:"type:varchar(100)" json:"author_name"`
+	// Variants generated from an uploaded cover; nil when Image is an external URL
+	Images *BookImages `gorm:"type:jsonb" json:"images,omitempty"`
+	// New reviews are held for moderation until this unix time after unusual activity
+	ReviewsHeldUntil int64 `gorm:"not null;default:0" json:"reviews_held_until,omitempty"`
+	// draft, scheduled, published or archived; only published books are listed publicly
+	Status string `gorm:"type:varchar(20);not null;default:'published';index" json:"status"`
+	// Unix time a scheduled book goes live, or the time a published book went live
+	PublishAt int64 `gorm:"not null;default:0;index" json:"publish_at,omitempty"`
+	// Set while the book is in the trash; purged for good after the retention period
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Reviews []Review `gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"reviews,omitempty"`
 }
@@ -32,3 +46,52 @@ func (b *Book) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return nil
 }
+
+// BookImages describes the variants generated from an uploaded cover. Width and height are
+// those of the upright original.
+type BookImages struct {
+	Width         int                     `json:"width"`
+	Height        int                     `json:"height"`
+	Blurhash      string                  `json:"blurhash"`
+	DominantColor string                  `json:"dominant_color"`
+	Variants      map[string]ImageVariant `json:"variants"`
+}
+
+// ImageVariant is one resized copy of a cover, encoded as JPEG and WebP
+type ImageVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	JPEG   string `json:"jpeg"`
+	WebP   string `json:"webp"`
+}
+
+// URLs returns the URL of every stored variant
+func (i *BookImages) URLs() []string {
+	if i == nil {
+		return nil
+	}
+	urls := make([]string, 0, 2*len(i.Variants))
+	for _, variant := range i.Variants {
+		urls = append(urls, variant.JPEG, variant.WebP)
+	}
+	return urls
+}
+
+func (i BookImages) Value() (driver.Value, error) {
+	payload, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	return string(payload), nil
+}
+
+func (i *BookImages) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, i)
+	case string:
+		return json.Unmarshal([]byte(v), i)
+	default:
+		return fmt.Errorf("cannot scan %T into BookImages", value)
+	}
+}
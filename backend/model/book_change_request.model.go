@@ -0,0 +1,38 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookChangeRequest is a reader's proposed correction to a book. Before holds the proposed
+// fields as they were when the request was made, After the values proposed.
+type BookChangeRequest struct {
+	ID      uuid.UUID              `gorm:"type:uuid;primaryKey" json:"id"`
+	BookID  uuid.UUID              `gorm:"type:uuid;not null;index" json:"book_id"`
+	Before  map[string]interface{} `gorm:"type:jsonb;serializer:json;not null" json:"before"`
+	After   map[string]interface{} `gorm:"type:jsonb;serializer:json;not null" json:"after"`
+	Comment string                 `gorm:"type:text" json:"comment"`
+	Name    string                 `gorm:"type:varchar(100)" json:"name"`
+	Email   string                 `gorm:"type:varchar(100);index" json:"email"`
+	// pending, accepted or rejected
+	Status       string `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	ReviewerNote string `gorm:"type:text" json:"reviewer_note"`
+	ReviewedBy   string `gorm:"type:varchar(20)" json:"reviewed_by"`
+	ReviewedAt   int64  `gorm:"not null;default:0" json:"reviewed_at"`
+	CreatedAt    int64  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    int64  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Book Book `gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (BookChangeRequest) TableName() string {
+	return "book_change_requests"
+}
+
+func (r *BookChangeRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookImage is one image in a book's gallery: a front, back or spine cover or a sample page.
+// Position orders the gallery from 0. The first front cover is the book's primary cover and
+// is copied to Book.Image and Book.Images.
+type BookImage struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	BookID   uuid.UUID `gorm:"type:uuid;not null;index:idx_book_images_position" json:"book_id"`
+	Role     string    `gorm:"type:varchar(20);not null" json:"role"`
+	Position int       `gorm:"not null;default:0;index:idx_book_images_position" json:"position"`
+	AltEn    string    `gorm:"type:text" json:"alt_en"`
+	AltJa    string    `gorm:"type:text" json:"alt_ja"`
+	// URL of the large JPEG variant
+	URL string `gorm:"type:varchar(255);not null" json:"url"`
+	// Size, blurhash, dominant colour and variants generated when the image was processed
+	Metadata  *BookImages `gorm:"type:jsonb" json:"metadata"`
+	CreatedAt int64       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt int64       `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Book Book `gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (BookImage) TableName() string {
+	return "book_images"
+}
+
+func (i *BookImage) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
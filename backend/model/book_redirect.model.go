@@ -0,0 +1,16 @@
+package model
+
+import "github.com/google/uuid"
+
+// BookRedirect points the ID of a book that was merged away at the book it was merged into
+type BookRedirect struct {
+	FromID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"from_id"`
+	ToID      uuid.UUID `gorm:"type:uuid;not null;index" json:"to_id"`
+	CreatedAt int64     `gorm:"autoCreateTime" json:"created_at"`
+
+	To Book `gorm:"foreignKey:ToID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (BookRedirect) TableName() string {
+	return "book_redirects"
+}
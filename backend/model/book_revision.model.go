@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookRevision records one change to a book: the changed columns before and after, who made
+// it and why. Numbers count up from 1 per book.
+type BookRevision struct {
+	ID        uuid.UUID              `gorm:"type:uuid;primaryKey" json:"id"`
+	BookID    uuid.UUID              `gorm:"type:uuid;not null;uniqueIndex:idx_book_revisions_number" json:"book_id"`
+	Number    int                    `gorm:"not null;uniqueIndex:idx_book_revisions_number" json:"number"`
+	Actor     string                 `gorm:"type:varchar(20);not null" json:"actor"`
+	Reason    string                 `gorm:"type:text" json:"reason"`
+	Before    map[string]interface{} `gorm:"type:jsonb;serializer:json;not null" json:"before"`
+	After     map[string]interface{} `gorm:"type:jsonb;serializer:json;not null" json:"after"`
+	CreatedAt int64                  `gorm:"autoCreateTime" json:"created_at"`
+
+	Book Book `gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (BookRevision) TableName() string {
+	return "book_revisions"
+}
+
+func (r *BookRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
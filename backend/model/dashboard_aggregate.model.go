@@ -0,0 +1,24 @@
+package model
+
+// DashboardAggregate is a precomputed count behind a dashboard chart, e.g. the number
+// of books in one category or of reviews written by one reviewer
+type DashboardAggregate struct {
+	Dimension string `gorm:"type:varchar(50);primaryKey" json:"dimension"`
+	Key       string `gorm:"type:varchar(255);primaryKey" json:"key"`
+	Count     int64  `gorm:"not null;default:0" json:"count"`
+	UpdatedAt int64  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (DashboardAggregate) TableName() string {
+	return "dashboard_aggregates"
+}
+
+// DashboardAggregateState tracks when a dimension was last fully rebuilt
+type DashboardAggregateState struct {
+	Dimension   string `gorm:"type:varchar(50);primaryKey" json:"dimension"`
+	RefreshedAt int64  `gorm:"not null" json:"refreshed_at"`
+}
+
+func (DashboardAggregateState) TableName() string {
+	return "dashboard_aggregate_states"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataSubjectRequest records a GDPR access or erasure request.
+// The subject's email is stored as a keyed hash so the trail itself holds no personal data.
+type DataSubjectRequest struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Type            string    `gorm:"type:varchar(20);not null" json:"type"`
+	EmailHMAC       string    `gorm:"column:email_hmac;type:varchar(64);not null;index" json:"email_hmac"`
+	Actor           string    `gorm:"type:varchar(100);not null" json:"actor"`
+	KeepContent     bool      `gorm:"not null;default:false" json:"keep_content"`
+	ReviewsAffected int64     `gorm:"not null;default:0" json:"reviews_affected"`
+	// Change requests whose proposer name and email were exported or erased
+	ChangeRequestsAffected int64 `gorm:"not null;default:0" json:"change_requests_affected"`
+	CreatedAt              int64 `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (DataSubjectRequest) TableName() string {
+	return "data_subject_requests"
+}
+
+func (d *DataSubjectRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReportRun is one delivery attempt of a scheduled report
+type ReportRun struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ReportID   uuid.UUID `gorm:"type:uuid;not null;index" json:"report_id"`
+	Trigger    string    `gorm:"type:varchar(20);not null" json:"trigger"`
+	Status     string    `gorm:"type:varchar(20);not null" json:"status"`
+	Error      string    `gorm:"type:text" json:"error,omitempty"`
+	Recipients int       `gorm:"not null;default:0" json:"recipients"`
+	StartedAt  int64     `gorm:"not null" json:"started_at"`
+	FinishedAt int64     `json:"finished_at"`
+
+	Report ScheduledReport `gorm:"foreignKey:ReportID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (ReportRun) TableName() string {
+	return "report_runs"
+}
+
+func (r *ReportRun) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -6,13 +6,22 @@ import (
 )
 
 type Review struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	BookID    uuid.UUID `gorm:"type:uuid;not null" json:"book_id"`
-	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
-	Email     string    `gorm:"type:varchar(100);not null" json:"email"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
-	CreatedAt int64     `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt int64     `gorm:"autoUpdateTime" json:"updated_at"`
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	BookID          uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_book_email" json:"book_id"`
+	Name            string    `gorm:"type:varchar(100);not null" json:"name"`
+	Email           string    `gorm:"type:varchar(100)" json:"email"`
+	EmailNormalized string    `gorm:"type:varchar(100);uniqueIndex:idx_reviews_book_email" json:"-"`
+	Content         string    `gorm:"type:text;not null" json:"content"`
+	ContentHTML     string    `gorm:"type:text" json:"content_html"`
+	Anonymized      bool      `gorm:"not null;default:false" json:"anonymized"`
+	Status          string    `gorm:"type:varchar(20);not null;default:'published';index" json:"status"`
+	ReportCount     int64     `gorm:"not null;default:0" json:"report_count"`
+	IPRange         string    `gorm:"type:varchar(50)" json:"-"`
+	CreatedAt       int64     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       int64     `gorm:"autoUpdateTime" json:"updated_at"`
+	// Set while the review is in the trash. It still counts against the reviewer's one
+	// review per book until it is purged.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Book Book `gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
 }
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReviewAlert is unusual review activity on a book, such as a spike against its hourly
+// baseline or a burst from one email domain or IP range. Subject names the domain or range.
+type ReviewAlert struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	BookID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_review_alerts_window;index" json:"book_id"`
+	Type        string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_review_alerts_window" json:"type"`
+	Subject     string    `gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_review_alerts_window" json:"subject,omitempty"`
+	WindowStart int64     `gorm:"not null;uniqueIndex:idx_review_alerts_window" json:"window_start"`
+	Count       int64     `gorm:"not null" json:"count"`
+	Baseline    float64   `gorm:"not null;default:0" json:"baseline"`
+	Score       float64   `gorm:"not null;default:0" json:"score"`
+	HeldUntil   int64     `gorm:"not null;default:0" json:"held_until"`
+	CreatedAt   int64     `gorm:"autoCreateTime;index" json:"created_at"`
+
+	Book Book `gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (ReviewAlert) TableName() string {
+	return "review_alerts"
+}
+
+func (a *ReviewAlert) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
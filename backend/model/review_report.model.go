@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReviewReport is a reader's flag on a review. Reporters are identified by a
+// hash of their IP so each one can only report a review once. Reports are kept
+// once a moderator approves the review; ResolvedAt then marks them as handled.
+type ReviewReport struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ReviewID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_review_reports_reporter" json:"review_id"`
+	ReporterHash string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_review_reports_reporter" json:"-"`
+	Reason       string    `gorm:"type:varchar(20);not null" json:"reason"`
+	Details      string    `gorm:"type:text" json:"details"`
+	ResolvedAt   int64     `gorm:"not null;default:0;index" json:"resolved_at,omitempty"`
+	CreatedAt    int64     `gorm:"autoCreateTime" json:"created_at"`
+
+	Review Review `gorm:"foreignKey:ReviewID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (ReviewReport) TableName() string {
+	return "review_reports"
+}
+
+func (r *ReviewReport) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
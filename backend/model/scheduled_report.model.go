@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScheduledReport emails a summary of selected dashboard charts on a cron schedule
+type ScheduledReport struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Name       string    `gorm:"type:varchar(100);not null" json:"name"`
+	Cron       string    `gorm:"type:varchar(100);not null" json:"cron"`
+	Timezone   string    `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+	Recipients []string  `gorm:"type:jsonb;serializer:json;not null" json:"recipients"`
+	Charts     []string  `gorm:"type:jsonb;serializer:json;not null" json:"charts"`
+	Format     string    `gorm:"type:varchar(10);not null;default:'csv'" json:"format"`
+	Enabled    bool      `gorm:"not null;default:true" json:"enabled"`
+	LastRunAt  *int64    `json:"last_run_at"`
+	NextRunAt  *int64    `gorm:"index" json:"next_run_at"`
+	CreatedAt  int64     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  int64     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (ScheduledReport) TableName() string {
+	return "scheduled_reports"
+}
+
+func (r *ScheduledReport) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package model
+
+// SpentChallenge is a redeemed proof-of-work challenge. The primary key lets each challenge be
+// redeemed once across every instance; rows are dropped once they expire.
+type SpentChallenge struct {
+	Nonce     string `gorm:"type:varchar(32);primaryKey" json:"nonce"`
+	ExpiresAt int64  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt int64  `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (SpentChallenge) TableName() string {
+	return "spent_challenges"
+}
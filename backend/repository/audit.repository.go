@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/dto"
+	"honya/backend/model"
+	"honya/backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository appends to and reads the hash-chained audit log. There is no update or
+// delete: the table is append-only.
+type AuditRepository interface {
+	Append(entry *model.AuditLog) error
+	FindAll(params dto.AuditQueryParams) ([]model.AuditLog, dto.PaginationMeta, error)
+	FindAfter(afterID uint64, limit int) ([]model.AuditLog, error)
+}
+
+type AuditRepositoryImpl struct {
+	*BaseRepository[model.AuditLog]
+}
+
+func NewAuditRepository() AuditRepository {
+	return &AuditRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.AuditLog](config.DB.Db),
+	}
+}
+
+// Append links the entry to the last one in the chain and inserts it. An advisory lock
+// keeps concurrent appends from linking to the same predecessor.
+func (r *AuditRepositoryImpl) Append(entry *model.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", utils.AuditChainLockID).Error; err != nil {
+			return err
+		}
+
+		var last model.AuditLog
+		if err := tx.Select("hash").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+
+		entry.PrevHash = last.Hash
+		entry.Hash = entry.ComputeHash()
+
+		return tx.Create(entry).Error
+	})
+}
+
+// FindAll returns the entries matching the filters, newest first
+func (r *AuditRepositoryImpl) FindAll(params dto.AuditQueryParams) ([]model.AuditLog, dto.PaginationMeta, error) {
+	var entries []model.AuditLog
+	var totalCount int64
+
+	query := r.db.Model(&model.AuditLog{})
+	if params.Actor != "" {
+		query = query.Where("actor = ?", params.Actor)
+	}
+	if params.EntityType != "" {
+		query = query.Where("entity_type = ?", params.EntityType)
+	}
+	if params.EntityID != "" {
+		query = query.Where("entity_id = ?", params.EntityID)
+	}
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+	if params.From > 0 {
+		query = query.Where("created_at >= ?", params.From)
+	}
+	if params.To > 0 {
+		query = query.Where("created_at <= ?", params.To)
+	}
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order("id DESC").Offset(params.Offset).Limit(params.Limit).Find(&entries).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	return entries, dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     params.Offset,
+		Limit:      params.Limit,
+	}, nil
+}
+
+// FindAfter returns up to limit entries following afterID in chain order
+func (r *AuditRepositoryImpl) FindAfter(afterID uint64, limit int) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	if err := r.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -83,3 +83,32 @@ func (r *BaseRepository[T]) Delete(id uuid.UUID) error {
 	var entity T
 	return r.db.Delete(&entity, "id = ?", id).Error
 }
+
+// CountByInterval counts rows created in [from, to) per interval bucket, keyed by the
+// bucket's start date (YYYY-MM-DD) in the given timezone.
+func (r *BaseRepository[T]) CountByInterval(interval, timezone string, from, to int64) (map[string]int64, error) {
+	return r.countByInterval(r.db, interval, timezone, from, to)
+}
+
+// countByInterval is CountByInterval over the rows the scope selects
+func (r *BaseRepository[T]) countByInterval(scope *gorm.DB, interval, timezone string, from, to int64) (map[string]int64, error) {
+	var results []struct {
+		Key   string `gorm:"column:key"`
+		Count int64  `gorm:"column:count"`
+	}
+
+	if err := scope.Model(new(T)).
+		Select("to_char(date_trunc(?, to_timestamp(created_at) AT TIME ZONE ?), 'YYYY-MM-DD') as key, COUNT(*) as count", interval, timezone).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("key").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, result := range results {
+		counts[result.Key] = result.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"honya/backend/config"
+	"honya/backend/utils"
+	"io"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlobStore keeps publicly served files such as book covers. Keys are slash separated
+// paths (e.g. "books/<sha256>.jpg"); every backend maps a key to the URL the file
+// is served from and back, so only URLs need to be stored on the rows.
+type BlobStore interface {
+	Put(key string, body io.Reader, contentType string) (string, error)
+	Delete(key string) error
+	Exists(key string) (bool, error)
+	// Touch makes an existing blob count as just written, so the image garbage collector's grace
+	// period starts over, without sending its bytes again. It reports whether the blob exists.
+	Touch(key string) (bool, error)
+	// List returns every blob whose key starts with prefix
+	List(prefix string) ([]BlobObject, error)
+	// Open reads a stored blob; a missing key returns ErrBlobNotFound
+	Open(key string) (io.ReadCloser, error)
+	// PresignPut returns a URL that accepts a PUT of exactly size bytes of contentType until it expires
+	PresignPut(key, contentType string, size int64, expires time.Duration) (string, error)
+	URL(key string) string
+	// Key returns the key behind a URL handed out by this store, or false for foreign URLs
+	Key(url string) (string, bool)
+}
+
+// UploadVerifier is implemented by backends whose presigned uploads are sent to the API itself
+// rather than to a storage service. The API checks the signature and then stores the body.
+type UploadVerifier interface {
+	VerifyUpload(key, contentType string, size, expires int64, signature string) error
+}
+
+// BlobObject is a stored blob as listed by BlobStore.List
+type BlobObject struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+var blobStore BlobStore
+
+// NewBlobStore opens the backend selected by STORAGE_DRIVER.
+func NewBlobStore(env config.EnvConfig) (BlobStore, error) {
+	switch env.StorageDriver {
+	case utils.StorageDriverLocal:
+		return NewLocalBlobStore(env.LocalStorageDir, env.LocalStorageURL, env.UploadSigningSecret)
+	case utils.StorageDriverS3:
+		return NewS3BlobStore(env)
+	case utils.StorageDriverMemory:
+		memory := NewMemoryBlobStore(env.LocalStorageURL)
+		memory.uploadSigner = newUploadSigner(env.UploadSigningSecret)
+		return memory, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", env.StorageDriver)
+	}
+}
+
+// ConnectToStorage opens the configured blob store once so that every router shares it.
+func ConnectToStorage(env config.EnvConfig) {
+	store, err := NewBlobStore(env)
+	if err != nil {
+		log.Fatalf("Failed to open blob storage: %v", err)
+	}
+	blobStore = store
+}
+
+func GetBlobStore() BlobStore {
+	return blobStore
+}
+
+// blobURLs maps keys to URLs below a base URL.
+type blobURLs struct {
+	baseURL string
+}
+
+func (u blobURLs) URL(key string) string {
+	return u.baseURL + "/" + key
+}
+
+func (u blobURLs) Key(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, u.baseURL+"/")
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// validBlobKey rejects keys that could escape the store's root.
+func validBlobKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+// uploadSigner presigns uploads that the API receives itself: the URL carries an expiry and an
+// HMAC over the key, content type, size and expiry.
+type uploadSigner struct {
+	secret []byte
+}
+
+// newUploadSigner signs with secret, or with a random key when it is empty. Without a shared
+// secret, URLs only work on the instance that issued them.
+func newUploadSigner(secret string) uploadSigner {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return uploadSigner{secret: key}
+}
+
+func (s uploadSigner) sign(key, contentType string, size, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%d", key, contentType, size, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s uploadSigner) presign(urls blobURLs, key, contentType string, size int64, expires time.Duration) (string, error) {
+	if err := validBlobKey(key); err != nil {
+		return "", err
+	}
+	expiresAt := time.Now().Add(expires).Unix()
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expiresAt, 10)},
+		"signature": {s.sign(key, contentType, size, expiresAt)},
+	}
+	return urls.URL(key) + "?" + query.Encode(), nil
+}
+
+func (s uploadSigner) VerifyUpload(key, contentType string, size, expires int64, signature string) error {
+	if time.Now().Unix() > expires {
+		return errors.New("upload URL has expired")
+	}
+	// The size is the body actually received, so a body of another size fails here too
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, contentType, size, expires))) {
+		return errors.New("upload signature does not match")
+	}
+	return nil
+}
+
+// localBlobStore writes files below a directory that main.go serves with Fiber's static handler.
+type localBlobStore struct {
+	blobURLs
+	uploadSigner
+	dir string
+}
+
+// Name of the temporary files Put writes before renaming them into place
+const localTempPattern = ".upload-*"
+
+func NewLocalBlobStore(dir, baseURL, uploadSecret string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &localBlobStore{blobURLs: blobURLs{strings.TrimSuffix(baseURL, "/")}, uploadSigner: newUploadSigner(uploadSecret), dir: dir}, nil
+}
+
+func (s *localBlobStore) path(key string) (string, error) {
+	if err := validBlobKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func (s *localBlobStore) Put(key string, body io.Reader, contentType string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write next to the target and rename, so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), localTempPattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *localBlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *localBlobStore) List(prefix string) ([]BlobObject, error) {
+	objects := []BlobObject{}
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		// Files still being written by Put are not blobs yet
+		if matched, _ := filepath.Match(localTempPattern, entry.Name()); matched {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, BlobObject{Key: key, Size: info.Size(), ModifiedAt: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
+
+func (s *localBlobStore) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (s *localBlobStore) PresignPut(key, contentType string, size int64, expires time.Duration) (string, error) {
+	return s.presign(s.blobURLs, key, contentType, size, expires)
+}
+
+func (s *localBlobStore) Exists(key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+func (s *localBlobStore) Touch(key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	err = os.Chtimes(path, now, now)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MemoryBlobStore keeps blobs in memory. Only presigned uploads are received on its URLs;
+// nothing serves them for reading. It is meant for tests and throwaway development setups.
+type MemoryBlobStore struct {
+	blobURLs
+	uploadSigner
+	mu    sync.RWMutex
+	blobs map[string]memoryBlob
+}
+
+type memoryBlob struct {
+	data        []byte
+	contentType string
+	modifiedAt  time.Time
+}
+
+func NewMemoryBlobStore(baseURL string) *MemoryBlobStore {
+	return &MemoryBlobStore{blobURLs: blobURLs{strings.TrimSuffix(baseURL, "/")}, uploadSigner: newUploadSigner(""), blobs: map[string]memoryBlob{}}
+}
+
+func (s *MemoryBlobStore) Put(key string, body io.Reader, contentType string) (string, error) {
+	if err := validBlobKey(key); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.blobs[key] = memoryBlob{data: data, contentType: contentType, modifiedAt: time.Now()}
+	s.mu.Unlock()
+
+	return s.URL(key), nil
+}
+
+func (s *MemoryBlobStore) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.blobs, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryBlobStore) Exists(key string) (bool, error) {
+	s.mu.RLock()
+	_, ok := s.blobs[key]
+	s.mu.RUnlock()
+	return ok, nil
+}
+
+func (s *MemoryBlobStore) Touch(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blob, ok := s.blobs[key]
+	if ok {
+		blob.modifiedAt = time.Now()
+		s.blobs[key] = blob
+	}
+	return ok, nil
+}
+
+func (s *MemoryBlobStore) List(prefix string) ([]BlobObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := []BlobObject{}
+	for key, blob := range s.blobs {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, BlobObject{Key: key, Size: int64(len(blob.data)), ModifiedAt: blob.modifiedAt})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (s *MemoryBlobStore) Open(key string) (io.ReadCloser, error) {
+	data, _, ok := s.Get(key)
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *MemoryBlobStore) PresignPut(key, contentType string, size int64, expires time.Duration) (string, error) {
+	return s.presign(s.blobURLs, key, contentType, size, expires)
+}
+
+// Get returns a stored blob and its content type.
+func (s *MemoryBlobStore) Get(key string) ([]byte, string, bool) {
+	s.mu.RLock()
+	blob, ok := s.blobs[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, "", false
+	}
+	return bytes.Clone(blob.data), blob.contentType, true
+}
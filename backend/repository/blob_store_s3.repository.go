@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"honya/backend/config"
+	"honya/backend/utils"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3BlobStore talks to AWS S3 or any S3-compatible server (MinIO, R2, ...) set through S3_ENDPOINT.
+type s3BlobStore struct {
+	blobURLs
+	client     *s3.Client
+	presigner  *s3.PresignClient
+	bucketName string
+}
+
+func NewS3BlobStore(env config.EnvConfig) (BlobStore, error) {
+	if env.AWSBucket == "" {
+		return nil, errors.New("AWS_BUCKET_NAME is not set")
+	}
+
+	opts := []func(*s3_config.LoadOptions) error{s3_config.WithRegion(env.AWSRegion)}
+	if env.AWSAccessKey != "" {
+		opts = append(opts, s3_config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(env.AWSAccessKey, env.AWSSecretKey, ""),
+		))
+	}
+
+	cfg, err := s3_config.LoadDefaultConfig(context.TODO(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if env.S3Endpoint != "" {
+			o.BaseEndpoint = aws.String(env.S3Endpoint)
+		}
+		o.UsePathStyle = env.S3ForcePathStyle
+	})
+
+	baseURL, err := s3BaseURL(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3BlobStore{blobURLs: blobURLs{baseURL}, client: client, presigner: s3.NewPresignClient(client), bucketName: env.AWSBucket}, nil
+}
+
+// s3BaseURL is where objects are publicly reachable: S3_PUBLIC_URL when a CDN or proxy sits
+// in front of the bucket, otherwise the bucket's own virtual-hosted or path-style address.
+func s3BaseURL(env config.EnvConfig) (string, error) {
+	if env.S3PublicURL != "" {
+		return env.S3PublicURL, nil
+	}
+
+	if env.S3Endpoint == "" {
+		if env.S3ForcePathStyle {
+			return fmt.Sprintf("https://s3.%s.amazonaws.com/%s", env.AWSRegion, env.AWSBucket), nil
+		}
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com", env.AWSBucket, env.AWSRegion), nil
+	}
+
+	endpoint, err := url.Parse(env.S3Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return "", fmt.Errorf("invalid S3_ENDPOINT %q", env.S3Endpoint)
+	}
+	if env.S3ForcePathStyle {
+		return strings.TrimSuffix(env.S3Endpoint, "/") + "/" + env.AWSBucket, nil
+	}
+	endpoint.Host = env.AWSBucket + "." + endpoint.Host
+	return strings.TrimSuffix(endpoint.String(), "/"), nil
+}
+
+func (s *s3BlobStore) Put(key string, body io.Reader, contentType string) (string, error) {
+	if err := validBlobKey(key); err != nil {
+		return "", err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+		Body:   body,
+		ACL:    types.ObjectCannedACLPublicRead,
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if utils.IsContentKey(key) {
+		input.CacheControl = aws.String(utils.ImmutableCacheControl)
+	}
+
+	uploader := manager.NewUploader(s.client)
+	if _, err := uploader.Upload(context.TODO(), input); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *s3BlobStore) Delete(key string) error {
+	_, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *s3BlobStore) Exists(key string) (bool, error) {
+	_, err := s.client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Touch copies the object onto itself, which S3 only allows when the metadata is replaced, so
+// the content type and cache headers are carried over from the object
+func (s *s3BlobStore) Touch(key string) (bool, error) {
+	head, err := s.client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err = s.client.CopyObject(context.TODO(), &s3.CopyObjectInput{
+		Bucket:            aws.String(s.bucketName),
+		Key:               aws.String(key),
+		CopySource:        aws.String(s.bucketName + "/" + url.PathEscape(key)),
+		MetadataDirective: types.MetadataDirectiveReplace,
+		Metadata:          head.Metadata,
+		ContentType:       head.ContentType,
+		CacheControl:      head.CacheControl,
+		ACL:               types.ObjectCannedACLPublicRead,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *s3BlobStore) List(prefix string) ([]BlobObject, error) {
+	objects := []BlobObject{}
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			objects = append(objects, BlobObject{
+				Key:        aws.ToString(object.Key),
+				Size:       aws.ToInt64(object.Size),
+				ModifiedAt: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (s *s3BlobStore) Open(key string) (io.ReadCloser, error) {
+	output, err := s.client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+// PresignPut signs the content type and length, so S3 rejects uploads of any other type or size.
+// Browsers uploading directly need a CORS rule on the bucket that allows PUT.
+func (s *s3BlobStore) PresignPut(key, contentType string, size int64, expires time.Duration) (string, error) {
+	if err := validBlobKey(key); err != nil {
+		return "", err
+	}
+
+	request, err := s.presigner.PresignPutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return request.URL, nil
+}
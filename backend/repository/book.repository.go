@@ -6,9 +6,13 @@ import (
 	"honya/backend/config"
 	"honya/backend/dto"
 	"honya/backend/model"
+	"honya/backend/utils"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BookRepository defines the interface for book data operations
@@ -16,9 +20,22 @@ type BookRepository interface {
 	FindAll(params dto.BookQueryParams) ([]model.Book, dto.PaginationMeta, error)
 	FindByID(id uuid.UUID) (*model.Book, error)
 	Create(book *model.Book) (*model.Book, error)
-	Update(id uuid.UUID, updateData *dto.BookUpdateRequest) (*model.Book, error)
+	Update(id uuid.UUID, updateData *dto.BookUpdateRequest, actor string) (*model.Book, error)
 	Delete(id uuid.UUID) error
 	CountByField(field string) (map[string]int64, error)
+	CountByWidth(field string, width float64) (map[int64]int64, error)
+	CountByEdges(field string, edges []float64) (map[int64]int64, error)
+	CountByInterval(interval, timezone string, from, to int64) (map[string]int64, error)
+	Aggregate(query *dto.AnalyticsQueryRequest) ([]map[string]interface{}, error)
+	HoldReviews(id uuid.UUID, until int64) error
+	FindDuplicateCandidates() ([]model.Book, error)
+	Merge(targetID, sourceID uuid.UUID, updates map[string]interface{}, actor string) (*model.Book, error)
+	FindRedirect(id uuid.UUID) (*model.BookRedirect, error)
+	FindRevisions(bookID uuid.UUID, offset, limit int) ([]model.BookRevision, dto.PaginationMeta, error)
+	FindRevisionsSince(bookID uuid.UUID, number int) ([]model.BookRevision, error)
+	Restore(id uuid.UUID) (*model.Book, error)
+	FindReviewerNames(bookID uuid.UUID) ([]string, error)
+	PublishDue(now int64) ([]model.Book, error)
 }
 
 type BookRepositoryImpl struct {
@@ -58,6 +75,12 @@ func (r *BookRepositoryImpl) FindAll(params dto.BookQueryParams) ([]model.Book,
 		query = query.Where("pages <= ?", params.Pages)
 	}
 
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	} else {
+		query = query.Where("status <> ?", utils.BookStatusArchived)
+	}
+
 	switch params.Sort {
 	case "title":
 		query = query.Order("title ASC")
@@ -92,15 +115,9 @@ func (r *BookRepositoryImpl) FindAll(params dto.BookQueryParams) ([]model.Book,
 	return books, meta, nil
 }
 
-func (r *BookRepositoryImpl) Update(id uuid.UUID, updateData *dto.BookUpdateRequest) (*model.Book, error) {
-	book, err := r.FindByID(id)
-	if err != nil {
-		return nil, err
-	}
-	if book == nil {
-		return nil, gorm.ErrRecordNotFound
-	}
-
+// Update applies the set fields of the request and records the change as a revision by actor.
+// Fields that already hold the requested value are left out of the revision.
+func (r *BookRepositoryImpl) Update(id uuid.UUID, updateData *dto.BookUpdateRequest, actor string) (*model.Book, error) {
 	updates := map[string]interface{}{}
 
 	if updateData.Title != nil {
@@ -114,6 +131,8 @@ func (r *BookRepositoryImpl) Update(id uuid.UUID, updateData *dto.BookUpdateRequ
 	}
 	if updateData.Image != nil {
 		updates["image"] = *updateData.Image
+		// Variants belong to the image they were generated from; a bare URL clears them
+		updates["images"] = updateData.Images
 	}
 	if updateData.PublicationYear != nil {
 		updates["publication_year"] = *updateData.PublicationYear
@@ -127,20 +146,85 @@ func (r *BookRepositoryImpl) Update(id uuid.UUID, updateData *dto.BookUpdateRequ
 	if updateData.AuthorName != nil {
 		updates["author_name"] = *updateData.AuthorName
 	}
-
-	if len(updates) == 0 {
-		return book, nil
+	// Clients cannot change an ISBN, but restoring a revision (e.g. of a merge) can
+	if updateData.Isbn != nil {
+		updates["isbn"] = *updateData.Isbn
+	}
+	if updateData.Status != nil {
+		updates["status"] = *updateData.Status
+	}
+	if updateData.PublishAt != nil {
+		updates["publish_at"] = *updateData.PublishAt
 	}
 
-	if err := r.db.Model(book).Where("id = ?", id).Updates(updates).Error; err != nil {
+	var book model.Book
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return updateWithRevision(tx, &book, updates, actor, updateData.Reason)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := r.db.First(book, "id = ?", id).Error; err != nil {
-		return nil, err
+	return &book, nil
+}
+
+// updateWithRevision writes the columns of updates that differ from the locked book, records
+// them as the book's next revision and reloads the book
+func updateWithRevision(tx *gorm.DB, book *model.Book, updates map[string]interface{}, actor, reason string) error {
+	current := map[string]interface{}{
+		"title":            book.Title,
+		"description":      book.Description,
+		"category":         book.Category,
+		"image":            book.Image,
+		"images":           book.Images,
+		"publication_year": book.PublicationYear,
+		"rating":           book.Rating,
+		"pages":            book.Pages,
+		"author_name":      book.AuthorName,
+		"isbn":             book.Isbn,
+		"status":           book.Status,
+		"publish_at":       book.PublishAt,
+	}
+
+	before := map[string]interface{}{}
+	after := map[string]interface{}{}
+	for column, value := range updates {
+		if current[column] != value {
+			before[column] = current[column]
+			after[column] = value
+		}
+	}
+	if len(after) == 0 {
+		return nil
+	}
+
+	var number int
+	if err := tx.Model(&model.BookRevision{}).
+		Select("COALESCE(MAX(number), 0)").
+		Where("book_id = ?", book.ID).
+		Scan(&number).Error; err != nil {
+		return err
 	}
 
-	return book, nil
+	if err := tx.Create(&model.BookRevision{
+		BookID: book.ID,
+		Number: number + 1,
+		Actor:  actor,
+		Reason: reason,
+		Before: before,
+		After:  after,
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.Book{}).Where("id = ?", book.ID).Updates(after).Error; err != nil {
+		return err
+	}
+
+	return tx.First(book).Error
 }
 
 func (r *BookRepositoryImpl) CountByField(field string) (map[string]int64, error) {
@@ -161,6 +245,7 @@ func (r *BookRepositoryImpl) CountByField(field string) (map[string]int64, error
 
 	query := r.db.Model(&model.Book{}).
 		Select(fmt.Sprintf("%s as key, COUNT(*) as count", field)).
+		Where("status = ?", utils.BookStatusPublished).
 		Group(field)
 
 	if field == "rating" {
@@ -190,3 +275,347 @@ func (r *BookRepositoryImpl) CountByField(field string) (map[string]int64, error
 
 	return counts, nil
 }
+
+// CountByInterval counts the published books created in [from, to) per interval bucket, so the
+// timeseries agrees with the other dashboard charts and never reveals unpublished books
+func (r *BookRepositoryImpl) CountByInterval(interval, timezone string, from, to int64) (map[string]int64, error) {
+	return r.countByInterval(r.db.Where("status = ?", utils.BookStatusPublished), interval, timezone, from, to)
+}
+
+var bucketableFields = map[string]bool{
+	"rating":           true,
+	"publication_year": true,
+	"pages":            true,
+}
+
+// CountByWidth counts books per fixed-width bucket, keyed by the bucket index FLOOR(field / width)
+func (r *BookRepositoryImpl) CountByWidth(field string, width float64) (map[int64]int64, error) {
+	if !bucketableFields[field] || width <= 0 {
+		return nil, errors.New("invalid field for bucketing")
+	}
+
+	return r.countByBucket(fmt.Sprintf("FLOOR(%s / ?)::bigint", field), field, width)
+}
+
+// CountByEdges counts books per explicit bucket, keyed like Postgres width_bucket:
+// 0 is below the first edge, len(edges) is at or above the last one.
+func (r *BookRepositoryImpl) CountByEdges(field string, edges []float64) (map[int64]int64, error) {
+	if !bucketableFields[field] || len(edges) == 0 {
+		return nil, errors.New("invalid field for bucketing")
+	}
+
+	// Edges are bound one by one; a slice argument would be expanded into a value list
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(edges)), ", ")
+	args := make([]interface{}, 0, len(edges))
+	for _, edge := range edges {
+		args = append(args, edge)
+	}
+
+	return r.countByBucket(fmt.Sprintf("width_bucket(%s::float8, ARRAY[%s]::float8[])", field, placeholders), field, args...)
+}
+
+func (r *BookRepositoryImpl) countByBucket(bucketExpr, field string, args ...interface{}) (map[int64]int64, error) {
+	var results []struct {
+		Bucket int64 `gorm:"column:bucket"`
+		Count  int64 `gorm:"column:count"`
+	}
+
+	// Like the precomputed category and author counts, charts only count published books
+	if err := r.db.Model(&model.Book{}).
+		Select(fmt.Sprintf("%s as bucket, COUNT(*) as count", bucketExpr), args...).
+		Where(fmt.Sprintf("%s IS NOT NULL", field)).
+		Where("status = ?", utils.BookStatusPublished).
+		Group("bucket").
+		Scan(&results).Error; err != nil {
+		return nil, errors.New("failed to execute aggregation query")
+	}
+
+	counts := make(map[int64]int64, len(results))
+	for _, result := range results {
+		counts[result.Bucket] = result.Count
+	}
+
+	return counts, nil
+}
+
+// SQL expressions behind the whitelisted analytics names. Only these strings are ever
+// interpolated into the query; user-supplied values are always bound as parameters.
+var analyticsDimensionExprs = map[string]string{
+	utils.AnalyticsDimensionCategory:              "books.category",
+	utils.AnalyticsDimensionAuthor:                "books.author_name",
+	utils.AnalyticsDimensionPublicationYearBucket: "(books.publication_year / 10) * 10",
+	utils.AnalyticsDimensionRatingBucket:          "FLOOR(books.rating)::int",
+	utils.AnalyticsDimensionCreatedMonth:          "to_char(to_timestamp(books.created_at), 'YYYY-MM')",
+}
+
+var analyticsMetricExprs = map[string]string{
+	utils.AnalyticsMetricCount:       "COUNT(*)",
+	utils.AnalyticsMetricAvgRating:   "COALESCE(AVG(books.rating), 0)::float8",
+	utils.AnalyticsMetricAvgPages:    "COALESCE(AVG(books.pages), 0)::float8",
+	utils.AnalyticsMetricReviewCount: "COALESCE(SUM(review_counts.review_count), 0)::bigint",
+}
+
+var analyticsFilterExprs = map[string]string{
+	"category":         "books.category",
+	"author":           "books.author_name",
+	"publication_year": "books.publication_year",
+	"rating":           "books.rating",
+	"pages":            "books.pages",
+}
+
+var analyticsFilterOps = map[string]string{
+	utils.AnalyticsFilterEq:  "=",
+	utils.AnalyticsFilterNeq: "<>",
+	utils.AnalyticsFilterGt:  ">",
+	utils.AnalyticsFilterGte: ">=",
+	utils.AnalyticsFilterLt:  "<",
+	utils.AnalyticsFilterLte: "<=",
+	utils.AnalyticsFilterIn:  "IN",
+}
+
+// Aggregate runs a validated analytics query over published books, one row per dimension group
+func (r *BookRepositoryImpl) Aggregate(query *dto.AnalyticsQueryRequest) ([]map[string]interface{}, error) {
+	selects := make([]string, 0, len(query.Dimensions)+len(query.Metrics))
+	tx := r.db.Table("books").Where("books.status = ? AND books.deleted_at IS NULL", utils.BookStatusPublished)
+
+	for _, dimension := range query.Dimensions {
+		expr, ok := analyticsDimensionExprs[dimension]
+		if !ok {
+			return nil, fmt.Errorf("unknown analytics dimension %q", dimension)
+		}
+		selects = append(selects, fmt.Sprintf("%s AS %s", expr, dimension))
+		tx = tx.Group(expr).Order(expr)
+	}
+
+	for _, metric := range query.Metrics {
+		expr, ok := analyticsMetricExprs[metric]
+		if !ok {
+			return nil, fmt.Errorf("unknown analytics metric %q", metric)
+		}
+		selects = append(selects, fmt.Sprintf("%s AS %s", expr, metric))
+
+		// Reviews are pre-aggregated per book so that joining them doesn't skew the other metrics
+		if metric == utils.AnalyticsMetricReviewCount {
+			tx = tx.Joins("LEFT JOIN (SELECT book_id, COUNT(*) AS review_count FROM reviews WHERE status = ? AND deleted_at IS NULL GROUP BY book_id) AS review_counts ON review_counts.book_id = books.id", utils.ReviewStatusPublished)
+		}
+	}
+
+	for _, filter := range query.Filters {
+		expr, ok := analyticsFilterExprs[filter.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown analytics filter field %q", filter.Field)
+		}
+		op, ok := analyticsFilterOps[filter.Op]
+		if !ok {
+			return nil, fmt.Errorf("unknown analytics filter operator %q", filter.Op)
+		}
+
+		if _, numeric := filter.Value.(float64); numeric {
+			tx = tx.Where(fmt.Sprintf("%s %s CAST(? AS numeric)", expr, op), filter.Value)
+		} else {
+			tx = tx.Where(fmt.Sprintf("%s %s ?", expr, op), filter.Value)
+		}
+	}
+
+	var rows []map[string]interface{}
+	if err := tx.Select(strings.Join(selects, ", ")).Limit(utils.MaxAnalyticsRows).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// HoldReviews holds new reviews of a book for moderation until the given time. An existing
+// hold is only ever extended.
+func (r *BookRepositoryImpl) HoldReviews(id uuid.UUID, until int64) error {
+	return r.db.Model(&model.Book{}).
+		Where("id = ? AND reviews_held_until < ?", id, until).
+		Update("reviews_held_until", until).Error
+}
+
+// FindDuplicateCandidates returns the fields the duplicate finder compares for every book,
+// oldest first so the original of a pair comes before its copy
+func (r *BookRepositoryImpl) FindDuplicateCandidates() ([]model.Book, error) {
+	var books []model.Book
+	if err := r.db.Order("created_at ASC").Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// Merge folds the source book into the target in one transaction: reviews move over unless
+// the reviewer already reviewed the target, the target takes the given field updates, and
+// the source ID (and any ID already redirected to it) redirects to the target. Field updates
+// are recorded as a revision of the target by actor.
+func (r *BookRepositoryImpl) Merge(targetID, sourceID uuid.UUID, updates map[string]interface{}, actor string) (*model.Book, error) {
+	var book model.Book
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, "id = ?", targetID).Error; err != nil {
+			return err
+		}
+
+		// One review per reviewer and book; the target's own review wins, even from the trash
+		if err := tx.Unscoped().Where("book_id = ? AND email_normalized IN (?)", sourceID,
+			tx.Unscoped().Model(&model.Review{}).Select("email_normalized").Where("book_id = ? AND email_normalized IS NOT NULL", targetID),
+		).Delete(&model.Review{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.Review{}).Where("book_id = ?", sourceID).Update("book_id", targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.BookRedirect{}).Where("to_id = ?", sourceID).Update("to_id", targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "from_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"to_id"}),
+		}).Create(&model.BookRedirect{FromID: sourceID, ToID: targetID}).Error; err != nil {
+			return err
+		}
+
+		// The source goes first so the target can take over its unique ISBN. It is removed for
+		// good rather than trashed, along with any of its reviews already in the trash.
+		if err := tx.Unscoped().Delete(&model.Book{}, "id = ?", sourceID).Error; err != nil {
+			return err
+		}
+
+		return updateWithRevision(tx, &book, updates, actor, fmt.Sprintf("Merged book %s", sourceID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+// FindRedirect returns where a merged book's ID now points, or nil if it was never merged
+func (r *BookRepositoryImpl) FindRedirect(id uuid.UUID) (*model.BookRedirect, error) {
+	var redirect model.BookRedirect
+	if err := r.db.First(&redirect, "from_id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &redirect, nil
+}
+
+// FindRevisions returns a page of a book's revisions, newest first
+func (r *BookRepositoryImpl) FindRevisions(bookID uuid.UUID, offset, limit int) ([]model.BookRevision, dto.PaginationMeta, error) {
+	var revisions []model.BookRevision
+	var totalCount int64
+
+	query := r.db.Model(&model.BookRevision{}).Where("book_id = ?", bookID)
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order("number DESC").Offset(offset).Limit(limit).Find(&revisions).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	meta := dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     offset,
+		Limit:      limit,
+	}
+
+	return revisions, meta, nil
+}
+
+// FindRevisionsSince returns a book's revisions from the given number on, oldest first
+func (r *BookRepositoryImpl) FindRevisionsSince(bookID uuid.UUID, number int) ([]model.BookRevision, error) {
+	var revisions []model.BookRevision
+	if err := r.db.Where("book_id = ? AND number >= ?", bookID, number).
+		Order("number ASC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// Delete moves a book and its reviews to the trash. They share one deletion time so that
+// restoring the book brings back exactly the reviews trashed with it.
+func (r *BookRepositoryImpl) Delete(id uuid.UUID) error {
+	now := time.Now()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Review{}).Where("book_id = ?", id).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Book{}).Where("id = ?", id).UpdateColumn("deleted_at", now).Error
+	})
+}
+
+// Restore takes a book and the reviews trashed with it out of the trash, or returns nil if the
+// book is not in the trash
+func (r *BookRepositoryImpl) Restore(id uuid.UUID) (*model.Book, error) {
+	var book model.Book
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&book).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&model.Review{}).
+			Where("book_id = ? AND deleted_at = ?", id, book.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		book.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&model.Book{}).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+// FindReviewerNames returns the names on a book's reviews, trashed ones included, so the
+// reviewers that trashing, restoring or merging the book affects can be recounted
+func (r *BookRepositoryImpl) FindReviewerNames(bookID uuid.UUID) ([]string, error) {
+	var names []string
+	if err := r.db.Unscoped().Model(&model.Review{}).
+		Where("book_id = ? AND name IS NOT NULL AND name != ''", bookID).
+		Distinct().
+		Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// PublishDue publishes the scheduled books whose time has come, recording a revision for
+// each. Rows locked by another publisher are skipped and picked up on its next run.
+func (r *BookRepositoryImpl) PublishDue(now int64) ([]model.Book, error) {
+	var books []model.Book
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND publish_at <= ?", utils.BookStatusScheduled, now).
+			Find(&books).Error; err != nil {
+			return err
+		}
+
+		for i := range books {
+			updates := map[string]interface{}{"status": utils.BookStatusPublished}
+			if err := updateWithRevision(tx, &books[i], updates, utils.ActorScheduler, "Scheduled publication"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
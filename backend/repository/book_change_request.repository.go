@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/dto"
+	"honya/backend/model"
+	"honya/backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BookChangeRequestRepository interface {
+	FindByID(id uuid.UUID) (*model.BookChangeRequest, error)
+	Create(request *model.BookChangeRequest) (*model.BookChangeRequest, error)
+	FindByStatus(params dto.BookChangeRequestQueryParams) ([]model.BookChangeRequest, dto.PaginationMeta, error)
+	Resolve(id uuid.UUID, status, note, actor string, at int64) (bool, error)
+	Reopen(id uuid.UUID) error
+	FindByEmail(email string) ([]model.BookChangeRequest, error)
+	AnonymizeByEmail(email string) (int64, error)
+}
+
+type BookChangeRequestRepositoryImpl struct {
+	*BaseRepository[model.BookChangeRequest]
+}
+
+func NewBookChangeRequestRepository() BookChangeRequestRepository {
+	return &BookChangeRequestRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.BookChangeRequest](config.DB.Db),
+	}
+}
+
+// FindByStatus returns change requests with the given status, oldest first so the queue is
+// worked in order
+func (r *BookChangeRequestRepositoryImpl) FindByStatus(params dto.BookChangeRequestQueryParams) ([]model.BookChangeRequest, dto.PaginationMeta, error) {
+	var requests []model.BookChangeRequest
+	var totalCount int64
+
+	query := r.db.Model(&model.BookChangeRequest{}).Where("status = ?", params.Status)
+	if params.BookID != nil {
+		query = query.Where("book_id = ?", *params.BookID)
+	}
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order("created_at ASC").Offset(params.Offset).Limit(params.Limit).Find(&requests).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	return requests, dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     params.Offset,
+		Limit:      params.Limit,
+	}, nil
+}
+
+// Resolve moves a pending change request to status. It reports false if the request was
+// no longer pending, so two editors cannot both resolve it.
+func (r *BookChangeRequestRepositoryImpl) Resolve(id uuid.UUID, status, note, actor string, at int64) (bool, error) {
+	result := r.db.Model(&model.BookChangeRequest{}).
+		Where("id = ? AND status = ?", id, utils.ChangeRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"reviewer_note": note,
+			"reviewed_by":   actor,
+			"reviewed_at":   at,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Reopen puts a change request back in the queue after it could not be applied
+func (r *BookChangeRequestRepositoryImpl) Reopen(id uuid.UUID) error {
+	return r.db.Model(&model.BookChangeRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":        utils.ChangeRequestStatusPending,
+			"reviewer_note": "",
+			"reviewed_by":   "",
+			"reviewed_at":   0,
+		}).Error
+}
+
+// FindByEmail returns every change request proposed with the given email
+func (r *BookChangeRequestRepositoryImpl) FindByEmail(email string) ([]model.BookChangeRequest, error) {
+	var results []model.BookChangeRequest
+
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).Order("created_at ASC").Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// AnonymizeByEmail strips the proposer's name and email from their change requests; the
+// proposed changes themselves hold no personal data and are kept
+func (r *BookChangeRequestRepositoryImpl) AnonymizeByEmail(email string) (int64, error) {
+	result := r.db.Model(&model.BookChangeRequest{}).
+		Where("LOWER(email) = LOWER(?)", email).
+		Updates(map[string]interface{}{
+			"name":  utils.DeletedUserName,
+			"email": gorm.Expr("NULL"),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
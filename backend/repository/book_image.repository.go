@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookImageRepository keeps the image galleries of books. Positions within a gallery run
+// from 0 without gaps.
+type BookImageRepository interface {
+	FindByID(id uuid.UUID) (*model.BookImage, error)
+	FindByBook(bookID uuid.UUID) ([]model.BookImage, error)
+	Create(image *model.BookImage) (*model.BookImage, error)
+	Update(id uuid.UUID, updates map[string]interface{}) (*model.BookImage, error)
+	Reorder(bookID uuid.UUID, ids []uuid.UUID) error
+	Delete(image *model.BookImage) error
+}
+
+type BookImageRepositoryImpl struct {
+	*BaseRepository[model.BookImage]
+}
+
+func NewBookImageRepository() BookImageRepository {
+	return &BookImageRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.BookImage](config.DB.Db),
+	}
+}
+
+// FindByBook returns a book's gallery in order
+func (r *BookImageRepositoryImpl) FindByBook(bookID uuid.UUID) ([]model.BookImage, error) {
+	var images []model.BookImage
+	if err := r.db.Where("book_id = ?", bookID).Order("position ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// Create inserts an image at its position, moving the images from there on one place back
+func (r *BookImageRepositoryImpl) Create(image *model.BookImage) (*model.BookImage, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.BookImage{}).
+			Where("book_id = ? AND position >= ?", image.BookID, image.Position).
+			UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Create(image).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// Reorder gives each image the position of its ID in ids
+func (r *BookImageRepositoryImpl) Reorder(bookID uuid.UUID, ids []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			if err := tx.Model(&model.BookImage{}).
+				Where("id = ? AND book_id = ?", id, bookID).
+				UpdateColumn("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes an image and closes the gap it leaves in the gallery
+func (r *BookImageRepositoryImpl) Delete(image *model.BookImage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.BookImage{}, "id = ?", image.ID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.BookImage{}).
+			Where("book_id = ? AND position > ?", image.BookID, image.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error
+	})
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// ChallengeRepository records redeemed proof-of-work challenges
+type ChallengeRepository interface {
+	Redeem(nonce string, expiresAt int64) (bool, error)
+	CountRedeemedSince(since int64) (int64, error)
+	DeleteExpired(before int64) error
+}
+
+type ChallengeRepositoryImpl struct {
+	*BaseRepository[model.SpentChallenge]
+}
+
+func NewChallengeRepository() ChallengeRepository {
+	return &ChallengeRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.SpentChallenge](config.DB.Db),
+	}
+}
+
+// Redeem marks a challenge as spent. It reports false when the challenge was already redeemed,
+// which the primary key decides even when two instances race on it.
+func (r *ChallengeRepositoryImpl) Redeem(nonce string, expiresAt int64) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.SpentChallenge{Nonce: nonce, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *ChallengeRepositoryImpl) CountRedeemedSince(since int64) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.SpentChallenge{}).Where("created_at >= ?", since).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *ChallengeRepositoryImpl) DeleteExpired(before int64) error {
+	return r.db.Where("expires_at < ?", before).Delete(&model.SpentChallenge{}).Error
+}
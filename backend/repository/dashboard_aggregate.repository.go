@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"honya/backend/config"
+	"honya/backend/model"
+	"honya/backend/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DashboardAggregateRepository maintains the precomputed dashboard counts
+type DashboardAggregateRepository interface {
+	FindByDimension(dimension string, limit int) ([]model.DashboardAggregate, error)
+	FindState(dimension string) (*model.DashboardAggregateState, error)
+	RefreshKeys(dimension string, keys []string) error
+	RefreshDimension(dimension string) error
+}
+
+type DashboardAggregateRepositoryImpl struct {
+	*BaseRepository[model.DashboardAggregate]
+}
+
+func NewDashboardAggregateRepository() DashboardAggregateRepository {
+	return &DashboardAggregateRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.DashboardAggregate](config.DB.Db),
+	}
+}
+
+// Where each dimension's counts come from; keys are always compared as text. These queries
+// bypass GORM's model scopes, so each condition excludes trashed rows itself. Drafts,
+// scheduled and archived books are not counted until they are published.
+type aggregateSource struct {
+	table   string
+	keyExpr string
+	where   string
+}
+
+var aggregateSources = map[string]aggregateSource{
+	utils.AggregateBooksByCategory: {
+		table:   "books",
+		keyExpr: fmt.Sprintf("COALESCE(NULLIF(category, ''), '%s')", utils.AggregateUnknownKey),
+		where:   fmt.Sprintf("status = '%s' AND deleted_at IS NULL", utils.BookStatusPublished),
+	},
+	utils.AggregateBooksByAuthor: {
+		table:   "books",
+		keyExpr: fmt.Sprintf("COALESCE(NULLIF(author_name, ''), '%s')", utils.AggregateUnknownKey),
+		where:   fmt.Sprintf("status = '%s' AND deleted_at IS NULL", utils.BookStatusPublished),
+	},
+	utils.AggregateReviewers: {
+		table:   "reviews",
+		keyExpr: "name",
+		where:   fmt.Sprintf("name IS NOT NULL AND name != '' AND anonymized = FALSE AND status = '%s' AND deleted_at IS NULL", utils.ReviewStatusPublished),
+	},
+}
+
+func (r *DashboardAggregateRepositoryImpl) FindByDimension(dimension string, limit int) ([]model.DashboardAggregate, error) {
+	var results []model.DashboardAggregate
+
+	query := r.db.Where("dimension = ?", dimension).Order("count DESC, key ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (r *DashboardAggregateRepositoryImpl) FindState(dimension string) (*model.DashboardAggregateState, error) {
+	var state model.DashboardAggregateState
+	if err := r.db.First(&state, "dimension = ?", dimension).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// RefreshKeys recounts only the given keys of a dimension, which keeps writes cheap:
+// a book update touches at most two categories, ratings and authors.
+func (r *DashboardAggregateRepositoryImpl) RefreshKeys(dimension string, keys []string) error {
+	source, ok := aggregateSources[dimension]
+	if !ok {
+		return fmt.Errorf("unknown aggregate dimension %q", dimension)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, key := range keys {
+			var count int64
+			if err := tx.Table(source.table).
+				Where(source.where).
+				Where(fmt.Sprintf("%s = ?", source.keyExpr), key).
+				Count(&count).Error; err != nil {
+				return err
+			}
+
+			if count == 0 {
+				if err := tx.Where("dimension = ? AND key = ?", dimension, key).Delete(&model.DashboardAggregate{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "dimension"}, {Name: "key"}},
+				DoUpdates: clause.AssignmentColumns([]string{"count", "updated_at"}),
+			}).Create(&model.DashboardAggregate{Dimension: dimension, Key: key, Count: count}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RefreshDimension rebuilds every count of a dimension from scratch and records when it happened
+func (r *DashboardAggregateRepositoryImpl) RefreshDimension(dimension string) error {
+	source, ok := aggregateSources[dimension]
+	if !ok {
+		return fmt.Errorf("unknown aggregate dimension %q", dimension)
+	}
+
+	now := time.Now().Unix()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("dimension = ?", dimension).Delete(&model.DashboardAggregate{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(fmt.Sprintf(
+			"INSERT INTO dashboard_aggregates (dimension, key, count, updated_at) SELECT ?, %s, COUNT(*), ? FROM %s WHERE %s GROUP BY 2",
+			source.keyExpr, source.table, source.where,
+		), dimension, now).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "dimension"}},
+			DoUpdates: clause.AssignmentColumns([]string{"refreshed_at"}),
+		}).Create(&model.DashboardAggregateState{Dimension: dimension, RefreshedAt: now}).Error
+	})
+}
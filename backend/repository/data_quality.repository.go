@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"errors"
+	"honya/backend/config"
+	"honya/backend/model"
+	"honya/backend/utils"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataQualityRepository runs the catalog checks behind the data-quality report
+type DataQualityRepository interface {
+	CountBooks() (int64, error)
+	FindBooksFailing(check string, sampleSize int) (int64, []uuid.UUID, error)
+	FindIsbns() ([]model.Book, error)
+	FindImages() ([]model.Book, error)
+	FindDuplicateGroups() ([][]uuid.UUID, error)
+	FindOrphanReviews(sampleSize int) (int64, []uuid.UUID, error)
+}
+
+type DataQualityRepositoryImpl struct {
+	*BaseRepository[model.Book]
+}
+
+func NewDataQualityRepository() DataQualityRepository {
+	return &DataQualityRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.Book](config.DB.Db),
+	}
+}
+
+// Checks that are a plain condition on the books table
+var bookQualityConditions = map[string]func(query *gorm.DB) *gorm.DB{
+	utils.DataQualityMissingCover: func(query *gorm.DB) *gorm.DB {
+		return query.Where("image IS NULL OR TRIM(image) = ''")
+	},
+	utils.DataQualityMissingDescription: func(query *gorm.DB) *gorm.DB {
+		return query.Where("description IS NULL OR TRIM(description) = ''")
+	},
+	utils.DataQualityMissingISBN: func(query *gorm.DB) *gorm.DB {
+		return query.Where("isbn IS NULL OR TRIM(isbn) = ''")
+	},
+	utils.DataQualitySuspiciousPages: func(query *gorm.DB) *gorm.DB {
+		return query.Where("pages IS NULL OR pages < ? OR pages > ?", utils.DataQualityMinPages, utils.DataQualityMaxPages)
+	},
+}
+
+func (r *DataQualityRepositoryImpl) CountBooks() (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Book{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindBooksFailing counts the books failing a check and returns the IDs of the oldest ones
+func (r *DataQualityRepositoryImpl) FindBooksFailing(check string, sampleSize int) (int64, []uuid.UUID, error) {
+	condition, ok := bookQualityConditions[check]
+	if !ok {
+		return 0, nil, errors.New("invalid data quality check")
+	}
+
+	var count int64
+	if err := condition(r.db.Model(&model.Book{})).Count(&count).Error; err != nil {
+		return 0, nil, err
+	}
+
+	ids := []uuid.UUID{}
+	if err := condition(r.db.Model(&model.Book{})).
+		Order("created_at ASC").
+		Limit(sampleSize).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return count, ids, nil
+}
+
+// FindIsbns returns the ID and ISBN of every book that has one
+func (r *DataQualityRepositoryImpl) FindIsbns() ([]model.Book, error) {
+	var books []model.Book
+	if err := r.db.Select("id", "isbn").
+		Where("isbn IS NOT NULL AND TRIM(isbn) <> ''").
+		Order("created_at ASC").
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// FindImages returns the ID and image URL of every book that has a cover
+func (r *DataQualityRepositoryImpl) FindImages() ([]model.Book, error) {
+	var books []model.Book
+	if err := r.db.Select("id", "image").
+		Where("image IS NOT NULL AND TRIM(image) <> ''").
+		Order("created_at ASC").
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// FindDuplicateGroups returns groups of books sharing a title and author, ignoring case and
+// surrounding spaces, largest group first
+func (r *DataQualityRepositoryImpl) FindDuplicateGroups() ([][]uuid.UUID, error) {
+	var results []struct {
+		IDs string `gorm:"column:ids"`
+	}
+
+	if err := r.db.Model(&model.Book{}).
+		Select("string_agg(id::text, ',' ORDER BY created_at) AS ids").
+		Group("LOWER(TRIM(title)), LOWER(TRIM(author_name))").
+		Having("COUNT(*) > 1").
+		Order("COUNT(*) DESC").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	groups := make([][]uuid.UUID, 0, len(results))
+	for _, result := range results {
+		group := []uuid.UUID{}
+		for _, id := range strings.Split(result.IDs, ",") {
+			parsed, err := uuid.Parse(id)
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, parsed)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// FindOrphanReviews counts reviews whose book no longer exists, which the foreign key
+// prevents today but older data may still contain
+func (r *DataQualityRepositoryImpl) FindOrphanReviews(sampleSize int) (int64, []uuid.UUID, error) {
+	orphans := func() *gorm.DB {
+		return r.db.Model(&model.Review{}).
+			Joins("LEFT JOIN books ON books.id = reviews.book_id").
+			Where("books.id IS NULL")
+	}
+
+	var count int64
+	if err := orphans().Count(&count).Error; err != nil {
+		return 0, nil, err
+	}
+
+	ids := []uuid.UUID{}
+	if err := orphans().Order("reviews.created_at ASC").Limit(sampleSize).Pluck("reviews.id", &ids).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return count, ids, nil
+}
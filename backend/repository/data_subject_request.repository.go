@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/dto"
+	"honya/backend/model"
+)
+
+// DataSubjectRequestRepository stores the audit trail of GDPR requests
+type DataSubjectRequestRepository interface {
+	Create(request *model.DataSubjectRequest) (*model.DataSubjectRequest, error)
+	FindAll(params dto.QueryParams) ([]model.DataSubjectRequest, dto.PaginationMeta, error)
+}
+
+type DataSubjectRequestRepositoryImpl struct {
+	*BaseRepository[model.DataSubjectRequest]
+}
+
+func NewDataSubjectRequestRepository() DataSubjectRequestRepository {
+	return &DataSubjectRequestRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.DataSubjectRequest](config.DB.Db),
+	}
+}
+
+func (r *DataSubjectRequestRepositoryImpl) FindAll(params dto.QueryParams) ([]model.DataSubjectRequest, dto.PaginationMeta, error) {
+	var results []model.DataSubjectRequest
+	var totalCount int64
+
+	query := r.db.Model(&model.DataSubjectRequest{})
+
+	if params.Query != "" {
+		query = query.Where("type = ? OR email_hmac = ?", params.Query, params.Query)
+	}
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order("created_at DESC").Offset(params.Offset).Limit(params.Limit).Find(&results).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	meta := dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     params.Offset,
+		Limit:      params.Limit,
+	}
+
+	return results, meta, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"encoding/json"
+	"honya/backend/config"
+	"honya/backend/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// ImageGCRepository finds the covers rows still refer to and keeps the queue of blobs whose
+// deletion failed
+type ImageGCRepository interface {
+	FindReferencedImages(revisionsSince int64) ([]string, error)
+	FindDeletions() ([]model.BlobDeletion, error)
+	SaveDeletion(deletion *model.BlobDeletion) error
+	RemoveDeletion(key string) error
+}
+
+type ImageGCRepositoryImpl struct {
+	*BaseRepository[model.BlobDeletion]
+}
+
+func NewImageGCRepository() ImageGCRepository {
+	return &ImageGCRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.BlobDeletion](config.DB.Db),
+	}
+}
+
+// FindReferencedImages returns every cover and cover variant URL that a book, trashed books
+// included, a gallery image or a book revision recorded since revisionsSince refers to.
+// Revisions count because restoring one brings its cover back; older ones are not kept
+// around forever for that, or a cover that was ever replaced would never be collected.
+func (r *ImageGCRepositoryImpl) FindReferencedImages(revisionsSince int64) ([]string, error) {
+	var books []model.Book
+	if err := r.db.Unscoped().Select("image", "images").Where("image != ''").Find(&books).Error; err != nil {
+		return nil, err
+	}
+
+	var revisions []model.BookRevision
+	if err := r.db.Select("before", "after").
+		Where("created_at >= ?", revisionsSince).
+		Where("before ->> 'image' != '' OR after ->> 'image' != ''").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+
+	var gallery []model.BookImage
+	if err := r.db.Select("url", "metadata").Find(&gallery).Error; err != nil {
+		return nil, err
+	}
+
+	images, err := revisionImages(revisions)
+	if err != nil {
+		return nil, err
+	}
+	for _, book := range books {
+		images = append(images, book.Image)
+		images = append(images, book.Images.URLs()...)
+	}
+	for _, image := range gallery {
+		images = append(images, image.URL)
+		images = append(images, image.Metadata.URLs()...)
+	}
+	return images, nil
+}
+
+// revisionImages collects the covers and cover variants recorded on revisions, without duplicates
+func revisionImages(revisions []model.BookRevision) ([]string, error) {
+	seen := map[string]bool{}
+	images := []string{}
+	add := func(image string) {
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	for _, revision := range revisions {
+		for _, values := range []map[string]interface{}{revision.Before, revision.After} {
+			if image, ok := values["image"].(string); ok {
+				add(image)
+			}
+			if variants, ok := values["images"]; ok && variants != nil {
+				payload, err := json.Marshal(variants)
+				if err != nil {
+					return nil, err
+				}
+				var decoded model.BookImages
+				if err := json.Unmarshal(payload, &decoded); err != nil {
+					return nil, err
+				}
+				for _, url := range decoded.URLs() {
+					add(url)
+				}
+			}
+		}
+	}
+
+	return images, nil
+}
+
+// FindDeletions returns the queued deletions, the longest waiting first
+func (r *ImageGCRepositoryImpl) FindDeletions() ([]model.BlobDeletion, error) {
+	var deletions []model.BlobDeletion
+	if err := r.db.Order("next_attempt_at ASC").Find(&deletions).Error; err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+// SaveDeletion queues a deletion, or records another failed attempt of a queued one
+func (r *ImageGCRepositoryImpl) SaveDeletion(deletion *model.BlobDeletion) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"attempts", "last_error", "next_attempt_at", "updated_at"}),
+	}).Create(deletion).Error
+}
+
+func (r *ImageGCRepositoryImpl) RemoveDeletion(key string) error {
+	return r.db.Delete(&model.BlobDeletion{}, "key = ?", key).Error
+}
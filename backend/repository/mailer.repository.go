@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"honya/backend/config"
+	"honya/backend/dto"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// MailerRepository sends emails. The SMTP implementation is used when SMTP_HOST is set;
+// otherwise messages are only logged, which keeps local development free of mail setup.
+type MailerRepository interface {
+	Send(message *dto.EmailMessage) error
+}
+
+type smtpMailerRepository struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+type logMailerRepository struct{}
+
+func NewMailerRepository() MailerRepository {
+	env, err := config.GetEnvConfig()
+	if err != nil || env.SmtpHost == "" {
+		return &logMailerRepository{}
+	}
+
+	var auth smtp.Auth
+	if env.SmtpUsername != "" {
+		auth = smtp.PlainAuth("", env.SmtpUsername, env.SmtpPassword, env.SmtpHost)
+	}
+
+	return &smtpMailerRepository{
+		addr: env.SmtpHost + ":" + env.SmtpPort,
+		auth: auth,
+		from: env.MailFrom,
+	}
+}
+
+func (m *logMailerRepository) Send(message *dto.EmailMessage) error {
+	log.Printf("Mailer: would send %q to %s with %d attachment(s)", message.Subject, strings.Join(message.To, ", "), len(message.Attachments))
+	return nil
+}
+
+// Send delivers the message. Addresses may carry a display name, as in "Ops <ops@example.com>";
+// the SMTP envelope only takes the bare address, so names are kept for the headers alone.
+func (m *smtpMailerRepository) Send(message *dto.EmailMessage) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", m.from, err)
+	}
+	to := make([]*mail.Address, 0, len(message.To))
+	recipients := make([]string, 0, len(message.To))
+	for _, recipient := range message.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		to = append(to, address)
+		recipients = append(recipients, address.Address)
+	}
+
+	body, err := buildMimeMessage(from, to, message)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, from.Address, recipients, body)
+}
+
+// buildMimeMessage renders a multipart/mixed message with a text/html alternative part and attachments
+func buildMimeMessage(from *mail.Address, to []*mail.Address, message *dto.EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	mixed := multipart.NewWriter(&buf)
+
+	// String encodes display names, so non-ASCII names cannot break the headers
+	recipients := make([]string, 0, len(to))
+	for _, address := range to {
+		recipients = append(recipients, address.String())
+	}
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	var alternativeBody bytes.Buffer
+	alternative := multipart.NewWriter(&alternativeBody)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		writer, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(writer, []byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+
+	writer, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(alternativeBody.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range message.Attachments {
+		writer, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(writer, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBase64 encodes data in 76-character lines as required by RFC 2045
+func writeBase64(writer io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := writer.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := writer.Write([]byte(encoded + "\r\n"))
+	return err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/dto"
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+)
+
+// ReportRunRepository stores the delivery history of scheduled reports
+type ReportRunRepository interface {
+	Create(run *model.ReportRun) (*model.ReportRun, error)
+	FindByReportID(reportID uuid.UUID, params dto.QueryParams) ([]model.ReportRun, dto.PaginationMeta, error)
+}
+
+type ReportRunRepositoryImpl struct {
+	*BaseRepository[model.ReportRun]
+}
+
+func NewReportRunRepository() ReportRunRepository {
+	return &ReportRunRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.ReportRun](config.DB.Db),
+	}
+}
+
+func (r *ReportRunRepositoryImpl) FindByReportID(reportID uuid.UUID, params dto.QueryParams) ([]model.ReportRun, dto.PaginationMeta, error) {
+	var results []model.ReportRun
+	var totalCount int64
+
+	query := r.db.Model(&model.ReportRun{}).Where("report_id = ?", reportID)
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order("started_at DESC").Offset(params.Offset).Limit(params.Limit).Find(&results).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	meta := dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     params.Offset,
+		Limit:      params.Limit,
+	}
+
+	return results, meta, nil
+}
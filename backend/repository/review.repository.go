@@ -2,11 +2,14 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"honya/backend/config"
 	"honya/backend/dto"
 	"honya/backend/model"
+	"honya/backend/utils"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // ReviewRepository defines methods for interacting with the reviews in the database.
@@ -18,6 +21,15 @@ type ReviewRepository interface {
 	Delete(id uuid.UUID) error
 	FindByBookID(bookID uuid.UUID, params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error)
 	GetTopReviewers(limit int) ([]dto.ReviewerStats, error)
+	FindByEmail(email string) ([]model.Review, error)
+	AnonymizeByEmail(email string, keepContent bool) (int64, error)
+	ExistsByBookAndEmail(bookID uuid.UUID, normalizedEmail string) (bool, error)
+	CountByEmailSince(normalizedEmail string, since int64) (int64, error)
+	FindModerationQueue(params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error)
+	CountByInterval(interval, timezone string, from, to int64) (map[string]int64, error)
+	CountActiveBooksSince(since int64, minCount int64) (map[uuid.UUID]int64, error)
+	CountHourlyByBook(bookIDs []uuid.UUID, from, to int64) ([]dto.BookHourlyCount, error)
+	CountBySourceSince(source string, bookIDs []uuid.UUID, since int64) ([]dto.ReviewSourceCount, error)
 }
 
 type ReviewRepositoryImpl struct {
@@ -30,11 +42,31 @@ func NewReviewRepository() ReviewRepository {
 	}
 }
 
+func (r *ReviewRepositoryImpl) FindAll(params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error) {
+	return r.findReviews(r.db.Model(&model.Review{}), params)
+}
+
 func (r *ReviewRepositoryImpl) FindByBookID(bookID uuid.UUID, params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error) {
+	return r.findReviews(r.db.Model(&model.Review{}).Where("book_id = ?", bookID), params)
+}
+
+// FindModerationQueue returns hidden or reported reviews, most reported first
+func (r *ReviewRepositoryImpl) FindModerationQueue(params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error) {
+	query := r.db.Model(&model.Review{}).
+		Where("status <> ? OR report_count > 0", utils.ReviewStatusPublished).
+		Order("report_count DESC, updated_at DESC")
+
+	params.IncludeHidden = true
+	return r.findReviews(query, params)
+}
+
+func (r *ReviewRepositoryImpl) findReviews(query *gorm.DB, params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error) {
 	var results []model.Review
 	var totalCount int64
 
-	query := r.db.Model(&model.Review{}).Where("book_id = ?", bookID)
+	if !params.IncludeHidden {
+		query = query.Where("status = ?", utils.ReviewStatusPublished)
+	}
 
 	if params.Query != "" {
 		query = query.Where("content ILIKE ? OR name ILIKE ?", "%"+params.Query+"%", "%"+params.Query+"%")
@@ -76,7 +108,7 @@ func (r *ReviewRepositoryImpl) GetTopReviewers(limit int) ([]dto.ReviewerStats,
 
 	query := r.db.Model(&model.Review{}).
 		Select("name, COUNT(*) as count").
-		Where("name IS NOT NULL AND name != ''").
+		Where("name IS NOT NULL AND name != '' AND anonymized = ? AND status = ?", false, utils.ReviewStatusPublished).
 		Group("name").
 		Order("count DESC").
 		Limit(limit)
@@ -102,3 +134,138 @@ func (r *ReviewRepositoryImpl) GetTopReviewers(limit int) ([]dto.ReviewerStats,
 
 	return reviewerStats, nil
 }
+
+// FindByEmail returns every review written with the given email, including trashed ones,
+// since they still hold the reviewer's personal data
+func (r *ReviewRepositoryImpl) FindByEmail(email string) ([]model.Review, error) {
+	var results []model.Review
+
+	if err := r.db.Unscoped().Where("LOWER(email) = LOWER(?)", email).Order("created_at ASC").Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// AnonymizeByEmail strips personal data from every review written with the given email,
+// trashed ones included
+func (r *ReviewRepositoryImpl) AnonymizeByEmail(email string, keepContent bool) (int64, error) {
+	updates := map[string]interface{}{
+		"name":             utils.DeletedUserName,
+		"email":            gorm.Expr("NULL"),
+		"email_normalized": gorm.Expr("NULL"),
+		"ip_range":         gorm.Expr("NULL"),
+		"anonymized":       true,
+	}
+	if !keepContent {
+		updates["content"] = utils.RemovedReviewContent
+		updates["content_html"] = utils.RenderMarkdown(utils.RemovedReviewContent)
+	}
+
+	result := r.db.Unscoped().Model(&model.Review{}).Where("LOWER(email) = LOWER(?)", email).Updates(updates)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// ExistsByBookAndEmail also sees trashed reviews, which keep their unique key until purged
+func (r *ReviewRepositoryImpl) ExistsByBookAndEmail(bookID uuid.UUID, normalizedEmail string) (bool, error) {
+	var count int64
+
+	if err := r.db.Unscoped().Model(&model.Review{}).
+		Where("book_id = ? AND email_normalized = ?", bookID, normalizedEmail).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *ReviewRepositoryImpl) CountByEmailSince(normalizedEmail string, since int64) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&model.Review{}).
+		Where("email_normalized = ? AND created_at >= ?", normalizedEmail, since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountActiveBooksSince returns the books with at least minCount reviews since the given time,
+// whatever their status, with their review count
+func (r *ReviewRepositoryImpl) CountActiveBooksSince(since int64, minCount int64) (map[uuid.UUID]int64, error) {
+	var results []struct {
+		BookID uuid.UUID `gorm:"column:book_id"`
+		Count  int64     `gorm:"column:count"`
+	}
+
+	if err := r.db.Model(&model.Review{}).
+		Select("book_id, COUNT(*) as count").
+		Where("created_at >= ?", since).
+		Group("book_id").
+		Having("COUNT(*) >= ?", minCount).
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int64, len(results))
+	for _, result := range results {
+		counts[result.BookID] = result.Count
+	}
+
+	return counts, nil
+}
+
+// CountHourlyByBook counts the reviews of each book per hour in [from, to). Hours are
+// numbered from `from` rather than the clock so any range can be compared to the last hour.
+func (r *ReviewRepositoryImpl) CountHourlyByBook(bookIDs []uuid.UUID, from, to int64) ([]dto.BookHourlyCount, error) {
+	var results []dto.BookHourlyCount
+
+	if len(bookIDs) == 0 {
+		return results, nil
+	}
+
+	if err := r.db.Model(&model.Review{}).
+		Select("book_id, (created_at - ?) / 3600 as hour, COUNT(*) as count", from).
+		Where("book_id IN ? AND created_at >= ? AND created_at < ?", bookIDs, from, to).
+		Group("book_id, hour").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+var reviewSourceExpressions = map[string]string{
+	utils.AlertTypeDomainBurst:  "split_part(email_normalized, '@', 2)",
+	utils.AlertTypeIPRangeBurst: "ip_range",
+}
+
+// CountBySourceSince counts the reviews of each book per email domain or IP range,
+// depending on the alert type given as source
+func (r *ReviewRepositoryImpl) CountBySourceSince(source string, bookIDs []uuid.UUID, since int64) ([]dto.ReviewSourceCount, error) {
+	var results []dto.ReviewSourceCount
+
+	expression, ok := reviewSourceExpressions[source]
+	if !ok {
+		return nil, errors.New("invalid review source")
+	}
+	if len(bookIDs) == 0 {
+		return results, nil
+	}
+
+	if err := r.db.Model(&model.Review{}).
+		Select(fmt.Sprintf("book_id, %s as source, COUNT(*) as count", expression)).
+		Where("book_id IN ? AND created_at >= ?", bookIDs, since).
+		Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", expression, expression)).
+		Group("book_id, source").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
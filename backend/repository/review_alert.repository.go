@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/dto"
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// ReviewAlertRepository stores detected review anomalies
+type ReviewAlertRepository interface {
+	FindAll(params dto.ReviewAlertQueryParams) ([]model.ReviewAlert, dto.PaginationMeta, error)
+	CreateIfAbsent(alert *model.ReviewAlert) (bool, error)
+}
+
+type ReviewAlertRepositoryImpl struct {
+	*BaseRepository[model.ReviewAlert]
+}
+
+func NewReviewAlertRepository() ReviewAlertRepository {
+	return &ReviewAlertRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.ReviewAlert](config.DB.Db),
+	}
+}
+
+func (r *ReviewAlertRepositoryImpl) FindAll(params dto.ReviewAlertQueryParams) ([]model.ReviewAlert, dto.PaginationMeta, error) {
+	var results []model.ReviewAlert
+	var totalCount int64
+
+	query := r.db.Model(&model.ReviewAlert{})
+
+	if params.BookID != uuid.Nil {
+		query = query.Where("book_id = ?", params.BookID)
+	}
+	if params.Type != "" {
+		query = query.Where("type = ?", params.Type)
+	}
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order("created_at DESC").Offset(params.Offset).Limit(params.Limit).Find(&results).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	meta := dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     params.Offset,
+		Limit:      params.Limit,
+	}
+
+	return results, meta, nil
+}
+
+// CreateIfAbsent stores an alert unless the same book, type and subject was already flagged
+// in the same window, so repeated analyzer runs do not duplicate alerts
+func (r *ReviewAlertRepositoryImpl) CreateIfAbsent(alert *model.ReviewAlert) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
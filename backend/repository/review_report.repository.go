@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+)
+
+// ReviewReportRepository defines methods for reader reports on reviews
+type ReviewReportRepository interface {
+	Create(report *model.ReviewReport) (*model.ReviewReport, error)
+	ExistsForReporter(reviewID uuid.UUID, reporterHash string) (bool, error)
+	CountByReviewID(reviewID uuid.UUID) (int64, error)
+	CountReasonsByReviewIDs(reviewIDs []uuid.UUID) (map[uuid.UUID]map[string]int64, error)
+	ResolveByReviewID(reviewID uuid.UUID, resolvedAt int64) error
+}
+
+type ReviewReportRepositoryImpl struct {
+	*BaseRepository[model.ReviewReport]
+}
+
+func NewReviewReportRepository() ReviewReportRepository {
+	return &ReviewReportRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.ReviewReport](config.DB.Db),
+	}
+}
+
+func (r *ReviewReportRepositoryImpl) ExistsForReporter(reviewID uuid.UUID, reporterHash string) (bool, error) {
+	var count int64
+
+	if err := r.db.Model(&model.ReviewReport{}).
+		Where("review_id = ? AND reporter_hash = ?", reviewID, reporterHash).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CountByReviewID counts the open reports of a review
+func (r *ReviewReportRepositoryImpl) CountByReviewID(reviewID uuid.UUID) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&model.ReviewReport{}).Where("review_id = ? AND resolved_at = 0", reviewID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountReasonsByReviewIDs counts the open reports of each review per reason
+func (r *ReviewReportRepositoryImpl) CountReasonsByReviewIDs(reviewIDs []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	var results []struct {
+		ReviewID uuid.UUID `gorm:"column:review_id"`
+		Reason   string    `gorm:"column:reason"`
+		Count    int64     `gorm:"column:count"`
+	}
+
+	counts := make(map[uuid.UUID]map[string]int64)
+	if len(reviewIDs) == 0 {
+		return counts, nil
+	}
+
+	if err := r.db.Model(&model.ReviewReport{}).
+		Select("review_id, reason, COUNT(*) as count").
+		Where("review_id IN ? AND resolved_at = 0", reviewIDs).
+		Group("review_id, reason").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if counts[result.ReviewID] == nil {
+			counts[result.ReviewID] = make(map[string]int64)
+		}
+		counts[result.ReviewID][result.Reason] = result.Count
+	}
+
+	return counts, nil
+}
+
+// ResolveByReviewID marks the open reports of a review as handled, keeping them on record
+func (r *ReviewReportRepositoryImpl) ResolveByReviewID(reviewID uuid.UUID, resolvedAt int64) error {
+	return r.db.Model(&model.ReviewReport{}).
+		Where("review_id = ? AND resolved_at = 0", reviewID).
+		Update("resolved_at", resolvedAt).Error
+}
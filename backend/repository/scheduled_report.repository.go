@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/dto"
+	"honya/backend/model"
+
+	"github.com/google/uuid"
+)
+
+// ScheduledReportRepository stores scheduled email reports
+type ScheduledReportRepository interface {
+	FindAll(params dto.QueryParams) ([]model.ScheduledReport, dto.PaginationMeta, error)
+	FindByID(id uuid.UUID) (*model.ScheduledReport, error)
+	Create(report *model.ScheduledReport) (*model.ScheduledReport, error)
+	Update(id uuid.UUID, updates map[string]interface{}) (*model.ScheduledReport, error)
+	Delete(id uuid.UUID) error
+	FindDue(now int64) ([]model.ScheduledReport, error)
+	ClaimRun(id uuid.UUID, expectedNextRunAt, nextRunAt int64) (bool, error)
+}
+
+type ScheduledReportRepositoryImpl struct {
+	*BaseRepository[model.ScheduledReport]
+}
+
+func NewScheduledReportRepository() ScheduledReportRepository {
+	return &ScheduledReportRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.ScheduledReport](config.DB.Db),
+	}
+}
+
+func (r *ScheduledReportRepositoryImpl) FindAll(params dto.QueryParams) ([]model.ScheduledReport, dto.PaginationMeta, error) {
+	var results []model.ScheduledReport
+	var totalCount int64
+
+	query := r.db.Model(&model.ScheduledReport{})
+
+	if params.Query != "" {
+		query = query.Where("name ILIKE ?", "%"+params.Query+"%")
+	}
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order("created_at DESC").Offset(params.Offset).Limit(params.Limit).Find(&results).Error; err != nil {
+		return nil, dto.PaginationMeta{}, err
+	}
+
+	meta := dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     params.Offset,
+		Limit:      params.Limit,
+	}
+
+	return results, meta, nil
+}
+
+// FindDue returns enabled reports whose next run is at or before now
+func (r *ScheduledReportRepositoryImpl) FindDue(now int64) ([]model.ScheduledReport, error) {
+	var results []model.ScheduledReport
+
+	if err := r.db.Where("enabled = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ClaimRun moves a due report to its next run time. It only succeeds for the first caller,
+// so a report is sent once even when several instances run the scheduler.
+func (r *ScheduledReportRepositoryImpl) ClaimRun(id uuid.UUID, expectedNextRunAt, nextRunAt int64) (bool, error) {
+	result := r.db.Model(&model.ScheduledReport{}).
+		Where("id = ? AND next_run_at = ?", id, expectedNextRunAt).
+		Update("next_run_at", nextRunAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"honya/backend/config"
+	"honya/backend/dto"
+	"honya/backend/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TrashRepository lists soft-deleted books and reviews and purges them for good
+type TrashRepository interface {
+	FindBooks(offset, limit int) ([]model.Book, dto.PaginationMeta, error)
+	FindReviews(offset, limit int) ([]model.Review, dto.PaginationMeta, error)
+	FindExpiredBooks(before time.Time) ([]model.Book, error)
+	FindBookImages(id uuid.UUID) ([]string, error)
+	PurgeBook(id uuid.UUID) error
+	PurgeReviews(before time.Time) (int64, error)
+}
+
+type TrashRepositoryImpl struct {
+	*BaseRepository[model.Book]
+}
+
+func NewTrashRepository() TrashRepository {
+	return &TrashRepositoryImpl{
+		BaseRepository: NewBaseRepository[model.Book](config.DB.Db),
+	}
+}
+
+func (r *TrashRepositoryImpl) FindBooks(offset, limit int) ([]model.Book, dto.PaginationMeta, error) {
+	var books []model.Book
+	meta, err := r.findTrashed(r.db.Unscoped().Model(&model.Book{}), "books", &books, offset, limit)
+	return books, meta, err
+}
+
+// FindReviews returns reviews that were trashed on their own; reviews trashed along with
+// their book are listed under the book
+func (r *TrashRepositoryImpl) FindReviews(offset, limit int) ([]model.Review, dto.PaginationMeta, error) {
+	var reviews []model.Review
+	query := r.db.Unscoped().Model(&model.Review{}).
+		Joins("JOIN books ON books.id = reviews.book_id").
+		Where("books.deleted_at IS NULL")
+	meta, err := r.findTrashed(query, "reviews", &reviews, offset, limit)
+	return reviews, meta, err
+}
+
+// findTrashed pages through the trashed rows of query, most recently deleted first
+func (r *TrashRepositoryImpl) findTrashed(query *gorm.DB, table string, dest interface{}, offset, limit int) (dto.PaginationMeta, error) {
+	var totalCount int64
+
+	query = query.Where(table + ".deleted_at IS NOT NULL")
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return dto.PaginationMeta{}, err
+	}
+
+	if err := query.Order(table + ".deleted_at DESC").Offset(offset).Limit(limit).Find(dest).Error; err != nil {
+		return dto.PaginationMeta{}, err
+	}
+
+	return dto.PaginationMeta{
+		TotalCount: totalCount,
+		Offset:     offset,
+		Limit:      limit,
+	}, nil
+}
+
+// FindExpiredBooks returns the books trashed before the given time
+func (r *TrashRepositoryImpl) FindExpiredBooks(before time.Time) ([]model.Book, error) {
+	var books []model.Book
+	if err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at ASC").
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// FindBookImages returns the gallery images of a book and the covers its revisions recorded,
+// which purging the book removes the last trace of
+func (r *TrashRepositoryImpl) FindBookImages(id uuid.UUID) ([]string, error) {
+	var revisions []model.BookRevision
+	if err := r.db.Select("before", "after").Where("book_id = ?", id).Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	images, err := revisionImages(revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	var gallery []model.BookImage
+	if err := r.db.Select("url", "metadata").Where("book_id = ?", id).Find(&gallery).Error; err != nil {
+		return nil, err
+	}
+	for _, image := range gallery {
+		images = append(images, image.URL)
+		images = append(images, image.Metadata.URLs()...)
+	}
+	return images, nil
+}
+
+// PurgeBook removes a book for good; its reviews, revisions and alerts go with it through
+// the foreign keys
+func (r *TrashRepositoryImpl) PurgeBook(id uuid.UUID) error {
+	return r.db.Unscoped().Delete(&model.Book{}, "id = ?", id).Error
+}
+
+// PurgeReviews removes reviews trashed before the given time for good
+func (r *TrashRepositoryImpl) PurgeReviews(before time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&model.Review{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
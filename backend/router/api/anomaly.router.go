@@ -0,0 +1,45 @@
+package api
+
+import (
+	"slices"
+
+	"honya/backend/config"
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AnomalyRouter struct {
+	app  *fiber.App
+	ctrl controller.AnomalyController
+}
+
+func NewAnomalyRouter(app *fiber.App) *AnomalyRouter {
+	env, _ := config.GetEnvConfig()
+
+	service := service.NewAnomalyService(repository.NewReviewRepository(), repository.NewBookRepository(), repository.NewReviewAlertRepository(), service.AnomalyLimits{
+		ZThreshold:   env.AnomalyZThreshold,
+		MinReviews:   env.AnomalyMinReviews,
+		HoldDuration: env.AnomalyHoldDuration,
+		// Behind a misconfigured proxy every review would seem to come from the proxy itself
+		IgnoredNetworks: utils.ParseNetworks(slices.Concat(env.TrustedProxies, env.AnomalyIgnoredRanges)),
+	})
+	ctrl := controller.NewAnomalyController(service)
+
+	service.StartAnalyzer(utils.AnomalyAnalyzerInterval)
+
+	return &AnomalyRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *AnomalyRouter) Setup(api fiber.Router) {
+	alertRoutes := api.Group("/dashboard/alerts", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator))
+
+	alertRoutes.Get("/", r.ctrl.GetAlerts)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditRouter struct {
+	app     *fiber.App
+	service service.AuditService
+	ctrl    controller.AuditController
+}
+
+func NewAuditRouter(app *fiber.App) *AuditRouter {
+	service := service.NewAuditService(repository.NewAuditRepository())
+	ctrl := controller.NewAuditController(service)
+
+	return &AuditRouter{
+		app:     app,
+		service: service,
+		ctrl:    ctrl,
+	}
+}
+
+// Recorder returns the middleware that writes audit entries for the API group
+func (r *AuditRouter) Recorder() fiber.Handler {
+	return middleware.Audit(r.service)
+}
+
+func (r *AuditRouter) Setup(api fiber.Router) {
+	auditRoutes := api.Group("/audit", middleware.RequireRole(utils.RoleAdmin))
+
+	auditRoutes.Get("/", r.ctrl.GetLogs)
+	auditRoutes.Get("/verify", r.ctrl.Verify)
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"honya/backend/controller"
+	"honya/backend/middleware"
 	"honya/backend/repository"
 	"honya/backend/service"
+	"honya/backend/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,12 +15,13 @@ type BookRouter struct {
 	ctrl controller.BookController
 }
 
-func NewBookRouter(app *fiber.App) *BookRouter {
+func NewBookRouter(app *fiber.App, aggregator service.DashboardAggregator) *BookRouter {
 	repo := repository.NewBookRepository()
-	s3repo := repository.NewS3Repository()
-	service := service.NewBookService(repo, s3repo)
+	service := service.NewBookService(repo, repository.GetBlobStore(), aggregator)
 	ctrl := controller.NewBookController(service)
 
+	service.StartPublisher(utils.BookPublishInterval)
+
 	return &BookRouter{
 		app:  app,
 		ctrl: ctrl,
@@ -29,8 +32,13 @@ func (r *BookRouter) Setup(api fiber.Router) {
 	booksRoutes := api.Group("/books")
 
 	booksRoutes.Get("/", r.ctrl.GetBooks)
+	booksRoutes.Get("/duplicates", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator), r.ctrl.GetDuplicates)
 	booksRoutes.Get("/:id", r.ctrl.GetBookByID)
 	booksRoutes.Post("/", r.ctrl.CreateBook)
 	booksRoutes.Patch("/:id", r.ctrl.UpdateBook)
 	booksRoutes.Delete("/:id", r.ctrl.DeleteBook)
+	booksRoutes.Post("/:id/restore", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator), r.ctrl.RestoreBook)
+	booksRoutes.Post("/:id/merge", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator), r.ctrl.MergeBook)
+	booksRoutes.Get("/:id/revisions", r.ctrl.GetRevisions)
+	booksRoutes.Post("/:id/revisions/:rev/restore", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator), r.ctrl.RestoreRevision)
 }
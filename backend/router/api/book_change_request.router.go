@@ -0,0 +1,40 @@
+package api
+
+import (
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BookChangeRequestRouter struct {
+	app  *fiber.App
+	ctrl controller.BookChangeRequestController
+}
+
+func NewBookChangeRequestRouter(app *fiber.App, challenges service.ChallengeService, aggregator service.DashboardAggregator) *BookChangeRequestRouter {
+	bookRepo := repository.NewBookRepository()
+	bookService := service.NewBookService(bookRepo, repository.GetBlobStore(), aggregator)
+	service := service.NewBookChangeRequestService(repository.NewBookChangeRequestRepository(), bookRepo, bookService)
+	ctrl := controller.NewBookChangeRequestController(service, challenges)
+
+	return &BookChangeRequestRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *BookChangeRequestRouter) Setup(api fiber.Router) {
+	api.Post("/books/:id/change-requests", middleware.ChangeRequestRateLimiter(), r.ctrl.CreateRequest)
+
+	changeRequestRoutes := api.Group("/change-requests")
+	editorOnly := middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator)
+
+	changeRequestRoutes.Get("/", editorOnly, r.ctrl.GetRequests)
+	changeRequestRoutes.Get("/:id", r.ctrl.GetRequest)
+	changeRequestRoutes.Post("/:id/accept", editorOnly, r.ctrl.AcceptRequest)
+	changeRequestRoutes.Post("/:id/reject", editorOnly, r.ctrl.RejectRequest)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"honya/backend/controller"
+	"honya/backend/repository"
+	"honya/backend/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BookImageRouter struct {
+	app  *fiber.App
+	ctrl controller.BookImageController
+}
+
+func NewBookImageRouter(app *fiber.App, aggregator service.DashboardAggregator) *BookImageRouter {
+	store := repository.GetBlobStore()
+	bookService := service.NewBookService(repository.NewBookRepository(), store, aggregator)
+	service := service.NewBookImageService(repository.NewBookImageRepository(), store, bookService)
+	ctrl := controller.NewBookImageController(service)
+
+	return &BookImageRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *BookImageRouter) Setup(api fiber.Router) {
+	imageRoutes := api.Group("/books/:id/images")
+
+	imageRoutes.Get("/", r.ctrl.GetImages)
+	imageRoutes.Post("/", r.ctrl.AddImage)
+	imageRoutes.Put("/order", r.ctrl.ReorderImages)
+	imageRoutes.Patch("/:imageId", r.ctrl.UpdateImage)
+	imageRoutes.Delete("/:imageId", r.ctrl.DeleteImage)
+}
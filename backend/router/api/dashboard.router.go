@@ -13,10 +13,10 @@ type DashboardRouter struct {
 	ctrl controller.DashboardController
 }
 
-func NewDashboardRouter(app *fiber.App) *DashboardRouter {
+func NewDashboardRouter(app *fiber.App, aggregator service.DashboardAggregator) *DashboardRouter {
 	bookRepo := repository.NewBookRepository()
 	repoReview := repository.NewReviewRepository()
-	service := service.NewDashboardService(bookRepo, repoReview)
+	service := service.NewDashboardService(bookRepo, repoReview, aggregator)
 	ctrl := controller.NewDashboardController(service)
 
 	return &DashboardRouter{
@@ -30,4 +30,6 @@ func (r *DashboardRouter) Setup(api fiber.Router) {
 
 	dashboardRoutes.Get("/books-data", r.ctrl.GetBooksData)
 	dashboardRoutes.Get("/reviews-data", r.ctrl.GetReviewsData)
+	dashboardRoutes.Get("/timeseries", r.ctrl.GetTimeSeries)
+	dashboardRoutes.Post("/query", r.ctrl.RunQuery)
 }
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataQualityRouter struct {
+	app  *fiber.App
+	ctrl controller.DataQualityController
+}
+
+func NewDataQualityRouter(app *fiber.App) *DataQualityRouter {
+	service := service.NewDataQualityService(repository.NewDataQualityRepository(), repository.GetBlobStore())
+	ctrl := controller.NewDataQualityController(service)
+
+	return &DataQualityRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *DataQualityRouter) Setup(api fiber.Router) {
+	dataQualityRoutes := api.Group("/dashboard/data-quality", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator))
+
+	dataQualityRoutes.Get("/", r.ctrl.GetReport)
+}
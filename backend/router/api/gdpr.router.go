@@ -0,0 +1,40 @@
+package api
+
+import (
+	"honya/backend/config"
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type GdprRouter struct {
+	app  *fiber.App
+	ctrl controller.GdprController
+}
+
+func NewGdprRouter(app *fiber.App, aggregator service.DashboardAggregator) *GdprRouter {
+	env, _ := config.GetEnvConfig()
+
+	reviewRepo := repository.NewReviewRepository()
+	requestRepo := repository.NewDataSubjectRequestRepository()
+	changeRepo := repository.NewBookChangeRequestRepository()
+	service := service.NewGdprService(reviewRepo, requestRepo, changeRepo, env.GdprKeepContent, env.GdprHashSecret, aggregator)
+	ctrl := controller.NewGdprController(service)
+
+	return &GdprRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *GdprRouter) Setup(api fiber.Router) {
+	gdprRoutes := api.Group("/admin/gdpr", middleware.RequireRole(utils.RoleAdmin))
+
+	gdprRoutes.Get("/export", r.ctrl.ExportByEmail)
+	gdprRoutes.Post("/erase", r.ctrl.EraseByEmail)
+	gdprRoutes.Get("/requests", r.ctrl.GetRequests)
+}
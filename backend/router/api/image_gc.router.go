@@ -0,0 +1,37 @@
+package api
+
+import (
+	"honya/backend/config"
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImageGCRouter struct {
+	app  *fiber.App
+	ctrl controller.ImageGCController
+}
+
+func NewImageGCRouter(app *fiber.App) *ImageGCRouter {
+	env, _ := config.GetEnvConfig()
+
+	service := service.NewImageGCService(repository.NewImageGCRepository(), repository.GetBlobStore(), env.ImageGCGracePeriod, env.ImageGCRevisionRetention)
+	ctrl := controller.NewImageGCController(service)
+
+	service.StartCollector(utils.ImageGCInterval, env.ImageGCDryRun)
+
+	return &ImageGCRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *ImageGCRouter) Setup(api fiber.Router) {
+	storageRoutes := api.Group("/admin/storage", middleware.RequireRole(utils.RoleAdmin))
+
+	storageRoutes.Post("/gc", r.ctrl.Collect)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"honya/backend/config"
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ModerationRouter struct {
+	app  *fiber.App
+	ctrl controller.ModerationController
+}
+
+func NewModerationRouter(app *fiber.App, aggregator service.DashboardAggregator) *ModerationRouter {
+	env, _ := config.GetEnvConfig()
+
+	reviewRepo := repository.NewReviewRepository()
+	reportRepo := repository.NewReviewReportRepository()
+	service := service.NewModerationService(reviewRepo, reportRepo, env.ReviewReportThreshold, env.GdprHashSecret, aggregator)
+	ctrl := controller.NewModerationController(service)
+
+	return &ModerationRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *ModerationRouter) Setup(api fiber.Router) {
+	api.Post("/reviews/:id/reports", middleware.ReportRateLimiter(), r.ctrl.ReportReview)
+
+	moderationRoutes := api.Group("/moderation", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator))
+
+	moderationRoutes.Get("/reviews", r.ctrl.GetQueue)
+	moderationRoutes.Post("/reviews/:id/approve", r.ctrl.ApproveReview)
+	moderationRoutes.Delete("/reviews/:id", r.ctrl.RemoveReview)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportRouter struct {
+	app  *fiber.App
+	ctrl controller.ReportController
+}
+
+func NewReportRouter(app *fiber.App, aggregator service.DashboardAggregator) *ReportRouter {
+	reportRepo := repository.NewScheduledReportRepository()
+	runRepo := repository.NewReportRunRepository()
+	dashboard := service.NewDashboardService(repository.NewBookRepository(), repository.NewReviewRepository(), aggregator)
+	service := service.NewReportService(reportRepo, runRepo, dashboard, repository.NewMailerRepository())
+	ctrl := controller.NewReportController(service)
+
+	service.StartScheduler(utils.ReportSchedulerInterval)
+
+	return &ReportRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *ReportRouter) Setup(api fiber.Router) {
+	reportRoutes := api.Group("/admin/reports", middleware.RequireRole(utils.RoleAdmin))
+
+	reportRoutes.Get("/", r.ctrl.GetReports)
+	reportRoutes.Post("/", r.ctrl.CreateReport)
+	reportRoutes.Get("/:id", r.ctrl.GetReportByID)
+	reportRoutes.Patch("/:id", r.ctrl.UpdateReport)
+	reportRoutes.Delete("/:id", r.ctrl.DeleteReport)
+	reportRoutes.Post("/:id/send", r.ctrl.SendNow)
+	reportRoutes.Get("/:id/runs", r.ctrl.GetRuns)
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"honya/backend/config"
 	"honya/backend/controller"
+	"honya/backend/middleware"
 	"honya/backend/repository"
 	"honya/backend/service"
 
@@ -13,10 +15,16 @@ type ReviewRouter struct {
 	ctrl controller.ReviewController
 }
 
-func NewReviewRouter(app *fiber.App) *ReviewRouter {
+func NewReviewRouter(app *fiber.App, challenges service.ChallengeService, aggregator service.DashboardAggregator) *ReviewRouter {
+	env, _ := config.GetEnvConfig()
+
 	repo := repository.NewReviewRepository()
-	service := service.NewReviewService(repo)
-	ctrl := controller.NewReviewController(service)
+	service := service.NewReviewService(repo, repository.NewBookRepository(), challenges, service.ReviewLimits{
+		IgnoreGmailDots: env.ReviewIgnoreGmailDots,
+		MaxPerWindow:    env.ReviewRateLimitMax,
+		Window:          env.ReviewRateLimitWindow,
+	}, aggregator)
+	ctrl := controller.NewReviewController(service, challenges)
 
 	return &ReviewRouter{
 		app:  app,
@@ -28,9 +36,10 @@ func (r *ReviewRouter) Setup(api fiber.Router) {
 	reviewRoutes := api.Group("/reviews")
 
 	reviewRoutes.Get("/", r.ctrl.GetAllReviews)
+	reviewRoutes.Get("/challenge", r.ctrl.GetChallenge)
 	reviewRoutes.Get("/:id", r.ctrl.GetReviewByID)
 	reviewRoutes.Get("/book/:book_id", r.ctrl.GetReviewsByBookID)
-	reviewRoutes.Post("/", r.ctrl.CreateReview)
+	reviewRoutes.Post("/", middleware.ReviewRateLimiter(), r.ctrl.CreateReview)
 	reviewRoutes.Patch("/:id", r.ctrl.UpdateReview)
 	reviewRoutes.Delete("/:id", r.ctrl.DeleteReview)
 }
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"honya/backend/config"
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrashRouter struct {
+	app  *fiber.App
+	ctrl controller.TrashController
+}
+
+func NewTrashRouter(app *fiber.App) *TrashRouter {
+	env, _ := config.GetEnvConfig()
+
+	images := service.NewImageGCService(repository.NewImageGCRepository(), repository.GetBlobStore(), env.ImageGCGracePeriod, env.ImageGCRevisionRetention)
+	service := service.NewTrashService(repository.NewTrashRepository(), env.TrashRetention, images, env.ImageGCDryRun)
+	ctrl := controller.NewTrashController(service)
+
+	service.StartPurger(utils.TrashPurgeInterval)
+
+	return &TrashRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *TrashRouter) Setup(api fiber.Router) {
+	trashRoutes := api.Group("/trash", middleware.RequireRole(utils.RoleAdmin, utils.RoleModerator))
+
+	trashRoutes.Get("/", r.ctrl.GetTrash)
+}
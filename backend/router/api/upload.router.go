@@ -0,0 +1,43 @@
+package api
+
+import (
+	"honya/backend/config"
+	"honya/backend/controller"
+	"honya/backend/middleware"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UploadRouter struct {
+	app  *fiber.App
+	ctrl controller.UploadController
+}
+
+func NewUploadRouter(app *fiber.App, aggregator service.DashboardAggregator) *UploadRouter {
+	env, _ := config.GetEnvConfig()
+
+	store := repository.GetBlobStore()
+	bookService := service.NewBookService(repository.NewBookRepository(), store, aggregator)
+	ctrl := controller.NewUploadController(service.NewUploadService(store, bookService))
+
+	// Presigned URLs of stores served by the API point at the stored files, so their uploads
+	// are received there rather than under /api
+	if _, ok := store.(repository.UploadVerifier); ok {
+		app.Put(utils.StoragePath(env.LocalStorageURL)+"/*", middleware.RateLimiter(), ctrl.ReceiveUpload)
+	}
+
+	return &UploadRouter{
+		app:  app,
+		ctrl: ctrl,
+	}
+}
+
+func (r *UploadRouter) Setup(api fiber.Router) {
+	uploadRoutes := api.Group("/uploads")
+
+	uploadRoutes.Post("/", r.ctrl.CreateUpload)
+	uploadRoutes.Post("/:id/finalize", r.ctrl.FinalizeUpload)
+}
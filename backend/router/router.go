@@ -1,38 +1,73 @@
 package router
 
 import (
+	"honya/backend/config"
 	"honya/backend/middleware"
+	"honya/backend/repository"
 	"honya/backend/router/api"
+	"honya/backend/service"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type Router struct {
-	app             *fiber.App
-	healthRouter    *api.HealthRouter
-	bookRouter      *api.BookRouter
-	reviewRouter    *api.ReviewRouter
-	seedRouter      *api.SeedRouter
-	urlRouter       *api.UrlRouter
-	dashboardRouter *api.DashboardRouter
+	app                 *fiber.App
+	healthRouter        *api.HealthRouter
+	bookRouter          *api.BookRouter
+	reviewRouter        *api.ReviewRouter
+	seedRouter          *api.SeedRouter
+	urlRouter           *api.UrlRouter
+	dashboardRouter     *api.DashboardRouter
+	gdprRouter          *api.GdprRouter
+	moderationRouter    *api.ModerationRouter
+	reportRouter        *api.ReportRouter
+	anomalyRouter       *api.AnomalyRouter
+	dataQualityRouter   *api.DataQualityRouter
+	trashRouter         *api.TrashRouter
+	auditRouter         *api.AuditRouter
+	changeRequestRouter *api.BookChangeRequestRouter
+	uploadRouter        *api.UploadRouter
+	imageGCRouter       *api.ImageGCRouter
+	bookImageRouter     *api.BookImageRouter
 }
 
 func New(app *fiber.App) *Router {
+	env, _ := config.GetEnvConfig()
+
+	// Shared so that a challenge issued for reviews also verifies for suggested edits, even
+	// when POW_SECRET is not set and the signing key is random
+	challenges := service.NewChallengeService(repository.NewChallengeRepository(), env.PowEnabled, env.PowSecret, env.PowBaseDifficulty, env.PowMaxDifficulty)
+
+	// Shared so that every write notifies the same aggregator and only one scheduler runs
+	aggregator := service.NewDashboardAggregator(repository.NewDashboardAggregateRepository(), env.DashboardRefreshInterval)
+	aggregator.StartScheduler()
+
 	return &Router{
-		app:             app,
-		healthRouter:    api.NewHealthRouter(app),
-		bookRouter:      api.NewBookRouter(app),
-		reviewRouter:    api.NewReviewRouter(app),
-		seedRouter:      api.NewSeedRouter(app),
-		urlRouter:       api.NewUrlRouter(app),
-		dashboardRouter: api.NewDashboardRouter(app),
+		app:                 app,
+		healthRouter:        api.NewHealthRouter(app),
+		bookRouter:          api.NewBookRouter(app, aggregator),
+		reviewRouter:        api.NewReviewRouter(app, challenges, aggregator),
+		seedRouter:          api.NewSeedRouter(app),
+		urlRouter:           api.NewUrlRouter(app),
+		dashboardRouter:     api.NewDashboardRouter(app, aggregator),
+		gdprRouter:          api.NewGdprRouter(app, aggregator),
+		moderationRouter:    api.NewModerationRouter(app, aggregator),
+		reportRouter:        api.NewReportRouter(app, aggregator),
+		anomalyRouter:       api.NewAnomalyRouter(app),
+		dataQualityRouter:   api.NewDataQualityRouter(app),
+		trashRouter:         api.NewTrashRouter(app),
+		auditRouter:         api.NewAuditRouter(app),
+		changeRequestRouter: api.NewBookChangeRequestRouter(app, challenges, aggregator),
+		uploadRouter:        api.NewUploadRouter(app, aggregator),
+		imageGCRouter:       api.NewImageGCRouter(app),
+		bookImageRouter:     api.NewBookImageRouter(app, aggregator),
 	}
 }
 
 func Setup(app *fiber.App) {
 	router := New(app)
 
-	api := app.Group("/api", middleware.RateLimiter())
+	api := app.Group("/api", middleware.RateLimiter(), middleware.Authenticate(), router.auditRouter.Recorder())
 
 	router.healthRouter.Setup(api)
 	router.bookRouter.Setup(api)
@@ -40,4 +75,15 @@ func Setup(app *fiber.App) {
 	router.seedRouter.Setup(api)
 	router.urlRouter.Setup(api)
 	router.dashboardRouter.Setup(api)
+	router.gdprRouter.Setup(api)
+	router.moderationRouter.Setup(api)
+	router.reportRouter.Setup(api)
+	router.anomalyRouter.Setup(api)
+	router.dataQualityRouter.Setup(api)
+	router.trashRouter.Setup(api)
+	router.auditRouter.Setup(api)
+	router.changeRequestRouter.Setup(api)
+	router.uploadRouter.Setup(api)
+	router.imageGCRouter.Setup(api)
+	router.bookImageRouter.Setup(api)
 }
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"log"
+	"math"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnomalyService watches review activity for review bombing and holds affected books for moderation
+type AnomalyService interface {
+	Analyze(now time.Time) ([]model.ReviewAlert, error)
+	GetAlerts(params dto.ReviewAlertQueryParams) ([]model.ReviewAlert, *dto.PaginationMeta, error)
+	StartAnalyzer(interval time.Duration)
+}
+
+// AnomalyLimits configures when review activity counts as an anomaly
+type AnomalyLimits struct {
+	// Standard deviations above a book's baseline the last hour must reach to count as a spike
+	ZThreshold float64
+	// Fewest reviews in the last hour before a book is analyzed at all
+	MinReviews int
+	// How long new reviews of a flagged book go to the moderation queue
+	HoldDuration time.Duration
+	// Proxies and shared gateways whose IP ranges never count as a burst
+	IgnoredNetworks []*net.IPNet
+}
+
+type anomalyService struct {
+	reviewRepo repository.ReviewRepository
+	bookRepo   repository.BookRepository
+	alertRepo  repository.ReviewAlertRepository
+	limits     AnomalyLimits
+}
+
+func NewAnomalyService(reviewRepo repository.ReviewRepository, bookRepo repository.BookRepository, alertRepo repository.ReviewAlertRepository, limits AnomalyLimits) AnomalyService {
+	return &anomalyService{
+		reviewRepo: reviewRepo,
+		bookRepo:   bookRepo,
+		alertRepo:  alertRepo,
+		limits:     limits,
+	}
+}
+
+func (s *anomalyService) GetAlerts(params dto.ReviewAlertQueryParams) ([]model.ReviewAlert, *dto.PaginationMeta, error) {
+	if params.Type != "" && params.Type != utils.AlertTypeVolumeSpike && params.Type != utils.AlertTypeDomainBurst && params.Type != utils.AlertTypeIPRangeBurst {
+		return nil, nil, errors.NewBadRequestError("Invalid type. Allowed values: volume_spike, email_domain_burst, ip_range_burst")
+	}
+
+	alerts, meta, err := s.alertRepo.FindAll(params)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	return alerts, &meta, nil
+}
+
+// Analyze compares the last hour of reviews of every busy book against its hourly baseline
+// and looks for bursts from a single email domain or IP range. It returns the new alerts;
+// each one puts its book on a moderation hold.
+func (s *anomalyService) Analyze(now time.Time) ([]model.ReviewAlert, error) {
+	windowStart := now.Add(-time.Hour).Unix()
+
+	active, err := s.reviewRepo.CountActiveBooksSince(windowStart, int64(s.limits.MinReviews))
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 {
+		return nil, nil
+	}
+
+	bookIDs := make([]uuid.UUID, 0, len(active))
+	for bookID := range active {
+		bookIDs = append(bookIDs, bookID)
+	}
+	sort.Slice(bookIDs, func(i, j int) bool { return bookIDs[i].String() < bookIDs[j].String() })
+
+	candidates, err := s.detectSpikes(bookIDs, active, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range []string{utils.AlertTypeDomainBurst, utils.AlertTypeIPRangeBurst} {
+		bursts, err := s.detectBursts(source, bookIDs, active, windowStart)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, bursts...)
+	}
+
+	// Alerts are keyed to the clock hour so the analyzer can run often without repeating itself
+	alertWindow := now.Truncate(time.Hour).Unix()
+	heldUntil := now.Add(s.limits.HoldDuration).Unix()
+
+	alerts := []model.ReviewAlert{}
+	for _, alert := range candidates {
+		alert.WindowStart = alertWindow
+		alert.HeldUntil = heldUntil
+
+		created, err := s.alertRepo.CreateIfAbsent(&alert)
+		if err != nil {
+			return alerts, err
+		}
+		if !created {
+			continue
+		}
+
+		if err := s.bookRepo.HoldReviews(alert.BookID, heldUntil); err != nil {
+			return alerts, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+// detectSpikes flags books whose last hour is ZThreshold deviations above the moving
+// average of their hourly review counts
+func (s *anomalyService) detectSpikes(bookIDs []uuid.UUID, active map[uuid.UUID]int64, windowStart int64) ([]model.ReviewAlert, error) {
+	baselineFrom := windowStart - utils.AnomalyBaselineHours*3600
+
+	hourly, err := s.reviewRepo.CountHourlyByBook(bookIDs, baselineFrom, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[uuid.UUID][]int64, len(bookIDs))
+	for _, bookID := range bookIDs {
+		series[bookID] = make([]int64, utils.AnomalyBaselineHours)
+	}
+	for _, count := range hourly {
+		if hours, ok := series[count.BookID]; ok && count.Hour >= 0 && count.Hour < int64(len(hours)) {
+			hours[count.Hour] = count.Count
+		}
+	}
+
+	alerts := []model.ReviewAlert{}
+	for _, bookID := range bookIDs {
+		mean, stdDev := utils.EWMABaseline(series[bookID], utils.AnomalyEWMAAlpha)
+		score := (float64(active[bookID]) - mean) / math.Max(stdDev, utils.AnomalyMinStdDev)
+		if score < s.limits.ZThreshold {
+			continue
+		}
+
+		alerts = append(alerts, model.ReviewAlert{
+			BookID:   bookID,
+			Type:     utils.AlertTypeVolumeSpike,
+			Count:    active[bookID],
+			Baseline: roundScore(mean),
+			Score:    roundScore(score),
+		})
+	}
+
+	return alerts, nil
+}
+
+// detectBursts flags an email domain or IP range behind most of a book's recent reviews.
+// The score of a burst is the share of the book's reviews in the window it accounts for.
+// Public email providers and ignored networks are shared by unrelated reviewers, so a popular
+// book reviewed through them is not a burst.
+func (s *anomalyService) detectBursts(source string, bookIDs []uuid.UUID, active map[uuid.UUID]int64, windowStart int64) ([]model.ReviewAlert, error) {
+	counts, err := s.reviewRepo.CountBySourceSince(source, bookIDs, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []model.ReviewAlert{}
+	for _, count := range counts {
+		total := active[count.BookID]
+		if total == 0 || count.Count < int64(s.limits.MinReviews) {
+			continue
+		}
+
+		if s.isSharedSource(source, count.Source) {
+			continue
+		}
+
+		share := float64(count.Count) / float64(total)
+		if share < utils.AnomalyBurstShare {
+			continue
+		}
+
+		alerts = append(alerts, model.ReviewAlert{
+			BookID:  count.BookID,
+			Type:    source,
+			Subject: count.Source,
+			Count:   count.Count,
+			Score:   roundScore(share),
+		})
+	}
+
+	return alerts, nil
+}
+
+func (s *anomalyService) isSharedSource(source, subject string) bool {
+	if source == utils.AlertTypeDomainBurst {
+		return utils.IsFreemailDomain(subject)
+	}
+	return utils.NetworksOverlap(subject, s.limits.IgnoredNetworks)
+}
+
+func (s *anomalyService) StartAnalyzer(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			alerts, err := s.Analyze(now)
+			if err != nil {
+				log.Printf("Anomaly analyzer: %v", err)
+			}
+			for _, alert := range alerts {
+				log.Printf("Anomaly analyzer: %s on book %s (%d reviews), holding reviews until %d", alert.Type, alert.BookID, alert.Count, alert.HeldUntil)
+			}
+		}
+	}()
+}
+
+func roundScore(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+)
+
+// AuditService records mutating API calls in the hash-chained audit log, lists them and
+// checks the chain for tampering
+type AuditService interface {
+	Record(entry *model.AuditLog) error
+	GetLogs(params dto.AuditQueryParams) ([]model.AuditLog, *dto.PaginationMeta, error)
+	Verify() (*dto.AuditVerifyResponse, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo}
+}
+
+func (s *auditService) Record(entry *model.AuditLog) error {
+	return s.repo.Append(entry)
+}
+
+func (s *auditService) GetLogs(params dto.AuditQueryParams) ([]model.AuditLog, *dto.PaginationMeta, error) {
+	switch params.Actor {
+	case "", utils.RolePublic, utils.RoleModerator, utils.RoleAdmin:
+	default:
+		return nil, nil, errors.NewBadRequestError("actor must be public, moderator or admin")
+	}
+
+	if params.From > 0 && params.To > 0 && params.From > params.To {
+		return nil, nil, errors.NewBadRequestError("from must not be after to")
+	}
+
+	entries, meta, err := s.repo.FindAll(params)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+
+	return entries, &meta, nil
+}
+
+// Verify walks the chain from the first entry and reports the first one whose link to its
+// predecessor or whose own hash does not match
+func (s *auditService) Verify() (*dto.AuditVerifyResponse, error) {
+	result := &dto.AuditVerifyResponse{Valid: true}
+
+	var lastID uint64
+	prevHash := ""
+	for {
+		entries, err := s.repo.FindAfter(lastID, utils.AuditVerifyBatchSize)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+
+		for _, entry := range entries {
+			result.Checked++
+			if entry.PrevHash != prevHash || entry.ComputeHash() != entry.Hash {
+				brokenAt := entry.ID
+				result.Valid = false
+				result.BrokenAt = &brokenAt
+				return result, nil
+			}
+			prevHash = entry.Hash
+			lastID = entry.ID
+		}
+
+		if len(entries) < utils.AuditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
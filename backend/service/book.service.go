@@ -1,43 +1,57 @@
 package service
 
 import (
-	"honya/backend/config"
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"honya/backend/dto"
 	"honya/backend/model"
 	"honya/backend/repository"
 	"honya/backend/utils"
+	"io"
+	"log"
 	"mime/multipart"
+	"sort"
+	"strings"
+	"time"
 
 	"honya/backend/errors"
 
 	"github.com/google/uuid"
 )
 
-var (
-	env, _     = config.GetEnvConfig()
-	AWS_BUCKET = env.AWSBucket
-	AWS_REGION = env.AWSRegion
-)
-
 // BookService defines the interface for book-related services.
 type BookService interface {
 	GetBooks(params dto.BookQueryParams) ([]model.Book, *dto.PaginationMeta, error)
 	GetBookByID(id uuid.UUID) (*model.Book, error)
 	CreateBook(book *dto.BookCreateRequest, fileHeader *multipart.FileHeader) (*model.Book, error)
-	UpdateBook(id uuid.UUID, updateData *dto.BookUpdateRequest, fileHeader *multipart.FileHeader) (*model.Book, error)
+	UpdateBook(id uuid.UUID, updateData *dto.BookUpdateRequest, fileHeader *multipart.FileHeader, actor string) (*model.Book, error)
 	DeleteBook(id uuid.UUID) error
+	FindDuplicates() ([]dto.BookDuplicate, error)
+	MergeBooks(targetID uuid.UUID, req *dto.BookMergeRequest, actor string) (*model.Book, error)
+	FindRedirect(id uuid.UUID) (*model.BookRedirect, error)
+	GetRevisions(bookID uuid.UUID, offset, limit int, includeHidden bool) ([]model.BookRevision, *dto.PaginationMeta, error)
+	RestoreRevision(bookID uuid.UUID, number int, req *dto.BookRevisionRestoreRequest, actor string) (*model.Book, error)
+	RestoreBook(id uuid.UUID) (*model.Book, error)
+	PublishScheduled(now time.Time) (int, error)
+	StartPublisher(interval time.Duration)
 }
 
 type bookService struct {
-	repo   repository.BookRepository
-	s3repo repository.S3Repository
+	repo       repository.BookRepository
+	store      repository.BlobStore
+	aggregator DashboardAggregator
 }
 
-func NewBookService(repo repository.BookRepository, s3repo repository.S3Repository) BookService {
-	return &bookService{repo, s3repo}
+func NewBookService(repo repository.BookRepository, store repository.BlobStore, aggregator DashboardAggregator) BookService {
+	return &bookService{repo, store, aggregator}
 }
 
 func (s *bookService) GetBooks(params dto.BookQueryParams) ([]model.Book, *dto.PaginationMeta, error) {
+	if params.Status != "" && !utils.IsBookStatus(params.Status) {
+		return nil, nil, errors.NewBadRequestError("status must be draft, scheduled, published or archived")
+	}
+
 	books, meta, err := s.repo.FindAll(params)
 	if err != nil {
 		return nil, nil, errors.NewInternalError(err)
@@ -72,13 +86,22 @@ func (s *bookService) CreateBook(book *dto.BookCreateRequest, fileHeader *multip
 		return nil, errors.NewBadRequestError(err.Error())
 	}
 
+	status := book.Status
+	if status == "" {
+		status = utils.BookStatusPublished
+	}
+	publishAt, err := nextPublishAt("", status, book.PublishAt, 0, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
 	var imageURL string
+	var images *model.BookImages
 	if fileHeader != nil {
-		url, err := s.s3repo.UploadImage(fileHeader, book.Title)
+		imageURL, images, err = storeCover(s.store, fileHeader)
 		if err != nil {
-			return nil, errors.NewInternalError(err)
+			return nil, err
 		}
-		imageURL = url
 	}
 
 	newBook := model.Book{
@@ -86,26 +109,28 @@ func (s *bookService) CreateBook(book *dto.BookCreateRequest, fileHeader *multip
 		Description:     book.Description,
 		Category:        book.Category,
 		Image:           imageURL,
+		Images:          images,
 		PublicationYear: book.PublicationYear,
 		Rating:          book.Rating,
 		Pages:           book.Pages,
 		Isbn:            book.Isbn,
 		AuthorName:      book.AuthorName,
+		Status:          status,
+		PublishAt:       publishAt,
 	}
 
+	// A cover stored for a book that fails to save is left to the image garbage collector
 	resource, err := s.repo.Create(&newBook)
 	if err != nil {
-		if imageURL != "" {
-			key := utils.ExtractS3Key(imageURL, AWS_BUCKET, AWS_REGION)
-			_ = s.s3repo.DeleteImage(key)
-		}
 		return nil, errors.NewInternalError(err)
 	}
 
+	s.aggregator.BooksChanged(resource)
+
 	return resource, nil
 }
 
-func (s *bookService) UpdateBook(id uuid.UUID, updateData *dto.BookUpdateRequest, fileHeader *multipart.FileHeader) (*model.Book, error) {
+func (s *bookService) UpdateBook(id uuid.UUID, updateData *dto.BookUpdateRequest, fileHeader *multipart.FileHeader, actor string) (*model.Book, error) {
 	existingBook, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, errors.NewInternalError(err)
@@ -114,29 +139,41 @@ func (s *bookService) UpdateBook(id uuid.UUID, updateData *dto.BookUpdateRequest
 		return nil, errors.NewNotFoundError("Book not found")
 	}
 
-	// If new image uploaded, replace old one
-	if fileHeader != nil {
-		url, err := s.s3repo.UploadImage(fileHeader, existingBook.Title)
+	if updateData.Status != nil || updateData.PublishAt != nil {
+		status := existingBook.Status
+		if updateData.Status != nil {
+			status = *updateData.Status
+		}
+		var requested int64
+		if updateData.PublishAt != nil {
+			requested = *updateData.PublishAt
+		}
+		publishAt, err := nextPublishAt(existingBook.Status, status, requested, existingBook.PublishAt, time.Now().Unix())
 		if err != nil {
-			return nil, errors.NewInternalError(err)
+			return nil, err
 		}
-		updateData.Image = &url
+		updateData.Status = &status
+		updateData.PublishAt = &publishAt
+	}
 
-		if existingBook.Image != "" {
-			key := utils.ExtractS3Key(existingBook.Image, AWS_BUCKET, AWS_REGION)
-			_ = s.s3repo.DeleteImage(key)
+	// If new image uploaded, replace old one. The old cover stays in storage so that
+	// restoring an earlier revision can bring it back.
+	if fileHeader != nil {
+		url, images, err := storeCover(s.store, fileHeader)
+		if err != nil {
+			return nil, err
 		}
+		updateData.Image = &url
+		updateData.Images = images
 	}
 
-	resource, err := s.repo.Update(id, updateData)
+	resource, err := s.repo.Update(id, updateData, actor)
 	if err != nil {
-		if updateData.Image != nil && *updateData.Image != "" {
-			key := utils.ExtractS3Key(*updateData.Image, AWS_BUCKET, AWS_REGION)
-			_ = s.s3repo.DeleteImage(key)
-		}
 		return nil, errors.NewInternalError(err)
 	}
 
+	s.aggregator.BooksChanged(existingBook, resource)
+
 	return resource, nil
 }
 
@@ -150,12 +187,462 @@ func (s *bookService) DeleteBook(id uuid.UUID) error {
 		return errors.NewNotFoundError("Book not found")
 	}
 
-	// Delete image from S3 if exists
-	if existingBook.Image != "" {
-		key := utils.ExtractS3Key(existingBook.Image, AWS_BUCKET, AWS_REGION)
-		_ = s.s3repo.DeleteImage(key)
+	// Its reviews are trashed along with it, so their reviewers are recounted
+	reviewers, err := s.repo.FindReviewerNames(id)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	// Move the book and its reviews to the trash; the cover stays in storage until the
+	// trash is purged so a restored book keeps it
+	if err := s.repo.Delete(id); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	s.aggregator.BooksChanged(existingBook)
+	s.aggregator.ReviewersChanged(reviewers...)
+
+	return nil
+}
+
+// FindDuplicates pairs up books with the same ISBN (ISBN-10 and ISBN-13 compare equal) or
+// with near-identical normalized titles and authors. Only books sharing a title word are
+// compared, so the finder stays well below quadratic on a real catalog.
+func (s *bookService) FindDuplicates() ([]dto.BookDuplicate, error) {
+	books, err := s.repo.FindDuplicateCandidates()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	titles := make([]string, len(books))
+	authors := make([]string, len(books))
+	byWord := map[string][]int{}
+	byIsbn := map[string][]int{}
+	for i, book := range books {
+		titles[i] = utils.NormalizeBookText(book.Title)
+		authors[i] = utils.NormalizeBookText(book.AuthorName)
+		for _, word := range uniqueWords(titles[i]) {
+			byWord[word] = append(byWord[word], i)
+		}
+		if isbn := utils.NormalizeISBN(book.Isbn); isbn != "" {
+			byIsbn[isbn] = append(byIsbn[isbn], i)
+		}
+	}
+
+	type pair struct{ a, b int }
+	reasons := map[pair][]string{}
+	scores := map[pair]float64{}
+
+	for _, group := range byIsbn {
+		for x := 0; x < len(group); x++ {
+			for y := x + 1; y < len(group); y++ {
+				p := pair{group[x], group[y]}
+				reasons[p] = append(reasons[p], utils.DuplicateReasonISBN)
+				scores[p] = 1
+			}
+		}
+	}
+
+	compared := map[pair]bool{}
+	for _, group := range byWord {
+		for x := 0; x < len(group); x++ {
+			for y := x + 1; y < len(group); y++ {
+				p := pair{group[x], group[y]}
+				if compared[p] {
+					continue
+				}
+				compared[p] = true
+
+				titleScore := utils.TextSimilarity(titles[p.a], titles[p.b])
+				authorScore := utils.TextSimilarity(authors[p.a], authors[p.b])
+				if titleScore < utils.DuplicateTitleThreshold || authorScore < utils.DuplicateAuthorThreshold {
+					continue
+				}
+				reasons[p] = append(reasons[p], utils.DuplicateReasonTitleAuthor)
+				if score := (titleScore + authorScore) / 2; score > scores[p] {
+					scores[p] = score
+				}
+			}
+		}
+	}
+
+	pairs := make([]pair, 0, len(reasons))
+	for p := range reasons {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if scores[pairs[i]] != scores[pairs[j]] {
+			return scores[pairs[i]] > scores[pairs[j]]
+		}
+		if pairs[i].a != pairs[j].a {
+			return pairs[i].a < pairs[j].a
+		}
+		return pairs[i].b < pairs[j].b
+	})
+
+	duplicates := make([]dto.BookDuplicate, 0, len(pairs))
+	for _, p := range pairs {
+		duplicates = append(duplicates, dto.BookDuplicate{
+			Book:      *dto.ToBookResponse(&books[p.a]),
+			Duplicate: *dto.ToBookResponse(&books[p.b]),
+			Score:     scores[p],
+			Reasons:   reasons[p],
+		})
+	}
+
+	return duplicates, nil
+}
+
+func uniqueWords(s string) []string {
+	seen := map[string]bool{}
+	words := []string{}
+	for _, word := range strings.Fields(s) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// MergeBooks merges the source book into the target and removes the source, leaving a
+// redirect behind. Nothing is deleted from storage here: the source and its revisions are
+// gone, so the image GC deletes its cover on its next run unless the merged book took it
+// over. A cover the merged book replaced is kept while the merge revision is retained.
+func (s *bookService) MergeBooks(targetID uuid.UUID, req *dto.BookMergeRequest, actor string) (*model.Book, error) {
+	if req.SourceID == uuid.Nil {
+		return nil, errors.NewBadRequestError("source_id is required")
+	}
+	if req.SourceID == targetID {
+		return nil, errors.NewBadRequestError("A book cannot be merged into itself")
+	}
+
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = utils.MergeStrategyKeepTarget
+	}
+	if strategy != utils.MergeStrategyKeepTarget && strategy != utils.MergeStrategyFillEmpty && strategy != utils.MergeStrategyPreferSource {
+		return nil, errors.NewBadRequestError("strategy must be one of keep_target, fill_empty, prefer_source")
+	}
+
+	target, err := s.repo.FindByID(targetID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if target == nil {
+		return nil, errors.NewNotFoundError("Book not found")
+	}
+
+	source, err := s.repo.FindByID(req.SourceID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if source == nil {
+		return nil, errors.NewNotFoundError("Source book not found")
+	}
+
+	// Source reviews that clash with the target's are dropped, so their reviewers are recounted
+	reviewers, err := s.repo.FindReviewerNames(req.SourceID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	merged, err := s.repo.Merge(targetID, req.SourceID, mergeBookFields(target, source, strategy), actor)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	s.aggregator.BooksChanged(source, target, merged)
+	s.aggregator.ReviewersChanged(reviewers...)
+
+	return merged, nil
+}
+
+// mergeBookFields returns the target columns that take the source's value under a strategy.
+// Empty strings and zero numbers count as missing and never overwrite anything.
+func mergeBookFields(target, source *model.Book, strategy string) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if strategy == utils.MergeStrategyKeepTarget {
+		return updates
+	}
+
+	take := func(column string, targetEmpty, sourceEmpty bool, value interface{}) {
+		if sourceEmpty || (strategy == utils.MergeStrategyFillEmpty && !targetEmpty) {
+			return
+		}
+		updates[column] = value
+	}
+
+	take("title", target.Title == "", source.Title == "", source.Title)
+	take("description", target.Description == "", source.Description == "", source.Description)
+	take("category", target.Category == "", source.Category == "", source.Category)
+	take("image", target.Image == "", source.Image == "", source.Image)
+	if _, ok := updates["image"]; ok {
+		updates["images"] = source.Images
+	}
+	take("publication_year", target.PublicationYear == 0, source.PublicationYear == 0, source.PublicationYear)
+	take("rating", target.Rating == 0, source.Rating == 0, source.Rating)
+	take("pages", target.Pages == 0, source.Pages == 0, source.Pages)
+	take("isbn", target.Isbn == "", source.Isbn == "", source.Isbn)
+	take("author_name", target.AuthorName == "", source.AuthorName == "", source.AuthorName)
+
+	return updates
+}
+
+// FindRedirect returns where the ID of a merged book now points, or nil
+func (s *bookService) FindRedirect(id uuid.UUID) (*model.BookRedirect, error) {
+	redirect, err := s.repo.FindRedirect(id)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return redirect, nil
+}
+
+// GetRevisions lists a book's revisions, newest first. The history of drafts and scheduled
+// books is only returned with includeHidden.
+func (s *bookService) GetRevisions(bookID uuid.UUID, offset, limit int, includeHidden bool) ([]model.BookRevision, *dto.PaginationMeta, error) {
+	book, err := s.repo.FindByID(bookID)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	if book == nil || (!includeHidden && !utils.IsBookVisible(book.Status)) {
+		return nil, nil, errors.NewNotFoundError("Book not found")
 	}
 
-	// Delete book from DB
-	return s.repo.Delete(id)
+	revisions, meta, err := s.repo.FindRevisions(bookID, offset, limit)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+
+	return revisions, &meta, nil
+}
+
+// RestoreRevision rolls back the given revision and every later one, returning the book to
+// how it was just before that revision. The rollback is itself recorded as a new revision.
+func (s *bookService) RestoreRevision(bookID uuid.UUID, number int, req *dto.BookRevisionRestoreRequest, actor string) (*model.Book, error) {
+	existingBook, err := s.repo.FindByID(bookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if existingBook == nil {
+		return nil, errors.NewNotFoundError("Book not found")
+	}
+
+	revisions, err := s.repo.FindRevisionsSince(bookID, number)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if len(revisions) == 0 || revisions[0].Number != number {
+		return nil, errors.NewNotFoundError("Revision not found")
+	}
+
+	// The earliest revision to touch a field holds its value from before the rollback point
+	restored := map[string]interface{}{}
+	for _, revision := range revisions {
+		for field, value := range revision.Before {
+			if _, ok := restored[field]; !ok {
+				restored[field] = value
+			}
+		}
+	}
+
+	// The image GC only keeps covers for revisions within IMAGE_GC_REVISION_RETENTION; when an
+	// older revision's cover is gone, the book keeps its current one
+	if image, ok := restored["image"].(string); ok && image != "" && image != existingBook.Image {
+		stored, err := s.coverStored(image)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		if !stored {
+			delete(restored, "image")
+			delete(restored, "images")
+		}
+	}
+
+	payload, err := json.Marshal(restored)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	var updateData dto.BookUpdateRequest
+	if err := json.Unmarshal(payload, &updateData); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	updateData.Reason = fmt.Sprintf("Restored revision %d", number)
+	if req.Reason != "" {
+		updateData.Reason += ": " + req.Reason
+	}
+
+	resource, err := s.repo.Update(bookID, &updateData, actor)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	s.aggregator.BooksChanged(existingBook, resource)
+
+	return resource, nil
+}
+
+// coverStored reports whether the blob behind a cover URL still exists. Covers hosted
+// elsewhere are taken as stored.
+func (s *bookService) coverStored(url string) (bool, error) {
+	key, ok := s.store.Key(url)
+	if !ok {
+		return true, nil
+	}
+	return s.store.Exists(key)
+}
+
+// RestoreBook takes a book and the reviews deleted with it out of the trash
+func (s *bookService) RestoreBook(id uuid.UUID) (*model.Book, error) {
+	reviewers, err := s.repo.FindReviewerNames(id)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	book, err := s.repo.Restore(id)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if book == nil {
+		return nil, errors.NewNotFoundError("Book not found in trash")
+	}
+
+	s.aggregator.BooksChanged(book)
+	s.aggregator.ReviewersChanged(reviewers...)
+
+	return book, nil
+}
+
+// nextPublishAt validates a status change and returns the book's publish time after it.
+// requested is the publish time asked for, which only scheduling accepts; current is the
+// book's publish time before the change.
+func nextPublishAt(from, to string, requested, current, now int64) (int64, error) {
+	if !utils.CanChangeBookStatus(from, to) {
+		if from == "" {
+			return 0, errors.NewBadRequestError(fmt.Sprintf("A book cannot be created as %s", to))
+		}
+		return 0, errors.NewBadRequestError(fmt.Sprintf("A %s book cannot be moved to %s", from, to))
+	}
+	if requested != 0 && to != utils.BookStatusScheduled {
+		return 0, errors.NewBadRequestError("publish_at can only be set when scheduling a book")
+	}
+
+	switch to {
+	case utils.BookStatusScheduled:
+		if requested <= now {
+			return 0, errors.NewBadRequestError("publish_at must be in the future to schedule a book")
+		}
+		return requested, nil
+	case utils.BookStatusPublished:
+		// Books coming back from the archive keep their original publish time
+		if from == utils.BookStatusArchived && current > 0 {
+			return current, nil
+		}
+		return now, nil
+	case utils.BookStatusDraft:
+		return 0, nil
+	default:
+		return current, nil
+	}
+}
+
+// PublishScheduled publishes the scheduled books whose publish time has passed and returns
+// how many went live
+func (s *bookService) PublishScheduled(now time.Time) (int, error) {
+	books, err := s.repo.PublishDue(now.Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range books {
+		s.aggregator.BooksChanged(&books[i])
+	}
+
+	return len(books), nil
+}
+
+// StartPublisher runs PublishScheduled every interval in the background
+func (s *bookService) StartPublisher(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			count, err := s.PublishScheduled(now)
+			if err != nil {
+				log.Printf("Book publisher: %v", err)
+			}
+			if count > 0 {
+				log.Printf("Book publisher: published %d scheduled books", count)
+			}
+		}
+	}()
+}
+
+// storeCover validates a cover uploaded through a form, stores all of its variants and returns
+// the URL of the large JPEG, kept as the book's image, together with the variant set. Invalid
+// images are rejected with a bad request error.
+func storeCover(store repository.BlobStore, fileHeader *multipart.FileHeader) (string, *model.BookImages, error) {
+	if fileHeader.Size > utils.CoverMaxBytes {
+		return "", nil, errors.NewBadRequestError(fmt.Sprintf("image must be at most %d MB", utils.CoverMaxBytes>>20))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", nil, errors.NewInternalError(err)
+	}
+	defer file.Close()
+
+	return storeCoverImage(store, file)
+}
+
+// storeCoverImage is storeCover for an image read from anywhere. Variants are stored under the
+// hash of their content, so they may already be in use by other books; variants stored before
+// a failure are left to the image garbage collector.
+func storeCoverImage(store repository.BlobStore, r io.Reader) (string, *model.BookImages, error) {
+	cover, err := utils.ProcessCoverImage(r)
+	if err != nil {
+		if _, ok := err.(*errors.AppError); ok {
+			return "", nil, err
+		}
+		return "", nil, errors.NewInternalError(err)
+	}
+
+	images := &model.BookImages{
+		Width:         cover.Width,
+		Height:        cover.Height,
+		Blurhash:      cover.Blurhash,
+		DominantColor: cover.DominantColor,
+		Variants:      map[string]model.ImageVariant{},
+	}
+
+	for _, variant := range cover.Variants {
+		jpegURL, err := storeContent(store, variant.JPEG, ".jpg", "image/jpeg")
+		if err != nil {
+			return "", nil, errors.NewInternalError(err)
+		}
+		webpURL, err := storeContent(store, variant.WebP, ".webp", "image/webp")
+		if err != nil {
+			return "", nil, errors.NewInternalError(err)
+		}
+		images.Variants[variant.Name] = model.ImageVariant{Width: variant.Width, Height: variant.Height, JPEG: jpegURL, WebP: webpURL}
+	}
+
+	return images.Variants[utils.CoverVariantLarge].JPEG, images, nil
+}
+
+// storeContent stores data under the hash of its content. When the same bytes are already
+// stored, nothing is uploaded; the blob is only touched so that the image garbage collector
+// does not remove it before the row that now refers to it is saved.
+func storeContent(store repository.BlobStore, data []byte, ext, contentType string) (string, error) {
+	key := utils.ContentKey(data, ext)
+	exists, err := store.Touch(key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return store.URL(key), nil
+	}
+	return store.Put(key, bytes.NewReader(data), contentType)
 }
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"encoding/json"
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookChangeRequestService lets readers propose corrections to book metadata and editors
+// accept or reject them. Accepted changes go through BookService.UpdateBook, so they are
+// recorded as revisions like any other edit.
+type BookChangeRequestService interface {
+	CreateRequest(bookID uuid.UUID, req *dto.BookChangeRequestCreateRequest) (*model.BookChangeRequest, error)
+	GetRequest(id uuid.UUID) (*model.BookChangeRequest, error)
+	GetRequests(params dto.BookChangeRequestQueryParams) ([]model.BookChangeRequest, *dto.PaginationMeta, error)
+	AcceptRequest(id uuid.UUID, req *dto.BookChangeRequestReviewRequest, actor string) (*model.BookChangeRequest, error)
+	RejectRequest(id uuid.UUID, req *dto.BookChangeRequestReviewRequest, actor string) (*model.BookChangeRequest, error)
+}
+
+type bookChangeRequestService struct {
+	repo        repository.BookChangeRequestRepository
+	bookRepo    repository.BookRepository
+	bookService BookService
+}
+
+func NewBookChangeRequestService(repo repository.BookChangeRequestRepository, bookRepo repository.BookRepository, bookService BookService) BookChangeRequestService {
+	return &bookChangeRequestService{repo, bookRepo, bookService}
+}
+
+func (s *bookChangeRequestService) CreateRequest(bookID uuid.UUID, req *dto.BookChangeRequestCreateRequest) (*model.BookChangeRequest, error) {
+	if err := utils.ValidateBookChangeRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	book, err := s.bookRepo.FindByID(bookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if book == nil || !utils.IsBookVisible(book.Status) {
+		return nil, errors.NewNotFoundError("Book not found")
+	}
+
+	proposed, err := toFieldMap(&req.Changes)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	current, err := toFieldMap(book)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	// Fields that already hold the proposed value are left out
+	before := map[string]interface{}{}
+	after := map[string]interface{}{}
+	for field, value := range proposed {
+		if current[field] != value {
+			before[field] = current[field]
+			after[field] = value
+		}
+	}
+	if len(after) == 0 {
+		return nil, errors.NewBadRequestError("The proposed changes match the book as it is")
+	}
+
+	request, err := s.repo.Create(&model.BookChangeRequest{
+		BookID:  bookID,
+		Before:  before,
+		After:   after,
+		Comment: strings.TrimSpace(req.Comment),
+		Name:    strings.TrimSpace(req.Name),
+		Email:   strings.TrimSpace(req.Email),
+		Status:  utils.ChangeRequestStatusPending,
+	})
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	return request, nil
+}
+
+// toFieldMap returns the JSON fields of v, so books and update requests compare by field name
+func toFieldMap(v interface{}) (map[string]interface{}, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (s *bookChangeRequestService) GetRequest(id uuid.UUID) (*model.BookChangeRequest, error) {
+	request, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if request == nil {
+		return nil, errors.NewNotFoundError("Change request not found")
+	}
+	return request, nil
+}
+
+func (s *bookChangeRequestService) GetRequests(params dto.BookChangeRequestQueryParams) ([]model.BookChangeRequest, *dto.PaginationMeta, error) {
+	switch params.Status {
+	case "":
+		params.Status = utils.ChangeRequestStatusPending
+	case utils.ChangeRequestStatusPending, utils.ChangeRequestStatusAccepted, utils.ChangeRequestStatusRejected:
+	default:
+		return nil, nil, errors.NewBadRequestError("status must be pending, accepted or rejected")
+	}
+
+	requests, meta, err := s.repo.FindByStatus(params)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	return requests, &meta, nil
+}
+
+// AcceptRequest applies the proposed changes to the book. The request is claimed before the
+// book is updated so it cannot be applied twice, and goes back to the queue if the update fails.
+// A request whose fields were edited since it was made is refused, so an editor does not
+// silently overwrite a newer correction with values proposed against an older book.
+func (s *bookChangeRequestService) AcceptRequest(id uuid.UUID, req *dto.BookChangeRequestReviewRequest, actor string) (*model.BookChangeRequest, error) {
+	request, err := s.resolve(id, utils.ChangeRequestStatusAccepted, strings.TrimSpace(req.Note), actor)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.bookRepo.FindByID(request.BookID)
+	if err != nil {
+		return nil, s.reopen(id, errors.NewInternalError(err))
+	}
+	if book == nil {
+		return nil, s.reopen(id, errors.NewNotFoundError("Book not found"))
+	}
+	current, err := toFieldMap(book)
+	if err != nil {
+		return nil, s.reopen(id, errors.NewInternalError(err))
+	}
+	changed := []string{}
+	for field, value := range request.Before {
+		if current[field] != value {
+			changed = append(changed, field)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return nil, s.reopen(id, errors.NewConflictError("The book has changed since this request was made: "+strings.Join(changed, ", ")))
+	}
+
+	payload, err := json.Marshal(request.After)
+	if err != nil {
+		return nil, s.reopen(id, errors.NewInternalError(err))
+	}
+	var updateData dto.BookUpdateRequest
+	if err := json.Unmarshal(payload, &updateData); err != nil {
+		return nil, s.reopen(id, errors.NewInternalError(err))
+	}
+	if err := utils.ValidateBookUpdateRequest(&updateData); err != nil {
+		return nil, s.reopen(id, errors.NewBadRequestError(err.Error()))
+	}
+
+	updateData.Reason = "Change request " + id.String()
+	if request.ReviewerNote != "" {
+		updateData.Reason += ": " + request.ReviewerNote
+	}
+
+	if _, err := s.bookService.UpdateBook(request.BookID, &updateData, nil, actor); err != nil {
+		return nil, s.reopen(id, err)
+	}
+
+	return request, nil
+}
+
+func (s *bookChangeRequestService) RejectRequest(id uuid.UUID, req *dto.BookChangeRequestReviewRequest, actor string) (*model.BookChangeRequest, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, errors.NewBadRequestError("A note is required to reject a change request")
+	}
+
+	return s.resolve(id, utils.ChangeRequestStatusRejected, note, actor)
+}
+
+// resolve moves a pending request to status and returns it as resolved
+func (s *bookChangeRequestService) resolve(id uuid.UUID, status, note, actor string) (*model.BookChangeRequest, error) {
+	request, err := s.GetRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != utils.ChangeRequestStatusPending {
+		return nil, errors.NewConflictError("Change request is already " + request.Status)
+	}
+
+	now := time.Now().Unix()
+	resolved, err := s.repo.Resolve(id, status, note, actor, now)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if !resolved {
+		return nil, errors.NewConflictError("Change request has already been resolved")
+	}
+
+	request.Status = status
+	request.ReviewerNote = note
+	request.ReviewedBy = actor
+	request.ReviewedAt = now
+	return request, nil
+}
+
+// reopen puts the request back in the queue and returns the error that stopped it
+func (s *bookChangeRequestService) reopen(id uuid.UUID, cause error) error {
+	if err := s.repo.Reopen(id); err != nil {
+		log.Printf("Change requests: failed to reopen %s: %v", id, err)
+	}
+	return cause
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"fmt"
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"log"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+)
+
+// BookImageService manages book galleries. Gallery images are processed like covers. The
+// first front cover is the book's primary cover: it is copied to Book.Image and Book.Images
+// through BookService.UpdateBook, so clients that only know the single cover keep working.
+type BookImageService interface {
+	GetImages(bookID uuid.UUID, includeHidden bool) ([]model.BookImage, error)
+	AddImage(bookID uuid.UUID, req *dto.BookImageCreateRequest, fileHeader *multipart.FileHeader, actor string) (*model.BookImage, error)
+	UpdateImage(bookID, imageID uuid.UUID, req *dto.BookImageUpdateRequest, actor string) (*model.BookImage, error)
+	ReorderImages(bookID uuid.UUID, req *dto.BookImageOrderRequest, actor string) ([]model.BookImage, error)
+	DeleteImage(bookID, imageID uuid.UUID, actor string) error
+}
+
+type bookImageService struct {
+	repo        repository.BookImageRepository
+	store       repository.BlobStore
+	bookService BookService
+}
+
+func NewBookImageService(repo repository.BookImageRepository, store repository.BlobStore, bookService BookService) BookImageService {
+	return &bookImageService{repo, store, bookService}
+}
+
+// GetImages returns a book's gallery in order. Galleries of drafts and scheduled books are
+// only returned with includeHidden.
+func (s *bookImageService) GetImages(bookID uuid.UUID, includeHidden bool) ([]model.BookImage, error) {
+	book, err := s.bookService.GetBookByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	if !includeHidden && !utils.IsBookVisible(book.Status) {
+		return nil, errors.NewNotFoundError("Book not found")
+	}
+
+	images, err := s.repo.FindByBook(bookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return images, nil
+}
+
+func (s *bookImageService) AddImage(bookID uuid.UUID, req *dto.BookImageCreateRequest, fileHeader *multipart.FileHeader, actor string) (*model.BookImage, error) {
+	if err := utils.ValidateBookImageCreateRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if fileHeader == nil {
+		return nil, errors.NewBadRequestError("image is required")
+	}
+
+	book, err := s.bookService.GetBookByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	gallery, err := s.repo.FindByBook(bookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if len(gallery) >= utils.BookImageMaxCount {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("a book can have at most %d images", utils.BookImageMaxCount))
+	}
+
+	position := len(gallery)
+	if req.Position != nil && *req.Position < position {
+		position = *req.Position
+	}
+
+	url, metadata, err := storeCover(s.store, fileHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	// An image stored for a row that fails to save is left to the image garbage collector
+	image, err := s.repo.Create(&model.BookImage{
+		BookID:   bookID,
+		Role:     req.Role,
+		Position: position,
+		AltEn:    req.AltEn,
+		AltJa:    req.AltJa,
+		URL:      url,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	s.syncPrimary(book, gallery, actor)
+
+	return image, nil
+}
+
+func (s *bookImageService) UpdateImage(bookID, imageID uuid.UUID, req *dto.BookImageUpdateRequest, actor string) (*model.BookImage, error) {
+	if err := utils.ValidateBookImageUpdateRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	book, image, err := s.findImage(bookID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	gallery, err := s.repo.FindByBook(bookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	updates := map[string]interface{}{}
+	if req.Role != nil {
+		updates["role"] = *req.Role
+	}
+	if req.AltEn != nil {
+		updates["alt_en"] = *req.AltEn
+	}
+	if req.AltJa != nil {
+		updates["alt_ja"] = *req.AltJa
+	}
+
+	updated, err := s.repo.Update(image.ID, updates)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	if req.Role != nil && *req.Role != image.Role {
+		s.syncPrimary(book, gallery, actor)
+	}
+
+	return updated, nil
+}
+
+func (s *bookImageService) ReorderImages(bookID uuid.UUID, req *dto.BookImageOrderRequest, actor string) ([]model.BookImage, error) {
+	book, err := s.bookService.GetBookByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	gallery, err := s.repo.FindByBook(bookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	// The new order must name every image of the gallery exactly once
+	remaining := map[uuid.UUID]bool{}
+	for _, image := range gallery {
+		remaining[image.ID] = true
+	}
+	for _, id := range req.IDs {
+		if !remaining[id] {
+			return nil, errors.NewBadRequestError("ids must list every image of the book exactly once")
+		}
+		delete(remaining, id)
+	}
+	if len(remaining) > 0 {
+		return nil, errors.NewBadRequestError("ids must list every image of the book exactly once")
+	}
+
+	if err := s.repo.Reorder(bookID, req.IDs); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	s.syncPrimary(book, gallery, actor)
+
+	reordered, err := s.repo.FindByBook(bookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return reordered, nil
+}
+
+// DeleteImage removes an image from the gallery. Its files are left to the image garbage
+// collector, which keeps them while a book revision can still bring them back as the cover.
+func (s *bookImageService) DeleteImage(bookID, imageID uuid.UUID, actor string) error {
+	book, image, err := s.findImage(bookID, imageID)
+	if err != nil {
+		return err
+	}
+
+	gallery, err := s.repo.FindByBook(bookID)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	if err := s.repo.Delete(image); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	s.syncPrimary(book, gallery, actor)
+
+	return nil
+}
+
+// findImage returns a book and one of its gallery images, or a not found error
+func (s *bookImageService) findImage(bookID, imageID uuid.UUID) (*model.Book, *model.BookImage, error) {
+	book, err := s.bookService.GetBookByID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	image, err := s.repo.FindByID(imageID)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	if image == nil || image.BookID != bookID {
+		return nil, nil, errors.NewNotFoundError("Image not found")
+	}
+
+	return book, image, nil
+}
+
+// syncPrimary copies the gallery's first front cover to the book after the gallery changed.
+// Without a front cover, a primary cover that came from the previous gallery is cleared, while
+// a cover set on the book itself is kept. The gallery change is already saved, so a failure
+// is only logged; the next change syncs again.
+func (s *bookImageService) syncPrimary(book *model.Book, previous []model.BookImage, actor string) {
+	gallery, err := s.repo.FindByBook(book.ID)
+	if err != nil {
+		log.Printf("Book image service: syncing the cover of %s: %v", book.ID, err)
+		return
+	}
+
+	update := &dto.BookUpdateRequest{Reason: "Primary cover changed in the gallery"}
+	switch primary := primaryImage(gallery); {
+	case primary != nil:
+		if primary.URL == book.Image {
+			return
+		}
+		update.Image = &primary.URL
+		update.Images = primary.Metadata
+	case inGallery(previous, book.Image):
+		empty := ""
+		update.Image = &empty
+	default:
+		return
+	}
+
+	if _, err := s.bookService.UpdateBook(book.ID, update, nil, actor); err != nil {
+		log.Printf("Book image service: syncing the cover of %s: %v", book.ID, err)
+	}
+}
+
+// primaryImage returns the first front cover of an ordered gallery, or nil
+func primaryImage(gallery []model.BookImage) *model.BookImage {
+	for i := range gallery {
+		if gallery[i].Role == utils.BookImageRoleFront {
+			return &gallery[i]
+		}
+	}
+	return nil
+}
+
+func inGallery(gallery []model.BookImage, url string) bool {
+	for _, image := range gallery {
+		if url != "" && image.URL == url {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/repository"
+	"honya/backend/utils"
+)
+
+// ChallengeService issues and verifies proof-of-work challenges for anonymous submissions
+type ChallengeService interface {
+	IssueChallenge() (*dto.ChallengeResponse, error)
+	VerifySolution(challenge string, solution string) error
+	CheckSolution(challenge string, solution string) error
+	SpendChallenge(challenge string) error
+}
+
+// Spent challenges live in the database, so any instance sharing POW_SECRET can verify a
+// solution and the difficulty follows the submission rate across all of them
+type challengeService struct {
+	repo           repository.ChallengeRepository
+	enabled        bool
+	secret         []byte
+	baseDifficulty int
+	maxDifficulty  int
+}
+
+func NewChallengeService(repo repository.ChallengeRepository, enabled bool, secret string, baseDifficulty int, maxDifficulty int) ChallengeService {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+
+	return &challengeService{
+		repo:           repo,
+		enabled:        enabled,
+		secret:         key,
+		baseDifficulty: baseDifficulty,
+		maxDifficulty:  maxDifficulty,
+	}
+}
+
+func (s *challengeService) IssueChallenge() (*dto.ChallengeResponse, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	difficulty, err := s.currentDifficulty()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	expiresAt := time.Now().Add(utils.PowChallengeTTL).Unix()
+
+	payload := fmt.Sprintf("%s.%d.%d", hex.EncodeToString(nonce), difficulty, expiresAt)
+
+	return &dto.ChallengeResponse{
+		Challenge:  payload + "." + s.sign(payload),
+		Difficulty: difficulty,
+		Algorithm:  utils.PowAlgorithm,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// VerifySolution checks a solution and spends its challenge in one go
+func (s *challengeService) VerifySolution(challenge string, solution string) error {
+	if err := s.CheckSolution(challenge, solution); err != nil {
+		return err
+	}
+	return s.SpendChallenge(challenge)
+}
+
+// CheckSolution verifies the signature, expiry and work of a solution without spending the
+// challenge, so a submission rejected for another reason can retry with the same one
+func (s *challengeService) CheckSolution(challenge string, solution string) error {
+	if !s.enabled {
+		return nil
+	}
+
+	if challenge == "" || solution == "" {
+		return errors.NewBadRequestError("Proof-of-work challenge and solution are required")
+	}
+
+	_, difficulty, expiresAt, err := s.parse(challenge)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return errors.NewBadRequestError("Proof-of-work challenge has expired")
+	}
+
+	sum := sha256.Sum256([]byte(challenge + ":" + solution))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return errors.NewBadRequestError("Invalid proof-of-work solution")
+	}
+
+	return nil
+}
+
+// SpendChallenge redeems a challenge whose solution CheckSolution accepted. Each challenge
+// can only be redeemed once.
+func (s *challengeService) SpendChallenge(challenge string) error {
+	if !s.enabled {
+		return nil
+	}
+
+	nonce, _, expiresAt, err := s.parse(challenge)
+	if err != nil {
+		return err
+	}
+
+	redeemed, err := s.repo.Redeem(nonce, expiresAt)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+	if !redeemed {
+		return errors.NewBadRequestError("Proof-of-work challenge has already been used")
+	}
+
+	// Expired challenges can no longer be replayed; they are kept for the surge window so
+	// they still count towards the difficulty
+	if err := s.repo.DeleteExpired(time.Now().Add(-utils.PowSurgeWindow).Unix()); err != nil {
+		log.Printf("Failed to delete expired challenges: %v", err)
+	}
+
+	return nil
+}
+
+// parse checks the signature of a challenge and returns its nonce, difficulty and expiry
+func (s *challengeService) parse(challenge string) (string, int, int64, error) {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 4 {
+		return "", 0, 0, errors.NewBadRequestError("Malformed proof-of-work challenge")
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.sign(payload))) {
+		return "", 0, 0, errors.NewBadRequestError("Invalid proof-of-work challenge")
+	}
+
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, 0, errors.NewBadRequestError("Malformed proof-of-work challenge")
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, 0, errors.NewBadRequestError("Malformed proof-of-work challenge")
+	}
+
+	return parts[0], difficulty, expiresAt, nil
+}
+
+// currentDifficulty adds one bit of difficulty for every doubling of the
+// submission rate above the surge threshold
+func (s *challengeService) currentDifficulty() (int, error) {
+	if !s.enabled {
+		return s.baseDifficulty, nil
+	}
+
+	recent, err := s.repo.CountRedeemedSince(time.Now().Add(-utils.PowSurgeWindow).Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	difficulty := s.baseDifficulty
+	if recent > utils.PowSurgeThreshold {
+		difficulty += int(math.Log2(float64(recent) / float64(utils.PowSurgeThreshold)))
+	}
+
+	return min(difficulty, s.maxDifficulty), nil
+}
+
+func (s *challengeService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func leadingZeroBits(hash []byte) int {
+	count := 0
+	for _, b := range hash {
+		if b == 0 {
+			count += 8
+			continue
+		}
+		return count + bits.LeadingZeros8(b)
+	}
+	return count
+}
@@ -4,50 +4,244 @@ import (
 	"fmt"
 	"honya/backend/dto"
 	"honya/backend/errors"
+	"honya/backend/model"
 	"honya/backend/repository"
+	"honya/backend/utils"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type DashboardService interface {
-	GetBooksData(filterBy string) (*dto.DonutChartData, error)
+	GetBooksData(query dto.BooksDataQuery) (*dto.DonutChartData, error)
 	GetReviewsData(limit int) (*dto.BarChartData, error)
+	GetTimeSeries(query dto.TimeSeriesQuery) (*dto.TimeSeriesData, error)
+	RunQuery(req *dto.AnalyticsQueryRequest) (*dto.AnalyticsQueryResponse, error)
 }
 
 type dashboardService struct {
 	bookRepo   repository.BookRepository
 	reviewRepo repository.ReviewRepository
+	aggregator DashboardAggregator
 }
 
-func NewDashboardService(bookRepo repository.BookRepository, reviewRepo repository.ReviewRepository) DashboardService {
+func NewDashboardService(bookRepo repository.BookRepository, reviewRepo repository.ReviewRepository, aggregator DashboardAggregator) DashboardService {
 	return &dashboardService{
 		bookRepo:   bookRepo,
 		reviewRepo: reviewRepo,
+		aggregator: aggregator,
 	}
 }
 
-func (s *dashboardService) GetBooksData(filterBy string) (*dto.DonutChartData, error) {
+var booksDataDimensions = map[string]string{
+	"category":    utils.AggregateBooksByCategory,
+	"author_name": utils.AggregateBooksByAuthor,
+}
+
+var defaultBucketWidths = map[string]float64{
+	"rating":           utils.DefaultRatingBucketWidth,
+	"publication_year": 10,
+	"pages":            utils.DefaultPagesBucketWidth,
+}
+
+func (s *dashboardService) GetBooksData(query dto.BooksDataQuery) (*dto.DonutChartData, error) {
 	var field string
-	switch filterBy {
+	switch query.FilterBy {
 	case "category":
 		field = "category"
 	case "rating":
 		field = "rating"
 	case "author":
 		field = "author_name"
+	case "publication_year":
+		field = "publication_year"
+	case "pages":
+		field = "pages"
 	default:
-		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid filter_by value '%s'. Allowed values: category, rating, author", filterBy))
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid filter_by value '%s'. Allowed values: category, rating, author, publication_year, pages", query.FilterBy))
+	}
+
+	// Continuous fields are too spread out to chart raw, so they are always bucketed: by decade
+	// for years and by a default width for the others unless the caller picks one
+	bucket := query.Bucket
+	if bucket == "" {
+		switch field {
+		case "publication_year":
+			bucket = utils.BucketDecade
+		case "rating", "pages":
+			bucket = utils.BucketWidth
+		}
+	}
+	if bucket == utils.BucketWidth && query.Width == 0 {
+		query.Width = defaultBucketWidths[field]
 	}
 
-	data, err := s.bookRepo.CountByField(field)
+	if bucket == "" {
+		aggregates, state, err := s.aggregator.Counts(booksDataDimensions[field], 0)
+		if err != nil {
+			return nil, errors.NewInternalError(fmt.Errorf("failed to get donut chart data: %w", err))
+		}
+
+		data := make(map[string]int64, len(aggregates))
+		for _, aggregate := range aggregates {
+			data[aggregate.Key] = aggregate.Count
+		}
+
+		return &dto.DonutChartData{
+			FilterBy:           query.FilterBy,
+			Data:               data,
+			AggregateFreshness: s.freshness(state),
+		}, nil
+	}
+
+	if field != "rating" && field != "publication_year" && field != "pages" {
+		return nil, errors.NewBadRequestError("Bucketing is only supported for rating, publication_year and pages")
+	}
+
+	var buckets []dto.HistogramBucket
+	var err error
+	switch bucket {
+	case utils.BucketWidth:
+		buckets, err = s.bucketByWidth(field, query.Width, false)
+	case utils.BucketDecade:
+		if field != "publication_year" {
+			return nil, errors.NewBadRequestError("Decade bucketing is only supported for publication_year")
+		}
+		buckets, err = s.bucketByWidth(field, 10, true)
+	case utils.BucketEdges:
+		buckets, err = s.bucketByEdges(field, query.Edges)
+	default:
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid bucket value '%s'. Allowed values: width, edges, decade", bucket))
+	}
 	if err != nil {
-		return nil, errors.NewInternalError(fmt.Errorf("failed to get donut chart data: %w", err))
+		return nil, err
+	}
+
+	data := make(map[string]int64, len(buckets))
+	for _, b := range buckets {
+		data[b.Label] = b.Count
 	}
 
 	return &dto.DonutChartData{
-		FilterBy: filterBy,
+		FilterBy: query.FilterBy,
 		Data:     data,
+		Bucket:   bucket,
+		Buckets:  buckets,
 	}, nil
 }
 
+// bucketByWidth returns consecutive [n*width, (n+1)*width) buckets from the lowest to the highest
+// populated one, zero-filling the gaps in between
+func (s *dashboardService) bucketByWidth(field string, width float64, decade bool) ([]dto.HistogramBucket, error) {
+	if width <= 0 {
+		return nil, errors.NewBadRequestError("width must be a positive number")
+	}
+
+	counts, err := s.bookRepo.CountByWidth(field, width)
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to get histogram data: %w", err))
+	}
+
+	buckets := []dto.HistogramBucket{}
+	if len(counts) == 0 {
+		return buckets, nil
+	}
+
+	first, last := int64(math.MaxInt64), int64(math.MinInt64)
+	for index := range counts {
+		first = min(first, index)
+		last = max(last, index)
+	}
+	if last-first+1 > utils.MaxHistogramBuckets {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Too many buckets. Use a larger width to return at most %d buckets", utils.MaxHistogramBuckets))
+	}
+
+	for index := first; index <= last; index++ {
+		lower := roundBucketEdge(float64(index) * width)
+		upper := roundBucketEdge(float64(index+1) * width)
+
+		label := formatBucketEdge(lower) + "–" + formatBucketEdge(upper)
+		if decade {
+			label = formatBucketEdge(lower) + "s"
+		}
+
+		buckets = append(buckets, dto.HistogramBucket{
+			Label: label,
+			Min:   &lower,
+			Max:   &upper,
+			Count: counts[index],
+		})
+	}
+
+	return buckets, nil
+}
+
+// bucketByEdges returns one bucket per pair of consecutive edges, plus open-ended
+// buckets below the first and above the last edge when they hold any books
+func (s *dashboardService) bucketByEdges(field, rawEdges string) ([]dto.HistogramBucket, error) {
+	if rawEdges == "" {
+		return nil, errors.NewBadRequestError("edges is required for edges bucketing")
+	}
+
+	parts := strings.Split(rawEdges, ",")
+	if len(parts) > utils.MaxHistogramBuckets {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("At most %d edges are allowed", utils.MaxHistogramBuckets))
+	}
+
+	edges := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		edge, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid edge value '%s'", part))
+		}
+		if len(edges) > 0 && edge <= edges[len(edges)-1] {
+			return nil, errors.NewBadRequestError("edges must be strictly increasing")
+		}
+		edges = append(edges, edge)
+	}
+
+	counts, err := s.bookRepo.CountByEdges(field, edges)
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to get histogram data: %w", err))
+	}
+
+	buckets := []dto.HistogramBucket{}
+	if counts[0] > 0 {
+		buckets = append(buckets, dto.HistogramBucket{
+			Label: "< " + formatBucketEdge(edges[0]),
+			Max:   &edges[0],
+			Count: counts[0],
+		})
+	}
+	for i := 1; i < len(edges); i++ {
+		buckets = append(buckets, dto.HistogramBucket{
+			Label: formatBucketEdge(edges[i-1]) + "–" + formatBucketEdge(edges[i]),
+			Min:   &edges[i-1],
+			Max:   &edges[i],
+			Count: counts[int64(i)],
+		})
+	}
+	if last := len(edges) - 1; counts[int64(len(edges))] > 0 {
+		buckets = append(buckets, dto.HistogramBucket{
+			Label: "≥ " + formatBucketEdge(edges[last]),
+			Min:   &edges[last],
+			Count: counts[int64(len(edges))],
+		})
+	}
+
+	return buckets, nil
+}
+
+// roundBucketEdge hides float noise such as 0.30000000000000004 in computed edges
+func roundBucketEdge(edge float64) float64 {
+	return math.Round(edge*1e6) / 1e6
+}
+
+func formatBucketEdge(edge float64) string {
+	return strconv.FormatFloat(edge, 'f', -1, 64)
+}
+
 func (s *dashboardService) GetReviewsData(limit int) (*dto.BarChartData, error) {
 	if limit < 0 {
 		return nil, errors.NewBadRequestError("Invalid limit value")
@@ -57,12 +251,230 @@ func (s *dashboardService) GetReviewsData(limit int) (*dto.BarChartData, error)
 		limit = 10
 	}
 
-	reviewerStats, err := s.reviewRepo.GetTopReviewers(limit)
+	aggregates, state, err := s.aggregator.Counts(utils.AggregateReviewers, limit)
 	if err != nil {
 		return nil, errors.NewInternalError(fmt.Errorf("failed to get top reviewers: %w", err))
 	}
 
+	reviewerStats := make([]dto.ReviewerStats, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		reviewerStats = append(reviewerStats, dto.ReviewerStats{Name: aggregate.Key, Count: aggregate.Count})
+	}
+
 	return &dto.BarChartData{
-		Data: reviewerStats,
+		Data:               reviewerStats,
+		AggregateFreshness: s.freshness(state),
+	}, nil
+}
+
+func (s *dashboardService) freshness(state *model.DashboardAggregateState) dto.AggregateFreshness {
+	if state == nil {
+		return dto.AggregateFreshness{}
+	}
+	return dto.AggregateFreshness{
+		RefreshedAt: state.RefreshedAt,
+		StaleAfter:  s.aggregator.StaleAfter(state),
+	}
+}
+
+func (s *dashboardService) GetTimeSeries(query dto.TimeSeriesQuery) (*dto.TimeSeriesData, error) {
+	var countByInterval func(interval, timezone string, from, to int64) (map[string]int64, error)
+	switch query.Metric {
+	case utils.MetricBooksCreated:
+		countByInterval = s.bookRepo.CountByInterval
+	case utils.MetricReviewsCreated:
+		countByInterval = s.reviewRepo.CountByInterval
+	default:
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid metric value '%s'. Allowed values: books_created, reviews_created", query.Metric))
+	}
+
+	interval := query.Interval
+	if interval == "" {
+		interval = utils.DefaultTimeSeriesInterval
+	}
+	if interval != utils.IntervalDay && interval != utils.IntervalWeek && interval != utils.IntervalMonth {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid interval value '%s'. Allowed values: day, week, month", interval))
+	}
+
+	timezone := query.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid timezone '%s'", timezone))
+	}
+
+	to := time.Now().In(loc)
+	if query.To != "" {
+		if to, err = time.ParseInLocation(utils.TimeSeriesDateLayout, query.To, loc); err != nil {
+			return nil, errors.NewBadRequestError("Invalid to date. Expected format: YYYY-MM-DD")
+		}
+	}
+	end := utils.AddInterval(utils.TruncateToInterval(to, interval), interval, 1)
+
+	start := utils.AddInterval(end, interval, -utils.DefaultTimeSeriesBuckets)
+	if query.From != "" {
+		from, err := time.ParseInLocation(utils.TimeSeriesDateLayout, query.From, loc)
+		if err != nil {
+			return nil, errors.NewBadRequestError("Invalid from date. Expected format: YYYY-MM-DD")
+		}
+		start = utils.TruncateToInterval(from, interval)
+	}
+
+	if !start.Before(end) {
+		return nil, errors.NewBadRequestError("from must not be after to")
+	}
+	if utils.AddInterval(start, interval, utils.MaxTimeSeriesBuckets).Before(end) {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Date range too large. At most %d buckets can be returned", utils.MaxTimeSeriesBuckets))
+	}
+
+	counts, err := countByInterval(interval, loc.String(), start.Unix(), end.Unix())
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to get time series data: %w", err))
+	}
+
+	// Zero-fill so every bucket in the range is present, even without activity
+	points := make([]dto.TimeSeriesPoint, 0)
+	for bucket := start; bucket.Before(end); bucket = utils.AddInterval(bucket, interval, 1) {
+		key := bucket.Format(utils.TimeSeriesDateLayout)
+		points = append(points, dto.TimeSeriesPoint{
+			Bucket: key,
+			Count:  counts[key],
+		})
+	}
+
+	return &dto.TimeSeriesData{
+		Metric:   query.Metric,
+		Interval: interval,
+		Timezone: loc.String(),
+		From:     start.Format(utils.TimeSeriesDateLayout),
+		To:       utils.AddInterval(end, utils.IntervalDay, -1).Format(utils.TimeSeriesDateLayout),
+		Data:     points,
+	}, nil
+}
+
+func (s *dashboardService) RunQuery(req *dto.AnalyticsQueryRequest) (*dto.AnalyticsQueryResponse, error) {
+	if err := utils.ValidateAnalyticsQueryRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	results, err := s.bookRepo.Aggregate(req)
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to run analytics query: %w", err))
+	}
+
+	rows := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		row := make(map[string]interface{}, len(req.Dimensions)+len(req.Metrics))
+		for _, dimension := range req.Dimensions {
+			row[dimension] = analyticsLabel(result[dimension])
+		}
+		for _, metric := range req.Metrics {
+			row[metric] = analyticsValue(result[metric])
+		}
+		rows = append(rows, row)
+	}
+
+	labels, series := chartSeries(req.Dimensions, req.Metrics, rows)
+
+	return &dto.AnalyticsQueryResponse{
+		Dimensions: req.Dimensions,
+		Metrics:    req.Metrics,
+		Rows:       rows,
+		Labels:     labels,
+		Series:     series,
 	}, nil
 }
+
+// chartSeries pivots rows into labels from the first dimension and one series per metric,
+// split by the second dimension when present. Missing combinations are filled with zero.
+func chartSeries(dimensions, metrics []string, rows []map[string]interface{}) ([]string, []dto.AnalyticsSeries) {
+	label := func(row map[string]interface{}, i int) string {
+		if len(dimensions) <= i {
+			return "Total"
+		}
+		return row[dimensions[i]].(string)
+	}
+
+	labels := []string{}
+	labelIndex := map[string]int{}
+	groups := []string{}
+	groupIndex := map[string]int{}
+	for _, row := range rows {
+		if _, ok := labelIndex[label(row, 0)]; !ok {
+			labelIndex[label(row, 0)] = len(labels)
+			labels = append(labels, label(row, 0))
+		}
+		if _, ok := groupIndex[label(row, 1)]; !ok {
+			groupIndex[label(row, 1)] = len(groups)
+			groups = append(groups, label(row, 1))
+		}
+	}
+
+	series := make([]dto.AnalyticsSeries, 0, len(groups)*len(metrics))
+	for _, group := range groups {
+		for _, metric := range metrics {
+			name := metric
+			if len(dimensions) > 1 {
+				name = group
+				if len(metrics) > 1 {
+					name = fmt.Sprintf("%s (%s)", group, metric)
+				}
+			}
+			series = append(series, dto.AnalyticsSeries{
+				Name:   name,
+				Metric: metric,
+				Data:   make([]float64, len(labels)),
+			})
+		}
+	}
+
+	for _, row := range rows {
+		base := groupIndex[label(row, 1)] * len(metrics)
+		for i, metric := range metrics {
+			series[base+i].Data[labelIndex[label(row, 0)]] = row[metric].(float64)
+		}
+	}
+
+	return labels, series
+}
+
+func analyticsLabel(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "Unknown"
+	case string:
+		if v == "" {
+			return "Unknown"
+		}
+		return v
+	case []byte:
+		return analyticsLabel(string(v))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func analyticsValue(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case []byte:
+		f, _ := strconv.ParseFloat(string(v), 64)
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"log"
+	"time"
+)
+
+// DashboardAggregator keeps the precomputed dashboard counts up to date. Writes notify it
+// of the keys they touched; a scheduler rebuilds every dimension to repair any drift.
+type DashboardAggregator interface {
+	Counts(dimension string, limit int) ([]model.DashboardAggregate, *model.DashboardAggregateState, error)
+	BooksChanged(books ...*model.Book)
+	ReviewersChanged(names ...string)
+	RefreshDimension(dimension string) error
+	RefreshAll() error
+	StaleAfter(state *model.DashboardAggregateState) int64
+	StartScheduler()
+}
+
+type dashboardAggregator struct {
+	repo            repository.DashboardAggregateRepository
+	refreshInterval time.Duration
+}
+
+func NewDashboardAggregator(repo repository.DashboardAggregateRepository, refreshInterval time.Duration) DashboardAggregator {
+	return &dashboardAggregator{repo: repo, refreshInterval: refreshInterval}
+}
+
+var aggregateDimensions = []string{
+	utils.AggregateBooksByCategory,
+	utils.AggregateBooksByAuthor,
+	utils.AggregateReviewers,
+}
+
+// Counts returns the counts of a dimension, building it first if it was never refreshed
+func (a *dashboardAggregator) Counts(dimension string, limit int) ([]model.DashboardAggregate, *model.DashboardAggregateState, error) {
+	state, err := a.repo.FindState(dimension)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state == nil {
+		if err := a.repo.RefreshDimension(dimension); err != nil {
+			return nil, nil, err
+		}
+		if state, err = a.repo.FindState(dimension); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	aggregates, err := a.repo.FindByDimension(dimension, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return aggregates, state, nil
+}
+
+// BooksChanged recounts the category and author of each given book. Pass both the old and
+// the new version of an updated book so the key it moved away from is recounted too; a status
+// change is recounted the same way, since only published books count.
+func (a *dashboardAggregator) BooksChanged(books ...*model.Book) {
+	keys := map[string][]string{}
+	seen := map[string]bool{}
+	add := func(dimension, key string) {
+		if seen[dimension+"\x00"+key] {
+			return
+		}
+		seen[dimension+"\x00"+key] = true
+		keys[dimension] = append(keys[dimension], key)
+	}
+
+	for _, book := range books {
+		if book == nil {
+			continue
+		}
+		add(utils.AggregateBooksByCategory, aggregateKey(book.Category))
+		add(utils.AggregateBooksByAuthor, aggregateKey(book.AuthorName))
+	}
+
+	for dimension, dimensionKeys := range keys {
+		a.refreshKeys(dimension, dimensionKeys)
+	}
+}
+
+// ReviewersChanged recounts the published reviews of each given reviewer name
+func (a *dashboardAggregator) ReviewersChanged(names ...string) {
+	keys := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		keys = append(keys, name)
+	}
+
+	if len(keys) > 0 {
+		a.refreshKeys(utils.AggregateReviewers, keys)
+	}
+}
+
+// refreshKeys only logs failures: a stale count must never fail the write that caused it,
+// and the next scheduled refresh corrects it anyway
+func (a *dashboardAggregator) refreshKeys(dimension string, keys []string) {
+	if err := a.repo.RefreshKeys(dimension, keys); err != nil {
+		log.Printf("Dashboard aggregator: failed to refresh %s: %v", dimension, err)
+	}
+}
+
+func (a *dashboardAggregator) RefreshDimension(dimension string) error {
+	if err := a.repo.RefreshDimension(dimension); err != nil {
+		return fmt.Errorf("failed to refresh dashboard aggregate %s: %w", dimension, err)
+	}
+	return nil
+}
+
+func (a *dashboardAggregator) RefreshAll() error {
+	for _, dimension := range aggregateDimensions {
+		if err := a.RefreshDimension(dimension); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StaleAfter is when the next full refresh is due; counts may drift from the source
+// tables after that if an incremental refresh was missed
+func (a *dashboardAggregator) StaleAfter(state *model.DashboardAggregateState) int64 {
+	if state == nil {
+		return 0
+	}
+	return state.RefreshedAt + int64(a.refreshInterval/time.Second)
+}
+
+func (a *dashboardAggregator) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(a.refreshInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := a.RefreshAll(); err != nil {
+				log.Printf("Dashboard aggregator: %v", err)
+			}
+		}
+	}()
+}
+
+// aggregateKey mirrors the SQL key expression used for text dimensions
+func aggregateKey(value string) string {
+	if value == "" {
+		return utils.AggregateUnknownKey
+	}
+	return value
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataQualityService reports catalog records that editors should fix
+type DataQualityService interface {
+	GetReport() (*dto.DataQualityReport, error)
+}
+
+type dataQualityService struct {
+	repo  repository.DataQualityRepository
+	store repository.BlobStore
+}
+
+func NewDataQualityService(repo repository.DataQualityRepository, store repository.BlobStore) DataQualityService {
+	return &dataQualityService{repo: repo, store: store}
+}
+
+var bookQualityChecks = []struct {
+	check       string
+	description string
+}{
+	{utils.DataQualityMissingCover, "Books without a cover image"},
+	{utils.DataQualityMissingDescription, "Books without a description"},
+	{utils.DataQualityMissingISBN, "Books without an ISBN"},
+	{utils.DataQualitySuspiciousPages, fmt.Sprintf("Books with fewer than %d or more than %d pages", utils.DataQualityMinPages, utils.DataQualityMaxPages)},
+}
+
+func (s *dataQualityService) GetReport() (*dto.DataQualityReport, error) {
+	total, err := s.repo.CountBooks()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	report := &dto.DataQualityReport{
+		GeneratedAt: time.Now().Unix(),
+		TotalBooks:  total,
+		Checks:      []dto.DataQualityCheck{},
+	}
+
+	for _, c := range bookQualityChecks {
+		count, ids, err := s.repo.FindBooksFailing(c.check, utils.DataQualitySampleSize)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		report.Checks = append(report.Checks, dto.DataQualityCheck{
+			Check:       c.check,
+			Description: c.description,
+			Count:       count,
+			SampleIDs:   ids,
+		})
+	}
+
+	invalidIsbns, err := s.checkIsbns()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	report.Checks = append(report.Checks, *invalidIsbns)
+
+	duplicates, err := s.checkDuplicates()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	report.Checks = append(report.Checks, *duplicates)
+
+	brokenImages, err := s.checkImages()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	report.Checks = append(report.Checks, *brokenImages)
+
+	count, ids, err := s.repo.FindOrphanReviews(utils.DataQualitySampleSize)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	report.Checks = append(report.Checks, dto.DataQualityCheck{
+		Check:       utils.DataQualityOrphanReviews,
+		Description: "Reviews of books that no longer exist",
+		Count:       count,
+		SampleIDs:   ids,
+	})
+
+	return report, nil
+}
+
+func (s *dataQualityService) checkIsbns() (*dto.DataQualityCheck, error) {
+	books, err := s.repo.FindIsbns()
+	if err != nil {
+		return nil, err
+	}
+
+	check := &dto.DataQualityCheck{
+		Check:       utils.DataQualityInvalidISBN,
+		Description: "Books whose ISBN has the wrong length or check digit",
+		SampleIDs:   []uuid.UUID{},
+	}
+	for _, book := range books {
+		if utils.IsValidISBN(book.Isbn) {
+			continue
+		}
+		check.Count++
+		if len(check.SampleIDs) < utils.DataQualitySampleSize {
+			check.SampleIDs = append(check.SampleIDs, book.ID)
+		}
+	}
+
+	return check, nil
+}
+
+func (s *dataQualityService) checkDuplicates() (*dto.DataQualityCheck, error) {
+	groups, err := s.repo.FindDuplicateGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	check := &dto.DataQualityCheck{
+		Check:        utils.DataQualityDuplicateBooks,
+		Description:  "Books sharing a title and author with another book",
+		SampleIDs:    []uuid.UUID{},
+		SampleGroups: [][]uuid.UUID{},
+	}
+	for _, group := range groups {
+		check.Count += int64(len(group))
+		if len(check.SampleGroups) < utils.DataQualitySampleSize {
+			check.SampleGroups = append(check.SampleGroups, group)
+		}
+		for _, id := range group {
+			if len(check.SampleIDs) < utils.DataQualitySampleSize {
+				check.SampleIDs = append(check.SampleIDs, id)
+			}
+		}
+	}
+
+	return check, nil
+}
+
+// checkImages compares the covers stored in our bucket against one listing of the folders
+// they live in, rather than looking each of them up. External image URLs are not checked, and
+// a storage failure is reported on the check instead of failing the report.
+func (s *dataQualityService) checkImages() (*dto.DataQualityCheck, error) {
+	check := &dto.DataQualityCheck{
+		Check:       utils.DataQualityBrokenImages,
+		Description: "Books whose cover points to a missing storage object",
+		SampleIDs:   []uuid.UUID{},
+	}
+
+	if s.store == nil {
+		check.Error = "image storage is not configured"
+		return check, nil
+	}
+
+	books, err := s.repo.FindImages()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[uuid.UUID]string{}
+	prefixes := []string{}
+	for _, book := range books {
+		key, ok := s.store.Key(book.Image)
+		if !ok {
+			continue
+		}
+		keys[book.ID] = key
+		prefix := key[:strings.LastIndex(key, "/")+1]
+		if !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	stored := map[string]bool{}
+	for _, prefix := range prefixes {
+		objects, err := s.store.List(prefix)
+		if err != nil {
+			check.Error = fmt.Sprintf("failed to list stored images: %v", err)
+			return check, nil
+		}
+		for _, object := range objects {
+			stored[object.Key] = true
+		}
+	}
+
+	for _, book := range books {
+		key, ok := keys[book.ID]
+		if !ok || stored[key] {
+			continue
+		}
+		check.Count++
+		if len(check.SampleIDs) < utils.DataQualitySampleSize {
+			check.SampleIDs = append(check.SampleIDs, book.ID)
+		}
+	}
+
+	return check, nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+)
+
+// GdprService answers data-subject access and erasure requests
+type GdprService interface {
+	ExportByEmail(email string, actor string) (*dto.DataSubjectExportResponse, error)
+	EraseByEmail(req *dto.DataSubjectErasureRequest, actor string) (*dto.DataSubjectErasureResponse, error)
+	GetRequests(params dto.QueryParams) ([]model.DataSubjectRequest, *dto.PaginationMeta, error)
+}
+
+type gdprService struct {
+	reviewRepo  repository.ReviewRepository
+	requestRepo repository.DataSubjectRequestRepository
+	changeRepo  repository.BookChangeRequestRepository
+	keepContent bool
+	hashKey     []byte
+	aggregator  DashboardAggregator
+}
+
+func NewGdprService(reviewRepo repository.ReviewRepository, requestRepo repository.DataSubjectRequestRepository, changeRepo repository.BookChangeRequestRepository, keepContent bool, hashSecret string, aggregator DashboardAggregator) GdprService {
+	return &gdprService{
+		reviewRepo:  reviewRepo,
+		requestRepo: requestRepo,
+		changeRepo:  changeRepo,
+		keepContent: keepContent,
+		hashKey:     []byte(hashSecret),
+		aggregator:  aggregator,
+	}
+}
+
+func (s *gdprService) ExportByEmail(email string, actor string) (*dto.DataSubjectExportResponse, error) {
+	if err := utils.ValidateDataSubjectEmail(email); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	reviews, err := s.reviewRepo.FindByEmail(email)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	changeRequests, err := s.changeRepo.FindByEmail(email)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	if _, err := s.requestRepo.Create(&model.DataSubjectRequest{
+		Type:                   utils.DataSubjectRequestExport,
+		EmailHMAC:              utils.HashEmail(s.hashKey, email),
+		Actor:                  actor,
+		ReviewsAffected:        int64(len(reviews)),
+		ChangeRequestsAffected: int64(len(changeRequests)),
+	}); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	// The archive goes back to the data subject, so it always includes their email
+	archive := dto.ToReviewListResponse(reviews, dto.PaginationMeta{}, true)
+	changeArchive := dto.ToBookChangeRequestListResponse(changeRequests, dto.PaginationMeta{}, true)
+
+	return &dto.DataSubjectExportResponse{
+		Email:          email,
+		GeneratedAt:    time.Now().Unix(),
+		ReviewCount:    len(reviews),
+		Reviews:        archive.Data,
+		ChangeRequests: changeArchive.Data,
+	}, nil
+}
+
+func (s *gdprService) EraseByEmail(req *dto.DataSubjectErasureRequest, actor string) (*dto.DataSubjectErasureResponse, error) {
+	if err := utils.ValidateDataSubjectEmail(req.Email); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	keepContent := s.keepContent
+	if req.KeepContent != nil {
+		keepContent = *req.KeepContent
+	}
+
+	// The names are gone after anonymization, so collect them first to recount their reviewers
+	reviews, err := s.reviewRepo.FindByEmail(req.Email)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	affected, err := s.reviewRepo.AnonymizeByEmail(req.Email, keepContent)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	names := make([]string, 0, len(reviews))
+	for _, review := range reviews {
+		names = append(names, review.Name)
+	}
+	s.aggregator.ReviewersChanged(names...)
+
+	// Change requests only hold the proposer's name and email; the proposed edits stay
+	changesAffected, err := s.changeRepo.AnonymizeByEmail(req.Email)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	record, err := s.requestRepo.Create(&model.DataSubjectRequest{
+		Type:                   utils.DataSubjectRequestErase,
+		EmailHMAC:              utils.HashEmail(s.hashKey, req.Email),
+		Actor:                  actor,
+		KeepContent:            keepContent,
+		ReviewsAffected:        affected,
+		ChangeRequestsAffected: changesAffected,
+	})
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	return &dto.DataSubjectErasureResponse{
+		RequestID:              record.ID.String(),
+		ReviewsAffected:        affected,
+		ChangeRequestsAffected: changesAffected,
+		KeepContent:            keepContent,
+	}, nil
+}
+
+func (s *gdprService) GetRequests(params dto.QueryParams) ([]model.DataSubjectRequest, *dto.PaginationMeta, error) {
+	// Emails are only stored hashed, so hash them before filtering
+	if strings.Contains(params.Query, "@") {
+		params.Query = utils.HashEmail(s.hashKey, params.Query)
+	}
+
+	requests, meta, err := s.requestRepo.FindAll(params)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	return requests, &meta, nil
+}
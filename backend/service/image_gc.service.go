@@ -0,0 +1,229 @@
+package service
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"log"
+	"time"
+)
+
+// ImageGCService deletes covers and staged uploads that no row refers to any more, such as
+// covers stored for a book whose save then failed, or covers whose best-effort delete did
+// not go through. Blobs younger than the grace period are left alone, and deletions that fail
+// are queued and retried on later runs with a growing delay.
+type ImageGCService interface {
+	Collect(now time.Time, dryRun bool) (*dto.ImageGCReport, error)
+	CollectImages(now time.Time, urls []string, dryRun bool) (*dto.ImageGCReport, error)
+	StartCollector(interval time.Duration, dryRun bool)
+}
+
+type imageGCService struct {
+	repo        repository.ImageGCRepository
+	store       repository.BlobStore
+	gracePeriod time.Duration
+	// Covers only older revisions refer to are collected; restoring such a revision keeps
+	// the book's current cover
+	revisionRetention time.Duration
+}
+
+func NewImageGCService(repo repository.ImageGCRepository, store repository.BlobStore, gracePeriod, revisionRetention time.Duration) ImageGCService {
+	return &imageGCService{repo, store, gracePeriod, revisionRetention}
+}
+
+func (s *imageGCService) Collect(now time.Time, dryRun bool) (*dto.ImageGCReport, error) {
+	// Read the references before listing, so anything stored in between is younger than the
+	// grace period rather than mistaken for an orphan
+	referenced, queued, err := s.prepare(now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ImageGCReport{DryRun: dryRun, Orphans: []dto.ImageGCOrphan{}}
+	listed := map[string]bool{}
+
+	for _, prefix := range []string{utils.CoverKeyPrefix, utils.UploadKeyPrefix} {
+		objects, err := s.store.List(prefix)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+
+		for _, object := range objects {
+			listed[object.Key] = true
+			s.sweep(object, referenced, queued, now, dryRun, report)
+		}
+	}
+
+	// Queued blobs that were not listed may already be gone; deleting them again settles it
+	if !dryRun {
+		for key := range queued {
+			if listed[key] {
+				continue
+			}
+			if referenced[key] {
+				s.dequeue(key, queued)
+				continue
+			}
+			s.delete(key, queued, now, report)
+		}
+	}
+
+	report.PendingRetries = len(queued)
+	return report, nil
+}
+
+// CollectImages does what Collect does for the given images only, so that the files of a
+// purged book go with it rather than on the next run. Images that are still referenced or
+// were touched within the grace period are kept.
+func (s *imageGCService) CollectImages(now time.Time, urls []string, dryRun bool) (*dto.ImageGCReport, error) {
+	referenced, queued, err := s.prepare(now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ImageGCReport{DryRun: dryRun, Orphans: []dto.ImageGCOrphan{}}
+	seen := map[string]bool{}
+
+	for _, url := range urls {
+		key, ok := s.store.Key(url)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		// Listing by the key as a prefix is how stores report a blob's age
+		objects, err := s.store.List(key)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		for _, object := range objects {
+			if object.Key == key {
+				s.sweep(object, referenced, queued, now, dryRun, report)
+			}
+		}
+	}
+
+	report.PendingRetries = len(queued)
+	return report, nil
+}
+
+// prepare returns the keys rows refer to and the queued deletions by key
+func (s *imageGCService) prepare(now time.Time) (map[string]bool, map[string]*model.BlobDeletion, error) {
+	urls, err := s.repo.FindReferencedImages(now.Add(-s.revisionRetention).Unix())
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	referenced := map[string]bool{}
+	for _, url := range urls {
+		if key, ok := s.store.Key(url); ok {
+			referenced[key] = true
+		}
+	}
+
+	deletions, err := s.repo.FindDeletions()
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	queued := map[string]*model.BlobDeletion{}
+	for i := range deletions {
+		queued[deletions[i].Key] = &deletions[i]
+	}
+
+	return referenced, queued, nil
+}
+
+// sweep counts a listed blob in the report and deletes it when it is an orphan past the
+// grace period
+func (s *imageGCService) sweep(object repository.BlobObject, referenced map[string]bool, queued map[string]*model.BlobDeletion, now time.Time, dryRun bool, report *dto.ImageGCReport) {
+	report.Scanned++
+
+	switch {
+	case referenced[object.Key]:
+		report.Referenced++
+		if !dryRun && queued[object.Key] != nil {
+			s.dequeue(object.Key, queued)
+		}
+	case object.ModifiedAt.After(now.Add(-s.gracePeriod)):
+		report.Recent++
+	default:
+		report.Orphans = append(report.Orphans, dto.ImageGCOrphan{
+			Key:        object.Key,
+			URL:        s.store.URL(object.Key),
+			Size:       object.Size,
+			ModifiedAt: object.ModifiedAt.Unix(),
+		})
+		if !dryRun {
+			s.delete(object.Key, queued, now, report)
+		}
+	}
+}
+
+// delete removes an orphan unless its queued retry is not due yet. A failed delete is queued,
+// or its retry pushed back.
+func (s *imageGCService) delete(key string, queued map[string]*model.BlobDeletion, now time.Time, report *dto.ImageGCReport) {
+	deletion := queued[key]
+	if deletion != nil && deletion.NextAttemptAt > now.Unix() {
+		return
+	}
+
+	if err := s.store.Delete(key); err != nil {
+		report.Failed++
+		if deletion == nil {
+			deletion = &model.BlobDeletion{Key: key}
+			queued[key] = deletion
+		}
+		deletion.Attempts++
+		deletion.LastError = err.Error()
+		deletion.NextAttemptAt = now.Add(retryDelay(deletion.Attempts)).Unix()
+		if err := s.repo.SaveDeletion(deletion); err != nil {
+			log.Printf("Image GC: queueing %s: %v", key, err)
+		}
+		return
+	}
+
+	report.Deleted++
+	if deletion != nil {
+		report.Retried++
+		s.dequeue(key, queued)
+	}
+}
+
+func (s *imageGCService) dequeue(key string, queued map[string]*model.BlobDeletion) {
+	if err := s.repo.RemoveDeletion(key); err != nil {
+		log.Printf("Image GC: dequeueing %s: %v", key, err)
+		return
+	}
+	delete(queued, key)
+}
+
+// retryDelay doubles the wait with every failed attempt, up to a day
+func retryDelay(attempts int) time.Duration {
+	delay := utils.ImageGCRetryDelay
+	for i := 1; i < attempts && delay < utils.ImageGCMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, utils.ImageGCMaxRetryDelay)
+}
+
+func (s *imageGCService) StartCollector(interval time.Duration, dryRun bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			report, err := s.Collect(now, dryRun)
+			if err != nil {
+				log.Printf("Image GC: %v", err)
+				continue
+			}
+			if dryRun && len(report.Orphans) > 0 {
+				log.Printf("Image GC: found %d orphaned blobs (dry run)", len(report.Orphans))
+			}
+			if report.Deleted > 0 || report.Failed > 0 {
+				log.Printf("Image GC: deleted %d orphaned blobs, %d failed and are queued for a retry", report.Deleted, report.Failed)
+			}
+		}
+	}()
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ModerationService handles reader reports and the moderation queue
+type ModerationService interface {
+	ReportReview(reviewID uuid.UUID, req *dto.ReviewReportRequest, clientIP string) (*dto.ReviewReportResponse, error)
+	GetQueue(params dto.QueryParams) ([]dto.ModerationQueueItem, *dto.PaginationMeta, error)
+	ApproveReview(reviewID uuid.UUID) (*model.Review, error)
+	RemoveReview(reviewID uuid.UUID) error
+}
+
+type moderationService struct {
+	reviewRepo      repository.ReviewRepository
+	reportRepo      repository.ReviewReportRepository
+	reportThreshold int64
+	// Keys the reporter hashes, which are otherwise reversible to the reporter's IP
+	hashKey    []byte
+	aggregator DashboardAggregator
+}
+
+func NewModerationService(reviewRepo repository.ReviewRepository, reportRepo repository.ReviewReportRepository, reportThreshold int, hashSecret string, aggregator DashboardAggregator) ModerationService {
+	return &moderationService{
+		reviewRepo:      reviewRepo,
+		reportRepo:      reportRepo,
+		reportThreshold: int64(reportThreshold),
+		hashKey:         []byte(hashSecret),
+		aggregator:      aggregator,
+	}
+}
+
+func (s *moderationService) ReportReview(reviewID uuid.UUID, req *dto.ReviewReportRequest, clientIP string) (*dto.ReviewReportResponse, error) {
+	if err := utils.ValidateReviewReportRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	review, err := s.reviewRepo.FindByID(reviewID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if review == nil {
+		return nil, errors.NewNotFoundError("Review not found")
+	}
+
+	// Reporters are told apart by IP; an email would cost nothing to vary. There are few
+	// enough IPv4 addresses to try them all, so the digest is keyed.
+	reporterHash := utils.KeyedHash(s.hashKey, "ip:"+clientIP)
+
+	exists, err := s.reportRepo.ExistsForReporter(reviewID, reporterHash)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if exists {
+		return nil, errors.NewConflictError("You have already reported this review")
+	}
+
+	if _, err := s.reportRepo.Create(&model.ReviewReport{
+		ReviewID:     reviewID,
+		ReporterHash: reporterHash,
+		Reason:       req.Reason,
+		Details:      req.Details,
+	}); err != nil {
+		if utils.IsUniqueViolation(err, utils.ReviewReportUniqueConstraint) {
+			return nil, errors.NewConflictError("You have already reported this review")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	count, err := s.reportRepo.CountByReviewID(reviewID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	updates := map[string]interface{}{"report_count": count}
+	if s.reportThreshold > 0 && count >= s.reportThreshold {
+		updates["status"] = utils.ReviewStatusHidden
+	}
+
+	updated, err := s.reviewRepo.Update(reviewID, updates)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	// Hidden reviews no longer count towards their reviewer
+	if _, hidden := updates["status"]; hidden {
+		s.aggregator.ReviewersChanged(updated.Name)
+	}
+
+	return &dto.ReviewReportResponse{
+		ReviewID:    reviewID,
+		Reason:      req.Reason,
+		ReportCount: updated.ReportCount,
+		Hidden:      updated.Status == utils.ReviewStatusHidden,
+	}, nil
+}
+
+func (s *moderationService) GetQueue(params dto.QueryParams) ([]dto.ModerationQueueItem, *dto.PaginationMeta, error) {
+	reviews, meta, err := s.reviewRepo.FindModerationQueue(params)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+
+	reviewIDs := make([]uuid.UUID, 0, len(reviews))
+	for _, review := range reviews {
+		reviewIDs = append(reviewIDs, review.ID)
+	}
+
+	reasons, err := s.reportRepo.CountReasonsByReviewIDs(reviewIDs)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+
+	renderMissingHTML(reviews)
+
+	items := make([]dto.ModerationQueueItem, 0, len(reviews))
+	for _, review := range reviews {
+		reviewReasons := reasons[review.ID]
+		if reviewReasons == nil {
+			reviewReasons = map[string]int64{}
+		}
+
+		items = append(items, dto.ModerationQueueItem{
+			Review:      *dto.ToReviewResponse(&review, true),
+			ReportCount: review.ReportCount,
+			Reasons:     reviewReasons,
+		})
+	}
+
+	return items, &meta, nil
+}
+
+// ApproveReview republishes a review and marks its reports as resolved
+func (s *moderationService) ApproveReview(reviewID uuid.UUID) (*model.Review, error) {
+	review, err := s.reviewRepo.FindByID(reviewID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if review == nil {
+		return nil, errors.NewNotFoundError("Review not found")
+	}
+
+	if err := s.reportRepo.ResolveByReviewID(reviewID, time.Now().Unix()); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	updated, err := s.reviewRepo.Update(reviewID, map[string]interface{}{
+		"status":       utils.ReviewStatusPublished,
+		"report_count": 0,
+	})
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	s.aggregator.ReviewersChanged(updated.Name)
+
+	return updated, nil
+}
+
+// RemoveReview moves a reported review to the trash
+func (s *moderationService) RemoveReview(reviewID uuid.UUID) error {
+	review, err := s.reviewRepo.FindByID(reviewID)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+	if review == nil {
+		return errors.NewNotFoundError("Review not found")
+	}
+
+	if err := s.reviewRepo.Delete(reviewID); err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	s.aggregator.ReviewersChanged(review.Name)
+
+	return nil
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+
+	"github.com/google/uuid"
+)
+
+// ReportService manages scheduled dashboard reports and delivers them by email
+type ReportService interface {
+	GetReports(params dto.QueryParams) ([]model.ScheduledReport, *dto.PaginationMeta, error)
+	GetReportByID(id uuid.UUID) (*model.ScheduledReport, error)
+	CreateReport(req *dto.ScheduledReportCreateRequest) (*model.ScheduledReport, error)
+	UpdateReport(id uuid.UUID, req *dto.ScheduledReportUpdateRequest) (*model.ScheduledReport, error)
+	DeleteReport(id uuid.UUID) error
+	SendNow(id uuid.UUID) (*model.ReportRun, error)
+	GetRuns(id uuid.UUID, params dto.QueryParams) ([]model.ReportRun, *dto.PaginationMeta, error)
+	RunDueReports(now time.Time)
+	StartScheduler(interval time.Duration)
+}
+
+type reportService struct {
+	reportRepo repository.ScheduledReportRepository
+	runRepo    repository.ReportRunRepository
+	dashboard  DashboardService
+	mailer     repository.MailerRepository
+}
+
+func NewReportService(reportRepo repository.ScheduledReportRepository, runRepo repository.ReportRunRepository, dashboard DashboardService, mailer repository.MailerRepository) ReportService {
+	return &reportService{
+		reportRepo: reportRepo,
+		runRepo:    runRepo,
+		dashboard:  dashboard,
+		mailer:     mailer,
+	}
+}
+
+func (s *reportService) GetReports(params dto.QueryParams) ([]model.ScheduledReport, *dto.PaginationMeta, error) {
+	reports, meta, err := s.reportRepo.FindAll(params)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	return reports, &meta, nil
+}
+
+func (s *reportService) GetReportByID(id uuid.UUID) (*model.ScheduledReport, error) {
+	report, err := s.reportRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if report == nil {
+		return nil, errors.NewNotFoundError("Report not found")
+	}
+	return report, nil
+}
+
+func (s *reportService) CreateReport(req *dto.ScheduledReportCreateRequest) (*model.ScheduledReport, error) {
+	if err := utils.ValidateScheduledReportCreateRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	report := &model.ScheduledReport{
+		Name:       strings.TrimSpace(req.Name),
+		Cron:       req.Cron,
+		Timezone:   req.Timezone,
+		Recipients: utils.NormalizeReportRecipients(req.Recipients),
+		Charts:     req.Charts,
+		Format:     req.Format,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if report.Format == "" {
+		report.Format = utils.ExportFormatCSV
+	}
+
+	nextRunAt, err := computeNextRunAt(report)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	report.NextRunAt = nextRunAt
+
+	created, err := s.reportRepo.Create(report)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return created, nil
+}
+
+func (s *reportService) UpdateReport(id uuid.UUID, req *dto.ScheduledReportUpdateRequest) (*model.ScheduledReport, error) {
+	if err := utils.ValidateScheduledReportUpdateRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	report, err := s.GetReportByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Cron != nil {
+		report.Cron = *req.Cron
+		updates["cron"] = *req.Cron
+	}
+	if req.Timezone != nil {
+		report.Timezone = *req.Timezone
+		updates["timezone"] = *req.Timezone
+	}
+	// Map updates skip the model's JSON serializer, so list columns are encoded here
+	if req.Recipients != nil {
+		recipients, _ := json.Marshal(utils.NormalizeReportRecipients(*req.Recipients))
+		updates["recipients"] = string(recipients)
+	}
+	if req.Charts != nil {
+		charts, _ := json.Marshal(*req.Charts)
+		updates["charts"] = string(charts)
+	}
+	if req.Format != nil {
+		updates["format"] = *req.Format
+	}
+	if req.Enabled != nil {
+		report.Enabled = *req.Enabled
+		updates["enabled"] = *req.Enabled
+	}
+
+	if req.Cron != nil || req.Timezone != nil || req.Enabled != nil {
+		nextRunAt, err := computeNextRunAt(report)
+		if err != nil {
+			return nil, errors.NewBadRequestError(err.Error())
+		}
+		updates["next_run_at"] = nextRunAt
+	}
+
+	if len(updates) == 0 {
+		return report, nil
+	}
+
+	updated, err := s.reportRepo.Update(id, updates)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return updated, nil
+}
+
+func (s *reportService) DeleteReport(id uuid.UUID) error {
+	if _, err := s.GetReportByID(id); err != nil {
+		return err
+	}
+	if err := s.reportRepo.Delete(id); err != nil {
+		return errors.NewInternalError(err)
+	}
+	return nil
+}
+
+// SendNow delivers a report immediately, regardless of its schedule. A failed delivery is
+// still recorded and returned as a run with status "failed".
+func (s *reportService) SendNow(id uuid.UUID) (*model.ReportRun, error) {
+	report, err := s.GetReportByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.deliver(report, utils.ReportTriggerManual)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return run, nil
+}
+
+func (s *reportService) GetRuns(id uuid.UUID, params dto.QueryParams) ([]model.ReportRun, *dto.PaginationMeta, error) {
+	if _, err := s.GetReportByID(id); err != nil {
+		return nil, nil, err
+	}
+
+	runs, meta, err := s.runRepo.FindByReportID(id, params)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	return runs, &meta, nil
+}
+
+// RunDueReports sends every enabled report whose next run time has passed
+func (s *reportService) RunDueReports(now time.Time) {
+	reports, err := s.reportRepo.FindDue(now.Unix())
+	if err != nil {
+		log.Printf("Report scheduler: failed to load due reports: %v", err)
+		return
+	}
+
+	for i := range reports {
+		report := &reports[i]
+
+		next, err := utils.NextReportRun(report.Cron, report.Timezone, now)
+		if err != nil {
+			log.Printf("Report scheduler: skipping report %s: %v", report.ID, err)
+			continue
+		}
+
+		// Claiming first means a slow or failing delivery is not retried every tick
+		claimed, err := s.reportRepo.ClaimRun(report.ID, *report.NextRunAt, next.Unix())
+		if err != nil {
+			log.Printf("Report scheduler: failed to claim report %s: %v", report.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if _, err := s.deliver(report, utils.ReportTriggerSchedule); err != nil {
+			log.Printf("Report scheduler: failed to record run of report %s: %v", report.ID, err)
+		}
+	}
+}
+
+func (s *reportService) StartScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			s.RunDueReports(now)
+		}
+	}()
+}
+
+// deliver renders and emails a report, then records the run. The returned error is only set
+// when the run itself could not be stored; delivery failures are kept on the run.
+func (s *reportService) deliver(report *model.ScheduledReport, trigger string) (*model.ReportRun, error) {
+	startedAt := time.Now()
+	run := &model.ReportRun{
+		ReportID:   report.ID,
+		Trigger:    trigger,
+		Status:     utils.ReportRunStatusSent,
+		Recipients: len(report.Recipients),
+		StartedAt:  startedAt.Unix(),
+	}
+
+	if err := s.send(report, startedAt); err != nil {
+		run.Status = utils.ReportRunStatusFailed
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now().Unix()
+
+	created, err := s.runRepo.Create(run)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.reportRepo.Update(report.ID, map[string]interface{}{"last_run_at": run.StartedAt}); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *reportService) send(report *model.ScheduledReport, generatedAt time.Time) error {
+	tables := make([]dto.Table, 0, len(report.Charts))
+	for _, chart := range report.Charts {
+		table, err := s.chartTable(chart, report.Timezone)
+		if err != nil {
+			return fmt.Errorf("failed to build chart %s: %w", chart, err)
+		}
+		tables = append(tables, table)
+	}
+
+	if loc, err := time.LoadLocation(report.Timezone); err == nil {
+		generatedAt = generatedAt.In(loc)
+	}
+
+	html, text, err := utils.RenderReportEmail(report.Name, generatedAt, tables)
+	if err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	attachments, err := reportAttachments(report, tables, generatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to export report: %w", err)
+	}
+
+	return s.mailer.Send(&dto.EmailMessage{
+		To:          report.Recipients,
+		Subject:     fmt.Sprintf("Honya report: %s", report.Name),
+		HTML:        html,
+		Text:        text,
+		Attachments: attachments,
+	})
+}
+
+func (s *reportService) chartTable(chart, timezone string) (dto.Table, error) {
+	var data dto.Tabular
+	var err error
+
+	switch chart {
+	case utils.ReportChartBooksByCategory:
+		data, err = s.dashboard.GetBooksData(dto.BooksDataQuery{FilterBy: "category"})
+	case utils.ReportChartBooksByRating:
+		data, err = s.dashboard.GetBooksData(dto.BooksDataQuery{FilterBy: "rating"})
+	case utils.ReportChartBooksByAuthor:
+		data, err = s.dashboard.GetBooksData(dto.BooksDataQuery{FilterBy: "author"})
+	case utils.ReportChartTopReviewers:
+		data, err = s.dashboard.GetReviewsData(utils.DefaultLimit)
+	case utils.ReportChartBooksCreated:
+		data, err = s.dashboard.GetTimeSeries(dto.TimeSeriesQuery{Metric: utils.MetricBooksCreated, Timezone: timezone})
+	case utils.ReportChartReviewsCreated:
+		data, err = s.dashboard.GetTimeSeries(dto.TimeSeriesQuery{Metric: utils.MetricReviewsCreated, Timezone: timezone})
+	default:
+		return dto.Table{}, fmt.Errorf("unknown chart %s", chart)
+	}
+	if err != nil {
+		return dto.Table{}, err
+	}
+
+	return data.Table(), nil
+}
+
+func reportAttachments(report *model.ScheduledReport, tables []dto.Table, generatedAt time.Time) ([]dto.EmailAttachment, error) {
+	date := generatedAt.Format("20060102")
+
+	if report.Format == utils.ExportFormatXLSX {
+		data, err := utils.WriteXLSX(tables...)
+		if err != nil {
+			return nil, err
+		}
+		return []dto.EmailAttachment{{
+			Filename:    fmt.Sprintf("%s-%s.xlsx", utils.Slugify(report.Name), date),
+			ContentType: utils.ContentTypeXLSX,
+			Data:        data,
+		}}, nil
+	}
+
+	attachments := make([]dto.EmailAttachment, 0, len(tables))
+	for i, table := range tables {
+		data, err := utils.WriteCSV(table)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, dto.EmailAttachment{
+			Filename:    fmt.Sprintf("%s-%s.csv", report.Charts[i], date),
+			ContentType: utils.ContentTypeCSV,
+			Data:        data,
+		})
+	}
+	return attachments, nil
+}
+
+// computeNextRunAt returns when an enabled report should next run, or nil when it is disabled
+func computeNextRunAt(report *model.ScheduledReport) (*int64, error) {
+	if !report.Enabled {
+		return nil, nil
+	}
+
+	next, err := utils.NextReportRun(report.Cron, report.Timezone, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	unix := next.Unix()
+	return &unix, nil
+}
@@ -1,11 +1,13 @@
 package service
 
 import (
+	"fmt"
 	"honya/backend/dto"
 	"honya/backend/errors"
 	"honya/backend/model"
 	"honya/backend/repository"
 	"honya/backend/utils"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,19 +16,31 @@ import (
 type ReviewService interface {
 	GetAllReviews(params dto.QueryParams) ([]model.Review, *dto.PaginationMeta, error)
 	GetReviewByID(id uuid.UUID) (*model.Review, error)
-	CreateReview(req *dto.ReviewCreateRequest) (*model.Review, error)
+	CreateReview(req *dto.ReviewCreateRequest, clientIP string) (*model.Review, error)
 	UpdateReview(id uuid.UUID, req *dto.ReviewUpdateRequest) (*model.Review, error)
 	DeleteReview(id uuid.UUID) error
 	FindByBookID(bookID uuid.UUID, params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error)
 	GetReviewsByBookID(bookID uuid.UUID, params dto.QueryParams) ([]model.Review, *dto.PaginationMeta, error)
 }
 
+// ReviewLimits configures how often a single reviewer may post.
+// A zero MaxPerWindow disables the per-email limit.
+type ReviewLimits struct {
+	IgnoreGmailDots bool
+	MaxPerWindow    int
+	Window          time.Duration
+}
+
 type reviewService struct {
-	repo repository.ReviewRepository
+	repo       repository.ReviewRepository
+	bookRepo   repository.BookRepository
+	challenges ChallengeService
+	limits     ReviewLimits
+	aggregator DashboardAggregator
 }
 
-func NewReviewService(repo repository.ReviewRepository) ReviewService {
-	return &reviewService{repo: repo}
+func NewReviewService(repo repository.ReviewRepository, bookRepo repository.BookRepository, challenges ChallengeService, limits ReviewLimits, aggregator DashboardAggregator) ReviewService {
+	return &reviewService{repo: repo, bookRepo: bookRepo, challenges: challenges, limits: limits, aggregator: aggregator}
 }
 
 func (s *reviewService) FindByBookID(bookID uuid.UUID, params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error) {
@@ -34,6 +48,7 @@ func (s *reviewService) FindByBookID(bookID uuid.UUID, params dto.QueryParams) (
 	if err != nil {
 		return nil, dto.PaginationMeta{}, errors.NewInternalError(err)
 	}
+	renderMissingHTML(reviews)
 	return reviews, meta, nil
 }
 
@@ -42,6 +57,7 @@ func (s *reviewService) GetAllReviews(params dto.QueryParams) ([]model.Review, *
 	if err != nil {
 		return nil, nil, errors.NewInternalError(err)
 	}
+	renderMissingHTML(reviews)
 	return reviews, &meta, nil
 }
 
@@ -53,29 +69,93 @@ func (s *reviewService) GetReviewByID(id uuid.UUID) (*model.Review, error) {
 	if review == nil {
 		return nil, errors.NewNotFoundError("Review not found")
 	}
+	if review.ContentHTML == "" {
+		review.ContentHTML = utils.RenderMarkdown(review.Content)
+	}
 	return review, nil
 }
 
-func (s *reviewService) CreateReview(req *dto.ReviewCreateRequest) (*model.Review, error) {
+// CreateReview spends the proof-of-work challenge only once the review is about to be saved,
+// so a rejected submission can be fixed and sent again with the same solution
+func (s *reviewService) CreateReview(req *dto.ReviewCreateRequest, clientIP string) (*model.Review, error) {
+	if err := s.challenges.CheckSolution(req.PowChallenge, req.PowSolution); err != nil {
+		return nil, err
+	}
+
 	if err := utils.ValidateReviewCreateRequest(req); err != nil {
 		return nil, errors.NewBadRequestError(err.Error())
 	}
 
+	book, err := s.bookRepo.FindByID(req.BookID)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if book == nil || !utils.IsBookVisible(book.Status) {
+		return nil, errors.NewNotFoundError("Book not found")
+	}
+	if book.Status == utils.BookStatusArchived {
+		return nil, errors.NewBadRequestError("This book is archived and no longer accepts reviews")
+	}
+
+	normalizedEmail := utils.NormalizeEmail(req.Email, s.limits.IgnoreGmailDots)
+
+	exists, err := s.repo.ExistsByBookAndEmail(req.BookID, normalizedEmail)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	if exists {
+		return nil, errors.NewConflictError("You have already reviewed this book")
+	}
+
+	if s.limits.MaxPerWindow > 0 {
+		since := time.Now().Add(-s.limits.Window).Unix()
+		count, err := s.repo.CountByEmailSince(normalizedEmail, since)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		if count >= int64(s.limits.MaxPerWindow) {
+			return nil, errors.NewTooManyRequestsError(fmt.Sprintf("You can post at most %d reviews every %s", s.limits.MaxPerWindow, s.limits.Window))
+		}
+	}
+
 	review := model.Review{
-		BookID:  req.BookID,
-		Name:    req.Name,
-		Email:   req.Email,
-		Content: req.Content,
+		BookID:          req.BookID,
+		Name:            req.Name,
+		Email:           req.Email,
+		EmailNormalized: normalizedEmail,
+		Content:         req.Content,
+		ContentHTML:     utils.RenderMarkdown(req.Content),
+		IPRange:         utils.IPRange(clientIP),
+	}
+
+	// Books flagged for unusual activity send new reviews to the moderation queue
+	if book.ReviewsHeldUntil > time.Now().Unix() {
+		review.Status = utils.ReviewStatusPending
+	}
+
+	if err := s.challenges.SpendChallenge(req.PowChallenge); err != nil {
+		return nil, err
 	}
 
 	resource, err := s.repo.Create(&review)
 	if err != nil {
+		// Two concurrent submissions can both pass the existence check
+		if utils.IsUniqueViolation(err, utils.ReviewUniqueConstraint) {
+			return nil, errors.NewConflictError("You have already reviewed this book")
+		}
 		return nil, errors.NewInternalError(err)
 	}
+
+	s.aggregator.ReviewersChanged(resource.Name)
+
 	return resource, nil
 }
 
 func (s *reviewService) UpdateReview(id uuid.UUID, req *dto.ReviewUpdateRequest) (*model.Review, error) {
+	if err := utils.ValidateReviewUpdateRequest(req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
 	existing, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, errors.NewInternalError(err)
@@ -87,19 +167,26 @@ func (s *reviewService) UpdateReview(id uuid.UUID, req *dto.ReviewUpdateRequest)
 	updates := map[string]interface{}{}
 	if req.Content != nil {
 		updates["content"] = *req.Content
+		updates["content_html"] = utils.RenderMarkdown(*req.Content)
 	}
 	if req.Name != nil {
 		updates["name"] = *req.Name
 	}
 	if req.Email != nil {
 		updates["email"] = *req.Email
+		updates["email_normalized"] = utils.NormalizeEmail(*req.Email, s.limits.IgnoreGmailDots)
 	}
 
 	updated, err := s.repo.Update(id, updates)
 	if err != nil {
+		if utils.IsUniqueViolation(err, utils.ReviewUniqueConstraint) {
+			return nil, errors.NewConflictError("This email has already reviewed this book")
+		}
 		return nil, errors.NewInternalError(err)
 	}
 
+	s.aggregator.ReviewersChanged(existing.Name, updated.Name)
+
 	return updated, nil
 }
 
@@ -112,7 +199,13 @@ func (s *reviewService) DeleteReview(id uuid.UUID) error {
 		return errors.NewNotFoundError("Review not found")
 	}
 
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.aggregator.ReviewersChanged(existing.Name)
+
+	return nil
 }
 
 func (s *reviewService) GetReviewsByBookID(bookID uuid.UUID, params dto.QueryParams) ([]model.Review, *dto.PaginationMeta, error) {
@@ -124,6 +217,16 @@ func (s *reviewService) GetReviewsByBookID(bookID uuid.UUID, params dto.QueryPar
 	if err != nil {
 		return nil, nil, errors.NewInternalError(err)
 	}
+	renderMissingHTML(reviews)
 
 	return reviews, &meta, nil
 }
+
+// renderMissingHTML fills in content_html for reviews written before Markdown support
+func renderMissingHTML(reviews []model.Review) {
+	for i := range reviews {
+		if reviews[i].ContentHTML == "" && reviews[i].Content != "" {
+			reviews[i].ContentHTML = utils.RenderMarkdown(reviews[i].Content)
+		}
+	}
+}
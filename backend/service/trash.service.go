@@ -0,0 +1,135 @@
+package service
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"log"
+	"time"
+)
+
+// TrashService lists soft-deleted books and reviews and purges them once their retention ends
+type TrashService interface {
+	GetTrash(params dto.TrashQueryParams) ([]dto.TrashItem, *dto.PaginationMeta, error)
+	Purge(now time.Time) (int, int64, error)
+	StartPurger(interval time.Duration)
+}
+
+type trashService struct {
+	repo          repository.TrashRepository
+	retention     time.Duration
+	images        ImageGCService
+	imageGCDryRun bool
+}
+
+func NewTrashService(repo repository.TrashRepository, retention time.Duration, images ImageGCService, imageGCDryRun bool) TrashService {
+	return &trashService{repo, retention, images, imageGCDryRun}
+}
+
+func (s *trashService) GetTrash(params dto.TrashQueryParams) ([]dto.TrashItem, *dto.PaginationMeta, error) {
+	items := []dto.TrashItem{}
+
+	switch params.Type {
+	case "", utils.TrashTypeBook:
+		books, meta, err := s.repo.FindBooks(params.Offset, params.Limit)
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err)
+		}
+		for _, book := range books {
+			items = append(items, dto.TrashItem{
+				Type:      utils.TrashTypeBook,
+				ID:        book.ID,
+				Label:     book.Title,
+				DeletedAt: book.DeletedAt.Time.Unix(),
+				PurgeAt:   book.DeletedAt.Time.Add(s.retention).Unix(),
+			})
+		}
+		return items, &meta, nil
+	case utils.TrashTypeReview:
+		reviews, meta, err := s.repo.FindReviews(params.Offset, params.Limit)
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err)
+		}
+		for _, review := range reviews {
+			bookID := review.BookID
+			items = append(items, dto.TrashItem{
+				Type:      utils.TrashTypeReview,
+				ID:        review.ID,
+				BookID:    &bookID,
+				Label:     review.Name,
+				DeletedAt: review.DeletedAt.Time.Unix(),
+				PurgeAt:   review.DeletedAt.Time.Add(s.retention).Unix(),
+			})
+		}
+		return items, &meta, nil
+	default:
+		return nil, nil, errors.NewBadRequestError("type must be book or review")
+	}
+}
+
+// Purge removes books and reviews trashed longer than the retention period. It returns how
+// many books and reviews were removed. The covers and gallery images of purged books are
+// deleted once nothing else refers to them and no upload has touched them within the image
+// collector's grace period; a failure there is logged and left to the collector's next run.
+func (s *trashService) Purge(now time.Time) (int, int64, error) {
+	cutoff := now.Add(-s.retention)
+
+	books, err := s.repo.FindExpiredBooks(cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	purged := 0
+	images := []string{}
+	for _, book := range books {
+		// Read the images first, the purge takes the revisions and gallery rows with it
+		bookImages, err := s.repo.FindBookImages(book.ID)
+		if err != nil {
+			return purged, 0, err
+		}
+		if err := s.repo.PurgeBook(book.ID); err != nil {
+			return purged, 0, err
+		}
+		purged++
+
+		if book.Image != "" {
+			images = append(images, book.Image)
+		}
+		images = append(images, book.Images.URLs()...)
+		images = append(images, bookImages...)
+	}
+
+	if len(images) > 0 {
+		report, err := s.images.CollectImages(now, images, s.imageGCDryRun)
+		if err != nil {
+			log.Printf("Trash purger: failed to delete images of purged books: %v", err)
+		} else if report.Deleted > 0 {
+			log.Printf("Trash purger: deleted %d images of purged books", report.Deleted)
+		}
+	}
+
+	reviews, err := s.repo.PurgeReviews(cutoff)
+	if err != nil {
+		return purged, 0, err
+	}
+
+	return purged, reviews, nil
+}
+
+func (s *trashService) StartPurger(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			books, reviews, err := s.Purge(now)
+			if err != nil {
+				log.Printf("Trash purger: %v", err)
+			}
+			if books > 0 || reviews > 0 {
+				log.Printf("Trash purger: purged %d books and %d reviews", books, reviews)
+			}
+		}
+	}()
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadService lets clients send covers straight to the blob store instead of through a
+// multipart form. A presigned URL accepts a single PUT of the announced type and size under
+// uploads/; finalizing processes the staged file like any other cover and attaches it to a
+// book through BookService.UpdateBook.
+type UploadService interface {
+	CreateUpload(req *dto.UploadCreateRequest) (*dto.UploadResponse, error)
+	// ReceiveUpload stores a presigned upload sent to the API, for backends that implement repository.UploadVerifier
+	ReceiveUpload(key, contentType string, expires int64, signature string, body []byte) error
+	FinalizeUpload(id uuid.UUID, req *dto.UploadFinalizeRequest, actor string) (*model.Book, error)
+}
+
+type uploadService struct {
+	store       repository.BlobStore
+	bookService BookService
+}
+
+func NewUploadService(store repository.BlobStore, bookService BookService) UploadService {
+	return &uploadService{store, bookService}
+}
+
+func (s *uploadService) CreateUpload(req *dto.UploadCreateRequest) (*dto.UploadResponse, error) {
+	if !utils.IsCoverContentType(req.ContentType) {
+		return nil, errors.NewBadRequestError("content_type must be image/jpeg, image/png or image/webp")
+	}
+	if req.Size <= 0 {
+		return nil, errors.NewBadRequestError("size is required")
+	}
+	if req.Size > utils.CoverMaxBytes {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("image must be at most %d MB", utils.CoverMaxBytes>>20))
+	}
+
+	id := uuid.New()
+	url, err := s.store.PresignPut(utils.UploadKey(id), req.ContentType, req.Size, utils.UploadURLExpiry)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	return &dto.UploadResponse{
+		ID:        id,
+		URL:       url,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": req.ContentType},
+		ExpiresAt: time.Now().Add(utils.UploadURLExpiry).Unix(),
+	}, nil
+}
+
+func (s *uploadService) ReceiveUpload(key, contentType string, expires int64, signature string, body []byte) error {
+	verifier, ok := s.store.(repository.UploadVerifier)
+	if !ok {
+		return errors.NewNotFoundError("Uploads are sent to the storage service")
+	}
+	if !strings.HasPrefix(key, utils.UploadKeyPrefix) {
+		return errors.NewForbiddenError("Invalid upload URL")
+	}
+	// The signature covers the size, so a body of any other length is rejected here
+	if err := verifier.VerifyUpload(key, contentType, int64(len(body)), expires, signature); err != nil {
+		return errors.NewForbiddenError("Invalid or expired upload URL")
+	}
+
+	if _, err := s.store.Put(key, bytes.NewReader(body), contentType); err != nil {
+		return errors.NewInternalError(err)
+	}
+	return nil
+}
+
+func (s *uploadService) FinalizeUpload(id uuid.UUID, req *dto.UploadFinalizeRequest, actor string) (*model.Book, error) {
+	if req.BookID == uuid.Nil {
+		return nil, errors.NewBadRequestError("book_id is required")
+	}
+
+	if _, err := s.bookService.GetBookByID(req.BookID); err != nil {
+		return nil, err
+	}
+
+	key := utils.UploadKey(id)
+	file, err := s.store.Open(key)
+	if err == repository.ErrBlobNotFound {
+		return nil, errors.NewNotFoundError("Upload not found")
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	defer file.Close()
+
+	// The staged file is checked the same way as a cover uploaded through a form
+	url, images, err := storeCoverImage(s.store, file)
+	if err != nil {
+		// A file that is not a valid cover can never be finalized
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == http.StatusBadRequest {
+			s.removeStaged(key)
+		}
+		return nil, err
+	}
+
+	// When the update fails, the stored cover is left to the image garbage collector and the
+	// staged file is kept, so finalizing can be retried
+	updated, err := s.bookService.UpdateBook(req.BookID, &dto.BookUpdateRequest{Image: &url, Images: images, Reason: req.Reason}, nil, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.removeStaged(key)
+
+	return updated, nil
+}
+
+// removeStaged deletes a staged upload. One left behind is harmless, so failures are only logged
+func (s *uploadService) removeStaged(key string) {
+	if err := s.store.Delete(key); err != nil {
+		log.Printf("Upload service: failed to delete staged upload %s: %v", key, err)
+	}
+}
@@ -0,0 +1,93 @@
+package controller_test
+
+import (
+	"honya/backend/controller"
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/middleware"
+	"honya/backend/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) Record(entry *model.AuditLog) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditService) GetLogs(params dto.AuditQueryParams) ([]model.AuditLog, *dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.AuditLog), args.Get(1).(*dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockAuditService) Verify() (*dto.AuditVerifyResponse, error) {
+	args := m.Called()
+	return args.Get(0).(*dto.AuditVerifyResponse), args.Error(1)
+}
+
+func TestAudit_RecordsSuccessfulMutation(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockAudit := new(MockAuditService)
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	bookID := uuid.New()
+	mockService.On("DeleteBook", bookID).Return(nil)
+	mockAudit.On("Record", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		return entry.Actor == "public" &&
+			entry.Method == http.MethodDelete &&
+			entry.Route == "/books/:id" &&
+			entry.Status == http.StatusOK &&
+			entry.EntityType == "book" &&
+			entry.EntityID == bookID.String() &&
+			entry.Action == "delete" &&
+			entry.RequestID == "req-1"
+	})).Return(nil)
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderXRequestID, c.Get(fiber.HeaderXRequestID))
+		return c.Next()
+	})
+	app.Use(middleware.Audit(mockAudit))
+	app.Delete("/books/:id", ctrl.DeleteBook)
+
+	req := httptest.NewRequest(http.MethodDelete, "/books/"+bookID.String(), nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-1")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockAudit.AssertNumberOfCalls(t, "Record", 1)
+}
+
+func TestAudit_SkipsFailedMutation(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockAudit := new(MockAuditService)
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	bookID := uuid.New()
+	mockService.On("DeleteBook", bookID).Return(errors.NewNotFoundError("Book not found"))
+
+	app.Use(middleware.Audit(mockAudit))
+	app.Delete("/books/:id", ctrl.DeleteBook)
+
+	req := httptest.NewRequest(http.MethodDelete, "/books/"+bookID.String(), nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	mockAudit.AssertNotCalled(t, "Record", mock.Anything)
+}
@@ -0,0 +1,68 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"honya/backend/controller"
+	"honya/backend/dto"
+	"honya/backend/middleware"
+	"honya/backend/model"
+	"honya/backend/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookChangeRequestService struct {
+	mock.Mock
+}
+
+func (m *MockBookChangeRequestService) CreateRequest(bookID uuid.UUID, req *dto.BookChangeRequestCreateRequest) (*model.BookChangeRequest, error) {
+	args := m.Called(bookID, req)
+	return args.Get(0).(*model.BookChangeRequest), args.Error(1)
+}
+
+func (m *MockBookChangeRequestService) GetRequest(id uuid.UUID) (*model.BookChangeRequest, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.BookChangeRequest), args.Error(1)
+}
+
+func (m *MockBookChangeRequestService) GetRequests(params dto.BookChangeRequestQueryParams) ([]model.BookChangeRequest, *dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.BookChangeRequest), args.Get(1).(*dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockBookChangeRequestService) AcceptRequest(id uuid.UUID, req *dto.BookChangeRequestReviewRequest, actor string) (*model.BookChangeRequest, error) {
+	args := m.Called(id, req, actor)
+	return args.Get(0).(*model.BookChangeRequest), args.Error(1)
+}
+
+func (m *MockBookChangeRequestService) RejectRequest(id uuid.UUID, req *dto.BookChangeRequestReviewRequest, actor string) (*model.BookChangeRequest, error) {
+	args := m.Called(id, req, actor)
+	return args.Get(0).(*model.BookChangeRequest), args.Error(1)
+}
+
+func TestCreateChangeRequest_RequiresProofOfWork(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockService := new(MockBookChangeRequestService)
+	ctrl := controller.NewBookChangeRequestController(mockService, service.NewChallengeService(nil, true, "secret", 4, 8))
+
+	pages := 412
+	reqBody := dto.BookChangeRequestCreateRequest{Changes: dto.BookUpdateRequest{Pages: &pages}}
+
+	app.Post("/books/:id/change-requests", ctrl.CreateRequest)
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/books/"+uuid.New().String()+"/change-requests", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything)
+}
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"honya/backend/controller"
 	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/middleware"
 	"honya/backend/model"
+	"honya/backend/utils"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -37,8 +41,8 @@ func (m *MockBookService) CreateBook(req *dto.BookCreateRequest, file *multipart
 	return args.Get(0).(*model.Book), args.Error(1)
 }
 
-func (m *MockBookService) UpdateBook(id uuid.UUID, req *dto.BookUpdateRequest, fileHeader *multipart.FileHeader) (*model.Book, error) {
-	args := m.Called(id, req, fileHeader)
+func (m *MockBookService) UpdateBook(id uuid.UUID, req *dto.BookUpdateRequest, fileHeader *multipart.FileHeader, actor string) (*model.Book, error) {
+	args := m.Called(id, req, fileHeader, actor)
 	return args.Get(0).(*model.Book), args.Error(1)
 }
 
@@ -47,6 +51,48 @@ func (m *MockBookService) DeleteBook(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockBookService) FindDuplicates() ([]dto.BookDuplicate, error) {
+	args := m.Called()
+	return args.Get(0).([]dto.BookDuplicate), args.Error(1)
+}
+
+func (m *MockBookService) MergeBooks(targetID uuid.UUID, req *dto.BookMergeRequest, actor string) (*model.Book, error) {
+	args := m.Called(targetID, req, actor)
+	return args.Get(0).(*model.Book), args.Error(1)
+}
+
+func (m *MockBookService) FindRedirect(id uuid.UUID) (*model.BookRedirect, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.BookRedirect), args.Error(1)
+}
+
+func (m *MockBookService) GetRevisions(bookID uuid.UUID, offset, limit int, includeHidden bool) ([]model.BookRevision, *dto.PaginationMeta, error) {
+	args := m.Called(bookID, offset, limit, includeHidden)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]model.BookRevision), args.Get(1).(*dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockBookService) RestoreRevision(bookID uuid.UUID, number int, req *dto.BookRevisionRestoreRequest, actor string) (*model.Book, error) {
+	args := m.Called(bookID, number, req, actor)
+	return args.Get(0).(*model.Book), args.Error(1)
+}
+
+func (m *MockBookService) RestoreBook(id uuid.UUID) (*model.Book, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Book), args.Error(1)
+}
+
+func (m *MockBookService) PublishScheduled(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBookService) StartPublisher(interval time.Duration) {
+	m.Called(interval)
+}
+
 func TestGetBooks_WithQueryParams(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockBookService)
@@ -78,6 +124,7 @@ func TestGetBooks_WithQueryParams(t *testing.T) {
 		Rating:          0,
 		Pages:           0,
 		Sort:            "title",
+		Status:          "published",
 	}
 
 	mockService.On("GetBooks", params).Return(books, meta, nil)
@@ -97,8 +144,9 @@ func TestGetBookByID(t *testing.T) {
 
 	bookID := uuid.New()
 	book := &model.Book{
-		ID:    bookID,
-		Title: "Test Book",
+		ID:     bookID,
+		Title:  "Test Book",
+		Status: "published",
 	}
 
 	mockService.On("GetBookByID", bookID).Return(book, nil)
@@ -111,6 +159,48 @@ func TestGetBookByID(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestGetBookByID_RedirectsMergedBook(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	oldID := uuid.New()
+	newID := uuid.New()
+
+	mockService.On("GetBookByID", oldID).Return((*model.Book)(nil), errors.NewNotFoundError("Book not found"))
+	mockService.On("FindRedirect", oldID).Return(&model.BookRedirect{FromID: oldID, ToID: newID}, nil)
+
+	app.Get("/api/books/:id", ctrl.GetBookByID)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/books/"+oldID.String(), nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/api/books/"+newID.String(), resp.Header.Get("Location"))
+}
+
+func TestMergeBook(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	targetID := uuid.New()
+	sourceID := uuid.New()
+	mergeReq := &dto.BookMergeRequest{SourceID: sourceID, Strategy: "prefer_source"}
+
+	mockService.On("MergeBooks", targetID, mergeReq, "public").Return(&model.Book{ID: targetID, Title: "Merged"}, nil)
+
+	app.Post("/api/books/:id/merge", ctrl.MergeBook)
+
+	body, _ := json.Marshal(mergeReq)
+	req := httptest.NewRequest(http.MethodPost, "/api/books/"+targetID.String()+"/merge", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
 func TestCreateBook_MultipartFormData(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockBookService)
@@ -178,7 +268,7 @@ func TestUpdateBook_JSON(t *testing.T) {
 		Category:   "fiction",
 	}
 
-	mockService.On("UpdateBook", bookID, &updateReq, (*multipart.FileHeader)(nil)).Return(updatedBook, nil)
+	mockService.On("UpdateBook", bookID, &updateReq, (*multipart.FileHeader)(nil), "public").Return(updatedBook, nil)
 
 	app.Patch("/api/books/:id", ctrl.UpdateBook)
 
@@ -208,7 +298,7 @@ func TestUpdateBook_MultipartFormData(t *testing.T) {
 		Category:   "fiction",
 	}
 
-	mockService.On("UpdateBook", bookID, mock.AnythingOfType("*dto.BookUpdateRequest"), mock.AnythingOfType("*multipart.FileHeader")).Return(updatedBook, nil)
+	mockService.On("UpdateBook", bookID, mock.AnythingOfType("*dto.BookUpdateRequest"), mock.AnythingOfType("*multipart.FileHeader"), "public").Return(updatedBook, nil)
 
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
@@ -250,3 +340,96 @@ func TestDeleteBook(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+func TestGetRevisions(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	bookID := uuid.New()
+	revisions := []model.BookRevision{{
+		ID:     uuid.New(),
+		BookID: bookID,
+		Number: 2,
+		Actor:  "moderator",
+		Before: map[string]interface{}{"title": "Dune", "pages": 400.0},
+		After:  map[string]interface{}{"title": "Dune (Deluxe)", "pages": 412.0},
+	}}
+
+	mockService.On("GetRevisions", bookID, 0, 10, false).Return(revisions, &dto.PaginationMeta{TotalCount: 1, Limit: 10}, nil)
+
+	app.Get("/api/books/:id/revisions", ctrl.GetRevisions)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/books/"+bookID.String()+"/revisions", nil)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body dto.BookRevisionListResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, []dto.FieldChange{
+		{Field: "pages", Before: 400.0, After: 412.0},
+		{Field: "title", Before: "Dune", After: "Dune (Deluxe)"},
+	}, body.Data[0].Changes)
+}
+
+func TestGetRevisions_DraftBookIsHiddenFromPublic(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	bookID := uuid.New()
+	mockService.On("GetRevisions", bookID, 0, 10, false).Return(nil, nil, errors.NewNotFoundError("Book not found"))
+	mockService.On("GetRevisions", bookID, 0, 10, true).Return([]model.BookRevision{}, &dto.PaginationMeta{Limit: 10}, nil)
+
+	app.Get("/api/books/:id/revisions", ctrl.GetRevisions)
+	app.Get("/api/admin/books/:id/revisions", func(c *fiber.Ctx) error {
+		c.Locals(utils.RoleLocalsKey, utils.RoleAdmin)
+		return c.Next()
+	}, ctrl.GetRevisions)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/books/"+bookID.String()+"/revisions", nil)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/books/"+bookID.String()+"/revisions", nil)
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreRevision_InvalidNumber(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	app.Post("/api/books/:id/revisions/:rev/restore", ctrl.RestoreRevision)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/books/"+uuid.New().String()+"/revisions/latest/restore", nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "RestoreRevision", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetBookByID_HidesDraftFromPublic(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockService := new(MockBookService)
+	ctrl := controller.NewBookController(mockService)
+
+	bookID := uuid.New()
+	mockService.On("GetBookByID", bookID).Return(&model.Book{ID: bookID, Status: "draft"}, nil)
+
+	app.Get("/api/books/:id", ctrl.GetBookByID)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/books/"+bookID.String(), nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,77 @@
+package controller_test
+
+import (
+	"honya/backend/controller"
+	"honya/backend/dto"
+	"honya/backend/middleware"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDashboardService struct {
+	mock.Mock
+}
+
+func (m *MockDashboardService) GetBooksData(query dto.BooksDataQuery) (*dto.DonutChartData, error) {
+	args := m.Called(query)
+	return args.Get(0).(*dto.DonutChartData), args.Error(1)
+}
+
+func (m *MockDashboardService) GetReviewsData(limit int) (*dto.BarChartData, error) {
+	args := m.Called(limit)
+	return args.Get(0).(*dto.BarChartData), args.Error(1)
+}
+
+func (m *MockDashboardService) GetTimeSeries(query dto.TimeSeriesQuery) (*dto.TimeSeriesData, error) {
+	args := m.Called(query)
+	return args.Get(0).(*dto.TimeSeriesData), args.Error(1)
+}
+
+func (m *MockDashboardService) RunQuery(req *dto.AnalyticsQueryRequest) (*dto.AnalyticsQueryResponse, error) {
+	args := m.Called(req)
+	return args.Get(0).(*dto.AnalyticsQueryResponse), args.Error(1)
+}
+
+func TestGetBooksData_ExportsCSV(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockDashboardService)
+	ctrl := controller.NewDashboardController(mockService)
+
+	data := &dto.DonutChartData{
+		FilterBy: "category",
+		Data:     map[string]int64{"science": 2, "fiction": 5},
+	}
+	mockService.On("GetBooksData", dto.BooksDataQuery{FilterBy: "category"}).Return(data, nil)
+
+	app.Get("/dashboard/books-data", ctrl.GetBooksData)
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/books-data?filter_by=category&format=csv", nil)
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+	assert.Equal(t, "category,count\nfiction,5\nscience,2\n", string(body))
+}
+
+func TestGetTimeSeries_RejectsUnknownFormat(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockService := new(MockDashboardService)
+	ctrl := controller.NewDashboardController(mockService)
+
+	mockService.On("GetTimeSeries", mock.Anything).Return(&dto.TimeSeriesData{}, nil)
+
+	app.Get("/dashboard/timeseries", ctrl.GetTimeSeries)
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/timeseries?metric=books_created&format=pdf", nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"honya/backend/controller"
 	"honya/backend/dto"
+	"honya/backend/middleware"
 	"honya/backend/model"
+	"honya/backend/service"
+	"honya/backend/utils"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -36,8 +39,8 @@ func (m *MockReviewService) GetReviewsByBookID(bookID uuid.UUID, params dto.Quer
 	return args.Get(0).([]model.Review), args.Get(1).(*dto.PaginationMeta), args.Error(2)
 }
 
-func (m *MockReviewService) CreateReview(req *dto.ReviewCreateRequest) (*model.Review, error) {
-	args := m.Called(req)
+func (m *MockReviewService) CreateReview(req *dto.ReviewCreateRequest, clientIP string) (*model.Review, error) {
+	args := m.Called(req, clientIP)
 	return args.Get(0).(*model.Review), args.Error(1)
 }
 
@@ -59,7 +62,7 @@ func (m *MockReviewService) FindByBookID(bookID uuid.UUID, params dto.QueryParam
 func TestGetAllReviews(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockReviewService)
-	ctrl := controller.NewReviewController(mockService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
 
 	reviews := []model.Review{
 		{ID: uuid.New(), Name: "Alice", Email: "a@test.com", Content: "Great book!"},
@@ -80,7 +83,7 @@ func TestGetAllReviews(t *testing.T) {
 func TestGetReviewByID(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockReviewService)
-	ctrl := controller.NewReviewController(mockService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
 
 	id := uuid.New()
 	review := &model.Review{ID: id, Name: "Alice", Email: "a@test.com", Content: "Great book!"}
@@ -94,10 +97,76 @@ func TestGetReviewByID(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestGetReviewByID_HidesEmailFromPublic(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockReviewService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
+
+	id := uuid.New()
+	review := &model.Review{ID: id, Name: "Alice", Email: "a@test.com", Content: "Great book!"}
+
+	mockService.On("GetReviewByID", id).Return(review, nil)
+
+	app.Get("/reviews/:id", ctrl.GetReviewByID)
+	req := httptest.NewRequest(http.MethodGet, "/reviews/"+id.String(), nil)
+	resp, _ := app.Test(req)
+
+	var result map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, result, "email")
+	assert.Equal(t, dto.AvatarHash("a@test.com"), result["avatar_hash"])
+}
+
+func TestGetReviewByID_ShowsEmailToModerator(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockReviewService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
+
+	id := uuid.New()
+	review := &model.Review{ID: id, Name: "Alice", Email: "a@test.com", Content: "Great book!"}
+
+	mockService.On("GetReviewByID", id).Return(review, nil)
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(utils.RoleLocalsKey, utils.RoleModerator)
+		return c.Next()
+	})
+	app.Get("/reviews/:id", ctrl.GetReviewByID)
+	req := httptest.NewRequest(http.MethodGet, "/reviews/"+id.String(), nil)
+	resp, _ := app.Test(req)
+
+	var result dto.ReviewResponse
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "a@test.com", result.Email)
+}
+
+func TestGetReviewByID_HidesHiddenReviewFromPublic(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	})
+	mockService := new(MockReviewService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
+
+	id := uuid.New()
+	review := &model.Review{ID: id, Name: "Alice", Email: "a@test.com", Content: "Spam", Status: utils.ReviewStatusHidden}
+
+	mockService.On("GetReviewByID", id).Return(review, nil)
+
+	app.Get("/reviews/:id", ctrl.GetReviewByID)
+	req := httptest.NewRequest(http.MethodGet, "/reviews/"+id.String(), nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
 func TestGetReviewsByBookID(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockReviewService)
-	ctrl := controller.NewReviewController(mockService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
 
 	bookID := uuid.New()
 	reviews := []model.Review{
@@ -118,7 +187,7 @@ func TestGetReviewsByBookID(t *testing.T) {
 func TestCreateReview(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockReviewService)
-	ctrl := controller.NewReviewController(mockService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
 
 	bookID := uuid.New()
 	reqBody := dto.ReviewCreateRequest{
@@ -137,7 +206,7 @@ func TestCreateReview(t *testing.T) {
 		UpdatedAt: time.Now().Unix(),
 	}
 
-	mockService.On("CreateReview", &reqBody).Return(review, nil)
+	mockService.On("CreateReview", &reqBody, "0.0.0.0").Return(review, nil)
 
 	app.Post("/reviews", ctrl.CreateReview)
 	body, _ := json.Marshal(reqBody)
@@ -151,7 +220,7 @@ func TestCreateReview(t *testing.T) {
 func TestUpdateReview(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockReviewService)
-	ctrl := controller.NewReviewController(mockService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
 
 	id := uuid.New()
 	name := "Bob"
@@ -177,7 +246,7 @@ func TestUpdateReview(t *testing.T) {
 func TestDeleteReview(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockReviewService)
-	ctrl := controller.NewReviewController(mockService)
+	ctrl := controller.NewReviewController(mockService, service.NewChallengeService(nil, false, "", 0, 0))
 
 	id := uuid.New()
 	mockService.On("DeleteReview", id).Return(nil)
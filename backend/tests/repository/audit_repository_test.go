@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockAuditRepository(t *testing.T) (*repository.AuditRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.AuditRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.AuditLog](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestAuditRepository_Append_LinksToLastEntry(t *testing.T) {
+	repo, mock, cleanup := NewMockAuditRepository(t)
+	defer cleanup()
+
+	entry := &model.AuditLog{
+		Actor:      "admin",
+		Method:     "DELETE",
+		Route:      "/api/books/:id",
+		Status:     200,
+		EntityType: "book",
+		EntityID:   "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d",
+		Action:     "delete",
+		CreatedAt:  1700000000,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WithArgs(utils.AuditChainLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "hash" FROM "audit_logs" ORDER BY id DESC LIMIT $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow("previous"))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "audit_logs"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectCommit()
+
+	err := repo.Append(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), entry.ID)
+	assert.Equal(t, "previous", entry.PrevHash)
+	assert.Equal(t, entry.ComputeHash(), entry.Hash)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_FindAll_Filters(t *testing.T) {
+	repo, mock, cleanup := NewMockAuditRepository(t)
+	defer cleanup()
+
+	params := dto.AuditQueryParams{Actor: "moderator", EntityType: "review", From: 1700000000, Limit: 10}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "audit_logs" WHERE actor = $1 AND entity_type = $2 AND created_at >= $3`)).
+		WithArgs("moderator", "review", int64(1700000000)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" WHERE actor = $1 AND entity_type = $2 AND created_at >= $3 ORDER BY id DESC LIMIT $4`)).
+		WithArgs("moderator", "review", int64(1700000000), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "entity_type", "action"}).AddRow(7, "moderator", "review", "remove"))
+
+	entries, meta, err := repo.FindAll(params)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), meta.TotalCount)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "remove", entries[0].Action)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -47,7 +47,7 @@ func TestBaseRepository_FindByID(t *testing.T) {
 		AddRow(id, "Test Book")
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "books" WHERE id = $1 ORDER BY "books"."id" LIMIT $2`,
+		`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2`,
 	)).
 		WithArgs(id, 1).
 		WillReturnRows(rows)
@@ -83,6 +83,11 @@ func TestBaseRepository_Create(t *testing.T) {
 			sqlmock.AnyArg(), // CreatedAt
 			sqlmock.AnyArg(), // UpdatedAt
 			sqlmock.AnyArg(), // AuthorName
+			sqlmock.AnyArg(), // Images
+			sqlmock.AnyArg(), // ReviewsHeldUntil
+			sqlmock.AnyArg(), // Status
+			sqlmock.AnyArg(), // PublishAt
+			sqlmock.AnyArg(), // DeletedAt
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
@@ -117,7 +122,7 @@ func TestBaseRepository_Update(t *testing.T) {
 		AddRow(id, "New Title")
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "books" WHERE id = $1 ORDER BY "books"."id" LIMIT $2`,
+		`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2`,
 	)).
 		WithArgs(id, 1).
 		WillReturnRows(rows)
@@ -139,8 +144,9 @@ func TestBaseRepository_Delete(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectExec(`DELETE FROM "books"`).
-		WithArgs(id).
+	// Books are soft-deleted
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1 WHERE id = $2 AND "books"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mock.ExpectCommit()
@@ -150,3 +156,22 @@ func TestBaseRepository_Delete(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestBaseRepository_CountByInterval(t *testing.T) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	defer cleanup()
+
+	repo := repository.NewBaseRepository[model.Book](db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT to_char(date_trunc($1, to_timestamp(created_at) AT TIME ZONE $2), 'YYYY-MM-DD') as key, COUNT(*) as count FROM "books" WHERE (created_at >= $3 AND created_at < $4) AND "books"."deleted_at" IS NULL GROUP BY "key"`,
+	)).
+		WithArgs("week", "Asia/Tokyo", int64(100), int64(200)).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).AddRow("2026-01-05", 3))
+
+	counts, err := repo.CountByInterval("week", "Asia/Tokyo", 100, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"2026-01-05": 3}, counts)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
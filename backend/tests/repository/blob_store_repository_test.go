@@ -0,0 +1,193 @@
+package repository_test
+
+import (
+	"honya/backend/config"
+	"honya/backend/repository"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBlobStore_PutExistsDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := repository.NewLocalBlobStore(dir, "http://localhost:8080/uploads/", "secret")
+	require.NoError(t, err)
+
+	url, err := store.Put("books/dune.png", strings.NewReader("png"), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/uploads/books/dune.png", url)
+
+	data, err := os.ReadFile(filepath.Join(dir, "books", "dune.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+
+	key, ok := store.Key(url)
+	assert.True(t, ok)
+	assert.Equal(t, "books/dune.png", key)
+
+	exists, err := store.Exists(key)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.NoError(t, store.Delete(key))
+	assert.NoError(t, store.Delete(key), "deleting a missing blob is not an error")
+
+	exists, err = store.Exists(key)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestLocalBlobStore_List(t *testing.T) {
+	dir := t.TempDir()
+	store, err := repository.NewLocalBlobStore(dir, "/uploads", "")
+	require.NoError(t, err)
+
+	for _, key := range []string{"books/a.jpg", "books/b.webp", "uploads/abc"} {
+		_, err := store.Put(key, strings.NewReader("data"), "")
+		require.NoError(t, err)
+	}
+	// A file Put is still writing is not listed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "books", ".upload-123"), []byte("partial"), 0o644))
+
+	objects, err := store.List("books/")
+	require.NoError(t, err)
+	keys := []string{}
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+		assert.Equal(t, int64(4), object.Size)
+		assert.WithinDuration(t, time.Now(), object.ModifiedAt, time.Minute)
+	}
+	assert.Equal(t, []string{"books/a.jpg", "books/b.webp"}, keys)
+}
+
+func TestLocalBlobStore_RejectsKeysOutsideTheDirectory(t *testing.T) {
+	store, err := repository.NewLocalBlobStore(t.TempDir(), "/uploads", "")
+	require.NoError(t, err)
+
+	for _, key := range []string{"../secret", "books/../../secret", "/etc/passwd", "books//a.png", ""} {
+		_, err := store.Put(key, strings.NewReader("x"), "")
+		assert.Error(t, err, key)
+	}
+}
+
+func TestLocalBlobStore_PresignedUploads(t *testing.T) {
+	store, err := repository.NewLocalBlobStore(t.TempDir(), "http://localhost:8080/uploads", "secret")
+	require.NoError(t, err)
+	verifier, ok := store.(repository.UploadVerifier)
+	require.True(t, ok)
+
+	presigned, err := store.PresignPut("uploads/abc", "image/png", 42, time.Minute)
+	require.NoError(t, err)
+	parsed, err := url.Parse(presigned)
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/uploads/abc", parsed.Path)
+
+	expires, err := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	require.NoError(t, err)
+	signature := parsed.Query().Get("signature")
+
+	assert.NoError(t, verifier.VerifyUpload("uploads/abc", "image/png", 42, expires, signature))
+	assert.Error(t, verifier.VerifyUpload("uploads/abc", "image/png", 43, expires, signature), "size differs")
+	assert.Error(t, verifier.VerifyUpload("uploads/abc", "image/jpeg", 42, expires, signature), "content type differs")
+	assert.Error(t, verifier.VerifyUpload("uploads/other", "image/png", 42, expires, signature), "key differs")
+	assert.Error(t, verifier.VerifyUpload("uploads/abc", "image/png", 42, expires+60, signature), "expiry was changed")
+
+	expired, err := store.PresignPut("uploads/abc", "image/png", 42, -time.Minute)
+	require.NoError(t, err)
+	parsed, _ = url.Parse(expired)
+	expires, _ = strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	assert.Error(t, verifier.VerifyUpload("uploads/abc", "image/png", 42, expires, parsed.Query().Get("signature")))
+}
+
+func TestLocalBlobStore_Touch(t *testing.T) {
+	dir := t.TempDir()
+	store, err := repository.NewLocalBlobStore(dir, "/uploads", "")
+	require.NoError(t, err)
+
+	exists, err := store.Touch("books/missing.jpg")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = store.Put("books/dune.jpg", strings.NewReader("jpeg"), "image/jpeg")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "books", "dune.jpg")
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	exists, err = store.Touch("books/dune.jpg")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
+}
+
+func TestBlobStore_OpenMissingKey(t *testing.T) {
+	store := repository.NewMemoryBlobStore("https://cdn.test")
+
+	_, err := store.Open("uploads/missing")
+	assert.Equal(t, repository.ErrBlobNotFound, err)
+}
+
+func TestBlobStore_KeyIgnoresForeignURLs(t *testing.T) {
+	store := repository.NewMemoryBlobStore("https://cdn.test")
+
+	_, ok := store.Key("https://covers.example.com/books/dune.png")
+	assert.False(t, ok)
+	_, ok = store.Key("https://cdn.test/")
+	assert.False(t, ok)
+}
+
+func TestS3BlobStore_URLs(t *testing.T) {
+	tests := []struct {
+		name string
+		env  config.EnvConfig
+		want string
+	}{
+		{
+			name: "aws",
+			env:  config.EnvConfig{AWSBucket: "honya", AWSRegion: "ap-south-1"},
+			want: "https://honya.s3.ap-south-1.amazonaws.com/books/a.png",
+		},
+		{
+			name: "minio path style",
+			env:  config.EnvConfig{AWSBucket: "honya", AWSRegion: "us-east-1", S3Endpoint: "http://localhost:9000", S3ForcePathStyle: true},
+			want: "http://localhost:9000/honya/books/a.png",
+		},
+		{
+			name: "custom endpoint virtual hosted",
+			env:  config.EnvConfig{AWSBucket: "honya", AWSRegion: "auto", S3Endpoint: "https://storage.example.com"},
+			want: "https://honya.storage.example.com/books/a.png",
+		},
+		{
+			name: "public url",
+			env:  config.EnvConfig{AWSBucket: "honya", AWSRegion: "ap-south-1", S3PublicURL: "https://cdn.example.com"},
+			want: "https://cdn.example.com/books/a.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := repository.NewS3BlobStore(tt.env)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, store.URL("books/a.png"))
+
+			key, ok := store.Key(tt.want)
+			assert.True(t, ok)
+			assert.Equal(t, "books/a.png", key)
+		})
+	}
+}
+
+func TestNewBlobStore_UnknownDriver(t *testing.T) {
+	_, err := repository.NewBlobStore(config.EnvConfig{StorageDriver: "ftp"})
+	assert.Error(t, err)
+}
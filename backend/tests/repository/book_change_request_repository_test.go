@@ -0,0 +1,40 @@
+package repository_test
+
+import (
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockBookChangeRequestRepository(t *testing.T) (*repository.BookChangeRequestRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.BookChangeRequestRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.BookChangeRequest](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestBookChangeRequestRepository_Resolve_OnlyPending(t *testing.T) {
+	repo, mock, cleanup := NewMockBookChangeRequestRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "book_change_requests" SET "reviewed_at"=$1,"reviewed_by"=$2,"reviewer_note"=$3,"status"=$4,"updated_at"=$5 WHERE id = $6 AND status = $7`)).
+		WithArgs(int64(1700000000), "admin", "Wrong edition", "rejected", sqlmock.AnyArg(), id, "pending").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	resolved, err := repo.Resolve(id, "rejected", "Wrong edition", "admin", 1700000000)
+	assert.NoError(t, err)
+	assert.False(t, resolved)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockBookImageRepository(t *testing.T) (*repository.BookImageRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.BookImageRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.BookImage](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestBookImageRepository_Delete(t *testing.T) {
+	repo, mock, cleanup := NewMockBookImageRepository(t)
+	defer cleanup()
+
+	image := &model.BookImage{ID: uuid.New(), BookID: uuid.New(), Position: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "book_images" WHERE id = $1`)).
+		WithArgs(image.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "book_images" SET "position"=position - 1 WHERE book_id = $1 AND position > $2`)).
+		WithArgs(image.BookID, 2).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	err := repo.Delete(image)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -7,6 +7,7 @@ import (
 	"honya/backend/utils"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -55,3 +56,289 @@ func TestBookRepository_FindAll_WithFilters(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestBookRepository_Aggregate_BindsFilterValues(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT books.category AS category, COUNT(*) AS count, COALESCE(SUM(review_counts.review_count), 0)::bigint AS review_count FROM "books" LEFT JOIN (SELECT book_id, COUNT(*) AS review_count FROM reviews WHERE status = $1 AND deleted_at IS NULL GROUP BY book_id) AS review_counts ON review_counts.book_id = books.id WHERE (books.status = $2 AND books.deleted_at IS NULL) AND books.rating >= CAST($3 AS numeric) AND books.author_name IN ($4,$5) GROUP BY "books"."category" ORDER BY books.category LIMIT $6`,
+	)).
+		WithArgs("published", "published", 4.0, "Haruki Murakami", "Matt Haig", 500).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "count", "review_count"}).AddRow("fiction", 2, 5))
+
+	rows, err := repo.Aggregate(&dto.AnalyticsQueryRequest{
+		Dimensions: []string{"category"},
+		Metrics:    []string{"count", "review_count"},
+		Filters: []dto.AnalyticsFilter{
+			{Field: "rating", Op: "gte", Value: 4.0},
+			{Field: "author", Op: "in", Value: []string{"Haruki Murakami", "Matt Haig"}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "fiction", rows[0]["category"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_CountByEdges(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT width_bucket(rating::float8, ARRAY[$1, $2]::float8[]) as bucket, COUNT(*) as count FROM "books" WHERE rating IS NOT NULL AND status = $3 AND "books"."deleted_at" IS NULL GROUP BY "bucket"`,
+	)).
+		WithArgs(3.0, 4.0, "published").
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).AddRow(1, 5).AddRow(2, 2))
+
+	counts, err := repo.CountByEdges("rating", []float64{3, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{1: 5, 2: 2}, counts)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_CountByInterval_OnlyPublished(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT to_char(date_trunc($1, to_timestamp(created_at) AT TIME ZONE $2), 'YYYY-MM-DD') as key, COUNT(*) as count FROM "books" WHERE status = $3 AND (created_at >= $4 AND created_at < $5) AND "books"."deleted_at" IS NULL GROUP BY "key"`,
+	)).
+		WithArgs("month", "UTC", "published", int64(100), int64(200)).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).AddRow("2026-01-01", 2))
+
+	counts, err := repo.CountByInterval("month", "UTC", 100, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"2026-01-01": 2}, counts)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_FindReviewerNames_IncludesTrashedReviews(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	bookID := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT "name" FROM "reviews" WHERE book_id = $1 AND name IS NOT NULL AND name != ''`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Alice").AddRow("Bob"))
+
+	names, err := repo.FindReviewerNames(bookID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Bob"}, names)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_HoldReviews_OnlyExtends(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "reviews_held_until"=$1,"updated_at"=$2 WHERE (id = $3 AND reviews_held_until < $4) AND "books"."deleted_at" IS NULL`)).
+		WithArgs(int64(1700086400), sqlmock.AnyArg(), id, int64(1700086400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.HoldReviews(id, 1700086400)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_Merge(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	targetID := uuid.New()
+	sourceID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(targetID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "isbn"}).AddRow(targetID, ""))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "reviews" WHERE book_id = $1 AND email_normalized IN (SELECT "email_normalized" FROM "reviews" WHERE book_id = $2 AND email_normalized IS NOT NULL)`)).
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "reviews" SET "book_id"=$1,"updated_at"=$2 WHERE book_id = $3`)).
+		WithArgs(targetID, sqlmock.AnyArg(), sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "book_redirects" SET "to_id"=$1 WHERE to_id = $2`)).
+		WithArgs(targetID, sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_redirects" ("from_id","to_id","created_at") VALUES ($1,$2,$3) ON CONFLICT ("from_id") DO UPDATE SET "to_id"="excluded"."to_id"`)).
+		WithArgs(sourceID, targetID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "books" WHERE id = $1`)).
+		WithArgs(sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(number), 0) FROM "book_revisions" WHERE book_id = $1`)).
+		WithArgs(targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_revisions"`)).
+		WithArgs(sqlmock.AnyArg(), targetID, 1, "admin", "Merged book "+sourceID.String(), `{"isbn":""}`, `{"isbn":"9780756404079"}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "isbn"=$1,"updated_at"=$2 WHERE id = $3`)).
+		WithArgs("9780756404079", sqlmock.AnyArg(), targetID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE "books"."deleted_at" IS NULL AND "books"."id" = $1`)).
+		WithArgs(targetID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "isbn"}).AddRow(targetID, "9780756404079"))
+	mock.ExpectCommit()
+
+	book, err := repo.Merge(targetID, sourceID, map[string]interface{}{"isbn": "9780756404079"}, "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "9780756404079", book.Isbn)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_FindRedirect_NotMerged(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "book_redirects" WHERE from_id = $1`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"from_id", "to_id"}))
+
+	redirect, err := repo.FindRedirect(id)
+	assert.NoError(t, err)
+	assert.Nil(t, redirect)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_Update_RecordsRevision(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+	title := "Dune"
+	pages := 412
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND "books"."deleted_at" IS NULL ORDER BY "books"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "pages"}).AddRow(id, "Dune", 400))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(number), 0) FROM "book_revisions" WHERE book_id = $1`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(4))
+	// The unchanged title is left out of the revision and the update
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_revisions"`)).
+		WithArgs(sqlmock.AnyArg(), id, 5, "moderator", "Typo", `{"pages":400}`, `{"pages":412}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "pages"=$1,"updated_at"=$2 WHERE id = $3`)).
+		WithArgs(412, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE "books"."deleted_at" IS NULL AND "books"."id" = $1`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "pages"}).AddRow(id, "Dune", 412))
+	mock.ExpectCommit()
+
+	book, err := repo.Update(id, &dto.BookUpdateRequest{Title: &title, Pages: &pages, Reason: "Typo"}, "moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, 412, book.Pages)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_Delete_TrashesBookAndReviews(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "reviews" SET "deleted_at"=$1 WHERE book_id = $2 AND "reviews"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1 WHERE id = $2 AND "books"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Delete(id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_Restore_OnlyReviewsTrashedWithBook(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+	deletedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "books"."id" LIMIT $2`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "deleted_at"}).AddRow(id, "Dune", deletedAt))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "reviews" SET "deleted_at"=$1 WHERE book_id = $2 AND deleted_at = $3`)).
+		WithArgs(nil, id, deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "deleted_at"=$1 WHERE id = $2`)).
+		WithArgs(nil, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	book, err := repo.Restore(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Dune", book.Title)
+	assert.False(t, book.DeletedAt.Valid)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_FindAll_HidesArchivedByDefault(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "books" WHERE status <> $1 AND "books"."deleted_at" IS NULL`)).
+		WithArgs("archived").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE status <> $1 AND "books"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $2`)).
+		WithArgs("archived", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, err := repo.FindAll(dto.BookQueryParams{Limit: 10})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookRepository_PublishDue_RecordsRevision(t *testing.T) {
+	repo, mock, cleanup := NewMockBookRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE (status = $1 AND publish_at <= $2) AND "books"."deleted_at" IS NULL FOR UPDATE SKIP LOCKED`)).
+		WithArgs("scheduled", int64(1700000000)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "publish_at"}).AddRow(id, "scheduled", 1699999990))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(number), 0) FROM "book_revisions" WHERE book_id = $1`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "book_revisions"`)).
+		WithArgs(sqlmock.AnyArg(), id, 2, "scheduler", "Scheduled publication", `{"status":"scheduled"}`, `{"status":"published"}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "books" SET "status"=$1,"updated_at"=$2 WHERE id = $3`)).
+		WithArgs("published", sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "books" WHERE "books"."deleted_at" IS NULL AND "books"."id" = $1`)).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "publish_at"}).AddRow(id, "published", 1699999990))
+	mock.ExpectCommit()
+
+	books, err := repo.PublishDue(1700000000)
+	assert.NoError(t, err)
+	assert.Len(t, books, 1)
+	assert.Equal(t, "published", books[0].Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
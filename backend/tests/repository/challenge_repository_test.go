@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockChallengeRepository(t *testing.T) (*repository.ChallengeRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.ChallengeRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.SpentChallenge](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestChallengeRepository_Redeem(t *testing.T) {
+	repo, mock, cleanup := NewMockChallengeRepository(t)
+	defer cleanup()
+
+	insert := regexp.QuoteMeta(`INSERT INTO "spent_challenges" ("nonce","expires_at","created_at") VALUES ($1,$2,$3) ON CONFLICT DO NOTHING`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).
+		WithArgs("abc123", int64(1700000300), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	redeemed, err := repo.Redeem("abc123", 1700000300)
+	assert.NoError(t, err)
+	assert.True(t, redeemed)
+
+	// The second redemption hits the primary key and inserts nothing
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).
+		WithArgs("abc123", int64(1700000300), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	redeemed, err = repo.Redeem("abc123", 1700000300)
+	assert.NoError(t, err)
+	assert.False(t, redeemed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
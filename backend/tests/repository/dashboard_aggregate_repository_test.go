@@ -0,0 +1,75 @@
+package repository_test
+
+import (
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockDashboardAggregateRepository(t *testing.T) (*repository.DashboardAggregateRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.DashboardAggregateRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.DashboardAggregate](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestDashboardAggregateRepository_RefreshKeys_UpsertsAndDeletes(t *testing.T) {
+	repo, mock, cleanup := NewMockDashboardAggregateRepository(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "books" WHERE (status = 'published' AND deleted_at IS NULL) AND COALESCE(NULLIF(category, ''), 'Unknown') = $1`)).
+		WithArgs("Fiction").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "dashboard_aggregates"`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "books"`)).
+		WithArgs("Poetry").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "dashboard_aggregates" WHERE dimension = $1 AND key = $2`)).
+		WithArgs(utils.AggregateBooksByCategory, "Poetry").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.RefreshKeys(utils.AggregateBooksByCategory, []string{"Fiction", "Poetry"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDashboardAggregateRepository_RefreshDimension_RebuildsFromSource(t *testing.T) {
+	repo, mock, cleanup := NewMockDashboardAggregateRepository(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "dashboard_aggregates" WHERE dimension = $1`)).
+		WithArgs(utils.AggregateReviewers).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO dashboard_aggregates (dimension, key, count, updated_at) SELECT $1, name, COUNT(*), $2 FROM reviews WHERE`)).
+		WithArgs(utils.AggregateReviewers, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "dashboard_aggregate_states"`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.RefreshDimension(utils.AggregateReviewers)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDashboardAggregateRepository_RefreshKeys_UnknownDimension(t *testing.T) {
+	repo, mock, cleanup := NewMockDashboardAggregateRepository(t)
+	defer cleanup()
+
+	err := repo.RefreshKeys("books_by_colour", []string{"red"})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockDataQualityRepository(t *testing.T) (*repository.DataQualityRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.DataQualityRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.Book](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestDataQualityRepository_FindDuplicateGroups(t *testing.T) {
+	repo, mock, cleanup := NewMockDataQualityRepository(t)
+	defer cleanup()
+
+	first, second := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT string_agg(id::text, ',' ORDER BY created_at) AS ids FROM "books" WHERE "books"."deleted_at" IS NULL GROUP BY LOWER(TRIM(title)), LOWER(TRIM(author_name)) HAVING COUNT(*) > 1 ORDER BY COUNT(*) DESC`)).
+		WillReturnRows(sqlmock.NewRows([]string{"ids"}).AddRow(first.String() + "," + second.String()))
+
+	groups, err := repo.FindDuplicateGroups()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]uuid.UUID{{first, second}}, groups)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataQualityRepository_FindBooksFailing_SuspiciousPages(t *testing.T) {
+	repo, mock, cleanup := NewMockDataQualityRepository(t)
+	defer cleanup()
+
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "books" WHERE (pages IS NULL OR pages < $1 OR pages > $2) AND "books"."deleted_at" IS NULL`)).
+		WithArgs(utils.DataQualityMinPages, utils.DataQualityMaxPages).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "books" WHERE (pages IS NULL OR pages < $1 OR pages > $2) AND "books"."deleted_at" IS NULL ORDER BY created_at ASC LIMIT $3`)).
+		WithArgs(utils.DataQualityMinPages, utils.DataQualityMaxPages, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+
+	count, ids, err := repo.FindBooksFailing(utils.DataQualitySuspiciousPages, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, []uuid.UUID{id}, ids)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
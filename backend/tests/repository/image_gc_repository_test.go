@@ -0,0 +1,69 @@
+package repository_test
+
+import (
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockImageGCRepository(t *testing.T) (*repository.ImageGCRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.ImageGCRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.BlobDeletion](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestImageGCRepository_FindReferencedImages(t *testing.T) {
+	repo, mock, cleanup := NewMockImageGCRepository(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "image","images" FROM "books" WHERE image != ''`)).
+		WillReturnRows(sqlmock.NewRows([]string{"image", "images"}).
+			AddRow("https://cdn.test/books/trashed.jpg", nil).
+			AddRow("https://cdn.test/books/dune-large.jpg", `{"variants":{"large":{"jpeg":"https://cdn.test/books/dune-large.jpg","webp":"https://cdn.test/books/dune-large.webp"}}}`))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "before","after" FROM "book_revisions" WHERE created_at >= $1 AND (before ->> 'image' != '' OR after ->> 'image' != '')`)).
+		WithArgs(int64(1700000000)).
+		WillReturnRows(sqlmock.NewRows([]string{"before", "after"}).
+			AddRow(`{"image":"https://cdn.test/books/old.jpg"}`, `{"image":"https://cdn.test/books/trashed.jpg"}`))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "url","metadata" FROM "book_images"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "metadata"}).
+			AddRow("https://cdn.test/books/dune-back-large.jpg", `{"variants":{"large":{"jpeg":"https://cdn.test/books/dune-back-large.jpg","webp":"https://cdn.test/books/dune-back-large.webp"}}}`))
+
+	images, err := repo.FindReferencedImages(1700000000)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"https://cdn.test/books/old.jpg",
+		"https://cdn.test/books/trashed.jpg",
+		"https://cdn.test/books/trashed.jpg",
+		"https://cdn.test/books/dune-large.jpg",
+		"https://cdn.test/books/dune-large.jpg",
+		"https://cdn.test/books/dune-large.webp",
+		"https://cdn.test/books/dune-back-large.jpg",
+		"https://cdn.test/books/dune-back-large.jpg",
+		"https://cdn.test/books/dune-back-large.webp",
+	}, images)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImageGCRepository_SaveDeletion(t *testing.T) {
+	repo, mock, cleanup := NewMockImageGCRepository(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "blob_deletions" ("key","attempts","last_error","next_attempt_at","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT ("key") DO UPDATE SET "attempts"="excluded"."attempts","last_error"="excluded"."last_error","next_attempt_at"="excluded"."next_attempt_at","updated_at"="excluded"."updated_at"`)).
+		WithArgs("books/orphan.jpg", 2, "timeout", int64(1700000600), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.SaveDeletion(&model.BlobDeletion{Key: "books/orphan.jpg", Attempts: 2, LastError: "timeout", NextAttemptAt: 1700000600})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
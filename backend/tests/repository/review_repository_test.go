@@ -31,12 +31,12 @@ func TestReviewRepository_FindByBookID(t *testing.T) {
 		AddRow(uuid.New(), bookID, "Reviewer A", "a@example.com", "Great book!", int64(1640995200), int64(1640995200)).
 		AddRow(uuid.New(), bookID, "Reviewer B", "b@example.com", "Loved it!", int64(1640995200), int64(1640995200))
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "reviews" WHERE book_id = $1`)).
-		WithArgs(bookID).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "reviews" WHERE book_id = $1 AND status = $2 AND "reviews"."deleted_at" IS NULL`)).
+		WithArgs(bookID, "published").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "reviews" WHERE book_id = $1 LIMIT $2`)).
-		WithArgs(bookID, 10).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "reviews" WHERE book_id = $1 AND status = $2 AND "reviews"."deleted_at" IS NULL LIMIT $3`)).
+		WithArgs(bookID, "published", 10).
 		WillReturnRows(rows)
 
 	params := dto.QueryParams{
@@ -53,3 +53,19 @@ func TestReviewRepository_FindByBookID(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestReviewRepository_GetTopReviewers_SkipsAnonymized(t *testing.T) {
+	repo, mock, cleanup := NewMockReviewRepository(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT name, COUNT(*) as count FROM "reviews" WHERE (name IS NOT NULL AND name != '' AND anonymized = $1 AND status = $2) AND "reviews"."deleted_at" IS NULL GROUP BY "name" ORDER BY count DESC LIMIT $3`)).
+		WithArgs(false, "published", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "count"}).AddRow("Reviewer A", 4))
+
+	stats, err := repo.GetTopReviewers(5)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "Reviewer A", stats[0].Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
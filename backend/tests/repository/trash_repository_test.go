@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewMockTrashRepository(t *testing.T) (*repository.TrashRepositoryImpl, sqlmock.Sqlmock, func()) {
+	db, mock, cleanup := utils.NewMockDB(t)
+	repo := &repository.TrashRepositoryImpl{
+		BaseRepository: repository.NewBaseRepository[model.Book](db),
+	}
+	return repo, mock, cleanup
+}
+
+func TestTrashRepository_FindBookImages(t *testing.T) {
+	repo, mock, cleanup := NewMockTrashRepository(t)
+	defer cleanup()
+
+	bookID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "before","after" FROM "book_revisions" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"before", "after"}).
+			AddRow(`{"image":"https://cdn.test/books/old.jpg"}`, `{"image":"https://cdn.test/books/dune.jpg"}`))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "url","metadata" FROM "book_images" WHERE book_id = $1`)).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "metadata"}).
+			AddRow("https://cdn.test/books/dune-back-large.jpg", `{"variants":{"large":{"jpeg":"https://cdn.test/books/dune-back-large.jpg","webp":"https://cdn.test/books/dune-back-large.webp"}}}`))
+
+	images, err := repo.FindBookImages(bookID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"https://cdn.test/books/old.jpg",
+		"https://cdn.test/books/dune.jpg",
+		"https://cdn.test/books/dune-back-large.jpg",
+		"https://cdn.test/books/dune-back-large.jpg",
+		"https://cdn.test/books/dune-back-large.webp",
+	}, images)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReviewAlertRepo struct {
+	mock.Mock
+}
+
+func (m *MockReviewAlertRepo) FindAll(params dto.ReviewAlertQueryParams) ([]model.ReviewAlert, dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.ReviewAlert), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockReviewAlertRepo) CreateIfAbsent(alert *model.ReviewAlert) (bool, error) {
+	args := m.Called(alert)
+	return args.Bool(0), args.Error(1)
+}
+
+var testAnomalyLimits = service.AnomalyLimits{
+	ZThreshold:   3,
+	MinReviews:   5,
+	HoldDuration: 24 * time.Hour,
+}
+
+func TestAnomalyService_Analyze_FlagsSpikeAndHoldsBook(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	mockAlertRepo := new(MockReviewAlertRepo)
+	svc := service.NewAnomalyService(mockReviewRepo, mockBookRepo, mockAlertRepo, testAnomalyLimits)
+
+	now := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	windowStart := now.Add(-time.Hour).Unix()
+	bookID := uuid.New()
+
+	// One review every other hour for a week, then 20 in the last hour
+	var hourly []dto.BookHourlyCount
+	for hour := int64(0); hour < 168; hour += 2 {
+		hourly = append(hourly, dto.BookHourlyCount{BookID: bookID, Hour: hour, Count: 1})
+	}
+
+	mockReviewRepo.On("CountActiveBooksSince", windowStart, int64(5)).Return(map[uuid.UUID]int64{bookID: 20}, nil)
+	mockReviewRepo.On("CountHourlyByBook", []uuid.UUID{bookID}, windowStart-168*3600, windowStart).Return(hourly, nil)
+	mockReviewRepo.On("CountBySourceSince", mock.Anything, []uuid.UUID{bookID}, windowStart).Return([]dto.ReviewSourceCount{}, nil)
+
+	heldUntil := now.Add(24 * time.Hour).Unix()
+	mockAlertRepo.On("CreateIfAbsent", mock.MatchedBy(func(alert *model.ReviewAlert) bool {
+		return alert.Type == "volume_spike" && alert.Count == 20 && alert.Score >= 3 &&
+			alert.WindowStart == time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC).Unix()
+	})).Return(true, nil)
+	mockBookRepo.On("HoldReviews", bookID, heldUntil).Return(nil)
+
+	alerts, err := svc.Analyze(now)
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, heldUntil, alerts[0].HeldUntil)
+
+	mockAlertRepo.AssertExpectations(t)
+	mockBookRepo.AssertExpectations(t)
+}
+
+func TestAnomalyService_Analyze_FlagsDomainBurst(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	mockAlertRepo := new(MockReviewAlertRepo)
+	svc := service.NewAnomalyService(mockReviewRepo, mockBookRepo, mockAlertRepo, testAnomalyLimits)
+
+	now := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	windowStart := now.Add(-time.Hour).Unix()
+	bookID := uuid.New()
+
+	// A busy book whose baseline explains the volume, but most reviews come from one domain
+	var hourly []dto.BookHourlyCount
+	for hour := int64(0); hour < 168; hour++ {
+		hourly = append(hourly, dto.BookHourlyCount{BookID: bookID, Hour: hour, Count: 10})
+	}
+
+	mockReviewRepo.On("CountActiveBooksSince", windowStart, int64(5)).Return(map[uuid.UUID]int64{bookID: 10}, nil)
+	mockReviewRepo.On("CountHourlyByBook", []uuid.UUID{bookID}, windowStart-168*3600, windowStart).Return(hourly, nil)
+	mockReviewRepo.On("CountBySourceSince", "email_domain_burst", []uuid.UUID{bookID}, windowStart).Return([]dto.ReviewSourceCount{
+		{BookID: bookID, Source: "mailinator.com", Count: 8},
+		{BookID: bookID, Source: "example.com", Count: 2},
+	}, nil)
+	mockReviewRepo.On("CountBySourceSince", "ip_range_burst", []uuid.UUID{bookID}, windowStart).Return([]dto.ReviewSourceCount{
+		{BookID: bookID, Source: "203.0.113.0/24", Count: 4},
+	}, nil)
+
+	mockAlertRepo.On("CreateIfAbsent", mock.MatchedBy(func(alert *model.ReviewAlert) bool {
+		return alert.Type == "email_domain_burst" && alert.Subject == "mailinator.com" && alert.Score == 0.8
+	})).Return(true, nil)
+	mockBookRepo.On("HoldReviews", bookID, mock.AnythingOfType("int64")).Return(nil)
+
+	alerts, err := svc.Analyze(now)
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "mailinator.com", alerts[0].Subject)
+
+	mockAlertRepo.AssertNumberOfCalls(t, "CreateIfAbsent", 1)
+}
+
+func TestAnomalyService_Analyze_IgnoresSharedSources(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	mockAlertRepo := new(MockReviewAlertRepo)
+	limits := testAnomalyLimits
+	limits.IgnoredNetworks = utils.ParseNetworks([]string{"172.28.0.10", "198.51.100.0/22"})
+	svc := service.NewAnomalyService(mockReviewRepo, mockBookRepo, mockAlertRepo, limits)
+
+	now := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	windowStart := now.Add(-time.Hour).Unix()
+	bookID := uuid.New()
+
+	// A bestseller reviewed by many people, most of them on public email providers and behind
+	// the frontend or a carrier gateway
+	var hourly []dto.BookHourlyCount
+	for hour := int64(0); hour < 168; hour++ {
+		hourly = append(hourly, dto.BookHourlyCount{BookID: bookID, Hour: hour, Count: 40})
+	}
+
+	mockReviewRepo.On("CountActiveBooksSince", windowStart, int64(5)).Return(map[uuid.UUID]int64{bookID: 40}, nil)
+	mockReviewRepo.On("CountHourlyByBook", []uuid.UUID{bookID}, windowStart-168*3600, windowStart).Return(hourly, nil)
+	mockReviewRepo.On("CountBySourceSince", "email_domain_burst", []uuid.UUID{bookID}, windowStart).Return([]dto.ReviewSourceCount{
+		{BookID: bookID, Source: "gmail.com", Count: 24},
+		{BookID: bookID, Source: "docomo.ne.jp", Count: 9},
+		{BookID: bookID, Source: "example.com", Count: 4},
+		{BookID: bookID, Source: "example.org", Count: 3},
+	}, nil)
+	mockReviewRepo.On("CountBySourceSince", "ip_range_burst", []uuid.UUID{bookID}, windowStart).Return([]dto.ReviewSourceCount{
+		{BookID: bookID, Source: "172.28.0.0/24", Count: 22},
+		{BookID: bookID, Source: "198.51.101.0/24", Count: 12},
+		{BookID: bookID, Source: "203.0.113.0/24", Count: 6},
+	}, nil)
+
+	alerts, err := svc.Analyze(now)
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	mockAlertRepo.AssertNotCalled(t, "CreateIfAbsent", mock.Anything)
+	mockBookRepo.AssertNotCalled(t, "HoldReviews", mock.Anything, mock.Anything)
+}
+
+func TestAnomalyService_Analyze_SkipsKnownAlerts(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	mockAlertRepo := new(MockReviewAlertRepo)
+	svc := service.NewAnomalyService(mockReviewRepo, mockBookRepo, mockAlertRepo, testAnomalyLimits)
+
+	now := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	windowStart := now.Add(-time.Hour).Unix()
+	bookID := uuid.New()
+
+	mockReviewRepo.On("CountActiveBooksSince", windowStart, int64(5)).Return(map[uuid.UUID]int64{bookID: 12}, nil)
+	mockReviewRepo.On("CountHourlyByBook", mock.Anything, mock.Anything, mock.Anything).Return([]dto.BookHourlyCount{}, nil)
+	mockReviewRepo.On("CountBySourceSince", mock.Anything, mock.Anything, mock.Anything).Return([]dto.ReviewSourceCount{}, nil)
+	mockAlertRepo.On("CreateIfAbsent", mock.Anything).Return(false, nil)
+
+	alerts, err := svc.Analyze(now)
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	mockBookRepo.AssertNotCalled(t, "HoldReviews", mock.Anything, mock.Anything)
+}
+
+func TestAnomalyService_GetAlerts_InvalidType(t *testing.T) {
+	svc := service.NewAnomalyService(new(MockReviewRepo), new(MockBookRepo), new(MockReviewAlertRepo), testAnomalyLimits)
+
+	alerts, meta, err := svc.GetAlerts(dto.ReviewAlertQueryParams{Type: "surge"})
+	assert.Nil(t, alerts)
+	assert.Nil(t, meta)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+}
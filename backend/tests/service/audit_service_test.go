@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/service"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepo) Append(entry *model.AuditLog) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepo) FindAll(params dto.AuditQueryParams) ([]model.AuditLog, dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.AuditLog), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockAuditRepo) FindAfter(afterID uint64, limit int) ([]model.AuditLog, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]model.AuditLog), args.Error(1)
+}
+
+// auditChain builds n correctly linked entries
+func auditChain(n int) []model.AuditLog {
+	entries := make([]model.AuditLog, n)
+	prevHash := ""
+	for i := range entries {
+		entries[i] = model.AuditLog{
+			ID:         uint64(i + 1),
+			Actor:      "admin",
+			Method:     "PATCH",
+			Route:      "/api/books/:id",
+			Status:     200,
+			EntityType: "book",
+			Action:     "update",
+			Summary:    "Changed title",
+			CreatedAt:  int64(1700000000 + i),
+			PrevHash:   prevHash,
+		}
+		entries[i].Hash = entries[i].ComputeHash()
+		prevHash = entries[i].Hash
+	}
+	return entries
+}
+
+func TestAuditService_Verify_ValidChain(t *testing.T) {
+	mockRepo := new(MockAuditRepo)
+	svc := service.NewAuditService(mockRepo)
+
+	mockRepo.On("FindAfter", uint64(0), 500).Return(auditChain(3), nil)
+
+	result, err := svc.Verify()
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(3), result.Checked)
+	assert.Nil(t, result.BrokenAt)
+}
+
+func TestAuditService_Verify_DetectsEditedEntry(t *testing.T) {
+	mockRepo := new(MockAuditRepo)
+	svc := service.NewAuditService(mockRepo)
+
+	entries := auditChain(3)
+	entries[1].Summary = "Changed pages"
+	mockRepo.On("FindAfter", uint64(0), 500).Return(entries, nil)
+
+	result, err := svc.Verify()
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, uint64(2), *result.BrokenAt)
+}
+
+func TestAuditService_Verify_DetectsRemovedEntry(t *testing.T) {
+	mockRepo := new(MockAuditRepo)
+	svc := service.NewAuditService(mockRepo)
+
+	entries := auditChain(3)
+	mockRepo.On("FindAfter", uint64(0), 500).Return([]model.AuditLog{entries[0], entries[2]}, nil)
+
+	result, err := svc.Verify()
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, uint64(3), *result.BrokenAt)
+}
+
+func TestAuditService_GetLogs_RejectsReversedRange(t *testing.T) {
+	mockRepo := new(MockAuditRepo)
+	svc := service.NewAuditService(mockRepo)
+
+	_, _, err := svc.GetLogs(dto.AuditQueryParams{From: 1700000100, To: 1700000000})
+
+	appErr, ok := err.(*errors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	mockRepo.AssertNotCalled(t, "FindAll", mock.Anything)
+}
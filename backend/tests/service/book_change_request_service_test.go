@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookChangeRequestRepo struct {
+	mock.Mock
+}
+
+func (m *MockBookChangeRequestRepo) FindByID(id uuid.UUID) (*model.BookChangeRequest, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.BookChangeRequest), args.Error(1)
+}
+
+func (m *MockBookChangeRequestRepo) Create(request *model.BookChangeRequest) (*model.BookChangeRequest, error) {
+	args := m.Called(request)
+	return args.Get(0).(*model.BookChangeRequest), args.Error(1)
+}
+
+func (m *MockBookChangeRequestRepo) FindByStatus(params dto.BookChangeRequestQueryParams) ([]model.BookChangeRequest, dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.BookChangeRequest), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockBookChangeRequestRepo) Resolve(id uuid.UUID, status, note, actor string, at int64) (bool, error) {
+	args := m.Called(id, status, note, actor, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBookChangeRequestRepo) Reopen(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockBookChangeRequestRepo) FindByEmail(email string) ([]model.BookChangeRequest, error) {
+	args := m.Called(email)
+	return args.Get(0).([]model.BookChangeRequest), args.Error(1)
+}
+
+func (m *MockBookChangeRequestRepo) AnonymizeByEmail(email string) (int64, error) {
+	args := m.Called(email)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestBookChangeRequestService_CreateRequest_KeepsOnlyChangedFields(t *testing.T) {
+	mockRepo := new(MockBookChangeRequestRepo)
+	mockBookRepo := new(MockBookRepo)
+	bookService := service.NewBookService(mockBookRepo, new(MockBlobStore), new(MockDashboardAggregator))
+	svc := service.NewBookChangeRequestService(mockRepo, mockBookRepo, bookService)
+
+	bookID := uuid.New()
+	title := "Dune"
+	year := 1966
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Title: "Dune", PublicationYear: 1965, Status: "published"}, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(request *model.BookChangeRequest) bool {
+		return request.Status == "pending" &&
+			assert.ObjectsAreEqual(map[string]interface{}{"publication_year": float64(1965)}, request.Before) &&
+			assert.ObjectsAreEqual(map[string]interface{}{"publication_year": float64(1966)}, request.After)
+	})).Return(&model.BookChangeRequest{ID: uuid.New(), BookID: bookID, Status: "pending"}, nil)
+
+	_, err := svc.CreateRequest(bookID, &dto.BookChangeRequestCreateRequest{
+		Changes: dto.BookUpdateRequest{Title: &title, PublicationYear: &year},
+		Email:   "reader@example.com",
+	})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookChangeRequestService_CreateRequest_RejectsEditorialFields(t *testing.T) {
+	mockRepo := new(MockBookChangeRequestRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewBookChangeRequestService(mockRepo, mockBookRepo, nil)
+
+	status := "archived"
+	_, err := svc.CreateRequest(uuid.New(), &dto.BookChangeRequestCreateRequest{
+		Changes: dto.BookUpdateRequest{Status: &status},
+	})
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	mockBookRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+func TestBookChangeRequestService_AcceptRequest_AppliesThroughBookService(t *testing.T) {
+	mockRepo := new(MockBookChangeRequestRepo)
+	mockBookRepo := new(MockBookRepo)
+	bookService := service.NewBookService(mockBookRepo, new(MockBlobStore), new(MockDashboardAggregator))
+	svc := service.NewBookChangeRequestService(mockRepo, mockBookRepo, bookService)
+
+	id := uuid.New()
+	bookID := uuid.New()
+	mockRepo.On("FindByID", id).Return(&model.BookChangeRequest{
+		ID:     id,
+		BookID: bookID,
+		Status: "pending",
+		After:  map[string]interface{}{"publication_year": float64(1966)},
+	}, nil)
+	mockRepo.On("Resolve", id, "accepted", "Checked the copyright page", "moderator", mock.Anything).Return(true, nil)
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockBookRepo.On("Update", bookID, mock.MatchedBy(func(req *dto.BookUpdateRequest) bool {
+		return *req.PublicationYear == 1966 && req.Reason == "Change request "+id.String()+": Checked the copyright page"
+	}), "moderator").Return(&model.Book{ID: bookID, PublicationYear: 1966}, nil)
+
+	request, err := svc.AcceptRequest(id, &dto.BookChangeRequestReviewRequest{Note: "Checked the copyright page"}, "moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, "accepted", request.Status)
+
+	mockRepo.AssertNotCalled(t, "Reopen", mock.Anything)
+	mockBookRepo.AssertExpectations(t)
+}
+
+func TestBookChangeRequestService_AcceptRequest_ReopensWhenBookGone(t *testing.T) {
+	mockRepo := new(MockBookChangeRequestRepo)
+	mockBookRepo := new(MockBookRepo)
+	bookService := service.NewBookService(mockBookRepo, new(MockBlobStore), new(MockDashboardAggregator))
+	svc := service.NewBookChangeRequestService(mockRepo, mockBookRepo, bookService)
+
+	id := uuid.New()
+	bookID := uuid.New()
+	mockRepo.On("FindByID", id).Return(&model.BookChangeRequest{
+		ID:     id,
+		BookID: bookID,
+		Status: "pending",
+		After:  map[string]interface{}{"pages": float64(412)},
+	}, nil)
+	mockRepo.On("Resolve", id, "accepted", "", "admin", mock.Anything).Return(true, nil)
+	mockRepo.On("Reopen", id).Return(nil)
+	mockBookRepo.On("FindByID", bookID).Return((*model.Book)(nil), nil)
+
+	_, err := svc.AcceptRequest(id, &dto.BookChangeRequestReviewRequest{}, "admin")
+	assert.Equal(t, 404, err.(*errors.AppError).Code)
+
+	mockRepo.AssertCalled(t, "Reopen", id)
+}
+
+func TestBookChangeRequestService_AcceptRequest_ConflictsWhenBookChanged(t *testing.T) {
+	mockRepo := new(MockBookChangeRequestRepo)
+	mockBookRepo := new(MockBookRepo)
+	bookService := service.NewBookService(mockBookRepo, new(MockBlobStore), new(MockDashboardAggregator))
+	svc := service.NewBookChangeRequestService(mockRepo, mockBookRepo, bookService)
+
+	id := uuid.New()
+	bookID := uuid.New()
+	mockRepo.On("FindByID", id).Return(&model.BookChangeRequest{
+		ID:     id,
+		BookID: bookID,
+		Status: "pending",
+		Before: map[string]interface{}{"publication_year": float64(1965), "title": "Dune"},
+		After:  map[string]interface{}{"publication_year": float64(1966), "title": "Dune Messiah"},
+	}, nil)
+	mockRepo.On("Resolve", id, "accepted", "", "admin", mock.Anything).Return(true, nil)
+	mockRepo.On("Reopen", id).Return(nil)
+	// An editor corrected the year after the request was made
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Title: "Dune", PublicationYear: 1964, Status: "published"}, nil)
+
+	_, err := svc.AcceptRequest(id, &dto.BookChangeRequestReviewRequest{}, "admin")
+	assert.Equal(t, 409, err.(*errors.AppError).Code)
+	assert.Contains(t, err.(*errors.AppError).Message, "publication_year")
+	assert.NotContains(t, err.(*errors.AppError).Message, "title")
+
+	mockRepo.AssertCalled(t, "Reopen", id)
+	mockBookRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookChangeRequestService_RejectRequest_RequiresNote(t *testing.T) {
+	mockRepo := new(MockBookChangeRequestRepo)
+	svc := service.NewBookChangeRequestService(mockRepo, new(MockBookRepo), nil)
+
+	_, err := svc.RejectRequest(uuid.New(), &dto.BookChangeRequestReviewRequest{Note: "  "}, "admin")
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	mockRepo.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookChangeRequestService_RejectRequest_AlreadyResolved(t *testing.T) {
+	mockRepo := new(MockBookChangeRequestRepo)
+	svc := service.NewBookChangeRequestService(mockRepo, new(MockBookRepo), nil)
+
+	id := uuid.New()
+	mockRepo.On("FindByID", id).Return(&model.BookChangeRequest{ID: id, Status: "accepted"}, nil)
+
+	_, err := svc.RejectRequest(id, &dto.BookChangeRequestReviewRequest{Note: "Wrong edition"}, "admin")
+	assert.Equal(t, 409, err.(*errors.AppError).Code)
+}
@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockBookImageRepo struct {
+	mock.Mock
+}
+
+func (m *MockBookImageRepo) FindByID(id uuid.UUID) (*model.BookImage, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.BookImage), args.Error(1)
+}
+
+func (m *MockBookImageRepo) FindByBook(bookID uuid.UUID) ([]model.BookImage, error) {
+	args := m.Called(bookID)
+	return args.Get(0).([]model.BookImage), args.Error(1)
+}
+
+func (m *MockBookImageRepo) Create(image *model.BookImage) (*model.BookImage, error) {
+	args := m.Called(image)
+	return args.Get(0).(*model.BookImage), args.Error(1)
+}
+
+func (m *MockBookImageRepo) Update(id uuid.UUID, updates map[string]interface{}) (*model.BookImage, error) {
+	args := m.Called(id, updates)
+	return args.Get(0).(*model.BookImage), args.Error(1)
+}
+
+func (m *MockBookImageRepo) Reorder(bookID uuid.UUID, ids []uuid.UUID) error {
+	args := m.Called(bookID, ids)
+	return args.Error(0)
+}
+
+func (m *MockBookImageRepo) Delete(image *model.BookImage) error {
+	args := m.Called(image)
+	return args.Error(0)
+}
+
+func newBookImageService(store repository.BlobStore) (service.BookImageService, *MockBookImageRepo, *MockBookRepo) {
+	imageRepo := new(MockBookImageRepo)
+	bookRepo := new(MockBookRepo)
+	bookService := service.NewBookService(bookRepo, store, new(MockDashboardAggregator))
+	return service.NewBookImageService(imageRepo, store, bookService), imageRepo, bookRepo
+}
+
+func TestBookImageService_AddImage_FirstFrontCoverBecomesPrimary(t *testing.T) {
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc, imageRepo, bookRepo := newBookImageService(store)
+
+	book := &model.Book{ID: uuid.New(), Title: "Book A", Image: "https://covers.example.com/old.jpg"}
+	bookRepo.On("FindByID", book.ID).Return(book, nil)
+
+	// The gallery read after creating returns the created image
+	var created *model.BookImage
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{}, nil).Once()
+	afterCreate := imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{}, nil)
+	imageRepo.On("Create", mock.AnythingOfType("*model.BookImage")).
+		Run(func(args mock.Arguments) {
+			created = args.Get(0).(*model.BookImage)
+			afterCreate.ReturnArguments = mock.Arguments{[]model.BookImage{*created}, nil}
+		}).
+		Return(&model.BookImage{}, nil)
+
+	var update *dto.BookUpdateRequest
+	bookRepo.On("Update", book.ID, mock.AnythingOfType("*dto.BookUpdateRequest"), "admin").
+		Run(func(args mock.Arguments) { update = args.Get(1).(*dto.BookUpdateRequest) }).
+		Return(book, nil)
+
+	fileHeader := newFileHeader(t, "front.png", "image/png", encodeTestPNG(t, 400, 600))
+	req := &dto.BookImageCreateRequest{Role: "front", AltEn: "Front cover", AltJa: "表紙"}
+
+	_, err := svc.AddImage(book.ID, req, fileHeader, "admin")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, created.Position)
+	assert.Equal(t, "表紙", created.AltJa)
+	key, ok := store.Key(created.URL)
+	require.True(t, ok)
+	assert.True(t, utils.IsContentKey(key), key)
+	require.NotNil(t, created.Metadata)
+	assert.Equal(t, created.URL, created.Metadata.Variants["large"].JPEG)
+
+	require.NotNil(t, update)
+	assert.Equal(t, created.URL, *update.Image)
+	assert.Equal(t, created.Metadata, update.Images)
+}
+
+func TestBookImageService_AddImage_RejectsInvalidRequests(t *testing.T) {
+	svc, _, _ := newBookImageService(repository.NewMemoryBlobStore(mockBlobBaseURL))
+	fileHeader := newFileHeader(t, "front.png", "image/png", encodeTestPNG(t, 400, 600))
+
+	_, err := svc.AddImage(uuid.New(), &dto.BookImageCreateRequest{Role: "poster"}, fileHeader, "admin")
+	require.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	_, err = svc.AddImage(uuid.New(), &dto.BookImageCreateRequest{Role: "back"}, nil, "admin")
+	require.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	_, err = svc.AddImage(uuid.New(), &dto.BookImageCreateRequest{Role: "back", AltEn: strings.Repeat("a", 501)}, fileHeader, "admin")
+	require.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+}
+
+func TestBookImageService_ReorderImages(t *testing.T) {
+	svc, imageRepo, bookRepo := newBookImageService(new(MockBlobStore))
+
+	first := model.BookImage{ID: uuid.New(), Role: "front", Position: 0, URL: mockBlobBaseURL + "/books/first.jpg"}
+	second := model.BookImage{ID: uuid.New(), Role: "front", Position: 1, URL: mockBlobBaseURL + "/books/second.jpg"}
+	book := &model.Book{ID: uuid.New(), Image: first.URL}
+	bookRepo.On("FindByID", book.ID).Return(book, nil)
+
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{first, second}, nil).Once()
+	_, err := svc.ReorderImages(book.ID, &dto.BookImageOrderRequest{IDs: []uuid.UUID{second.ID}}, "admin")
+	require.Error(t, err, "every image must be listed")
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{first, second}, nil).Once()
+	imageRepo.On("Reorder", book.ID, []uuid.UUID{second.ID, first.ID}).Return(nil)
+	second.Position, first.Position = 0, 1
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{second, first}, nil)
+	bookRepo.On("Update", book.ID, mock.MatchedBy(func(req *dto.BookUpdateRequest) bool {
+		return req.Image != nil && *req.Image == second.URL
+	}), "admin").Return(book, nil)
+
+	images, err := svc.ReorderImages(book.ID, &dto.BookImageOrderRequest{IDs: []uuid.UUID{second.ID, first.ID}}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{second.ID, first.ID}, []uuid.UUID{images[0].ID, images[1].ID})
+	bookRepo.AssertExpectations(t)
+}
+
+func TestBookImageService_DeleteImage_ClearsPrimaryFromGallery(t *testing.T) {
+	svc, imageRepo, bookRepo := newBookImageService(new(MockBlobStore))
+
+	front := model.BookImage{ID: uuid.New(), Role: "front", URL: mockBlobBaseURL + "/books/front.jpg"}
+	book := &model.Book{ID: uuid.New(), Image: front.URL}
+	front.BookID = book.ID
+	bookRepo.On("FindByID", book.ID).Return(book, nil)
+	imageRepo.On("FindByID", front.ID).Return(&front, nil)
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{front}, nil).Once()
+	imageRepo.On("Delete", &front).Return(nil)
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{}, nil)
+	bookRepo.On("Update", book.ID, mock.MatchedBy(func(req *dto.BookUpdateRequest) bool {
+		return req.Image != nil && *req.Image == "" && req.Images == nil
+	}), "admin").Return(book, nil)
+
+	require.NoError(t, svc.DeleteImage(book.ID, front.ID, "admin"))
+	bookRepo.AssertExpectations(t)
+}
+
+func TestBookImageService_DeleteImage_KeepsCoverSetOnTheBook(t *testing.T) {
+	svc, imageRepo, bookRepo := newBookImageService(new(MockBlobStore))
+
+	back := model.BookImage{ID: uuid.New(), Role: "back", URL: mockBlobBaseURL + "/books/back.jpg"}
+	book := &model.Book{ID: uuid.New(), Image: "https://covers.example.com/cover.jpg"}
+	back.BookID = book.ID
+	bookRepo.On("FindByID", book.ID).Return(book, nil)
+	imageRepo.On("FindByID", back.ID).Return(&back, nil)
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{back}, nil).Once()
+	imageRepo.On("Delete", &back).Return(nil)
+	imageRepo.On("FindByBook", book.ID).Return([]model.BookImage{}, nil)
+
+	require.NoError(t, svc.DeleteImage(book.ID, back.ID, "admin"))
+	bookRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookImageService_DeleteImage_OfAnotherBook(t *testing.T) {
+	svc, imageRepo, bookRepo := newBookImageService(new(MockBlobStore))
+
+	book := &model.Book{ID: uuid.New()}
+	image := &model.BookImage{ID: uuid.New(), BookID: uuid.New()}
+	bookRepo.On("FindByID", book.ID).Return(book, nil)
+	imageRepo.On("FindByID", image.ID).Return(image, nil)
+
+	err := svc.DeleteImage(book.ID, image.ID, "admin")
+	require.Error(t, err)
+	assert.Equal(t, 404, err.(*errors.AppError).Code)
+	imageRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
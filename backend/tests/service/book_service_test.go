@@ -1,16 +1,30 @@
 package service_test
 
 import (
+	"bytes"
+	goerrors "errors"
 	"honya/backend/dto"
 	"honya/backend/errors"
 	"honya/backend/model"
+	"honya/backend/repository"
 	"honya/backend/service"
+	"honya/backend/utils"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
 	"mime/multipart"
+	"net/textproto"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
 )
 
 type MockBookRepo struct {
@@ -32,8 +46,53 @@ func (m *MockBookRepo) Create(book *model.Book) (*model.Book, error) {
 	return args.Get(0).(*model.Book), args.Error(1)
 }
 
-func (m *MockBookRepo) Update(id uuid.UUID, updateData *dto.BookUpdateRequest) (*model.Book, error) {
-	args := m.Called(id, updateData)
+func (m *MockBookRepo) HoldReviews(id uuid.UUID, until int64) error {
+	args := m.Called(id, until)
+	return args.Error(0)
+}
+
+func (m *MockBookRepo) FindDuplicateCandidates() ([]model.Book, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockBookRepo) Merge(targetID, sourceID uuid.UUID, updates map[string]interface{}, actor string) (*model.Book, error) {
+	args := m.Called(targetID, sourceID, updates, actor)
+	return args.Get(0).(*model.Book), args.Error(1)
+}
+
+func (m *MockBookRepo) FindRedirect(id uuid.UUID) (*model.BookRedirect, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.BookRedirect), args.Error(1)
+}
+
+func (m *MockBookRepo) FindRevisions(bookID uuid.UUID, offset, limit int) ([]model.BookRevision, dto.PaginationMeta, error) {
+	args := m.Called(bookID, offset, limit)
+	return args.Get(0).([]model.BookRevision), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockBookRepo) FindRevisionsSince(bookID uuid.UUID, number int) ([]model.BookRevision, error) {
+	args := m.Called(bookID, number)
+	return args.Get(0).([]model.BookRevision), args.Error(1)
+}
+
+func (m *MockBookRepo) Restore(id uuid.UUID) (*model.Book, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.Book), args.Error(1)
+}
+
+func (m *MockBookRepo) FindReviewerNames(bookID uuid.UUID) ([]string, error) {
+	args := m.Called(bookID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockBookRepo) PublishDue(now int64) ([]model.Book, error) {
+	args := m.Called(now)
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockBookRepo) Update(id uuid.UUID, updateData *dto.BookUpdateRequest, actor string) (*model.Book, error) {
+	args := m.Called(id, updateData, actor)
 	return args.Get(0).(*model.Book), args.Error(1)
 }
 
@@ -47,26 +106,140 @@ func (m *MockBookRepo) CountByField(field string) (map[string]int64, error) {
 	return args.Get(0).(map[string]int64), args.Error(1)
 }
 
-type MockS3Repo struct {
+func (m *MockBookRepo) CountByInterval(interval, timezone string, from, to int64) (map[string]int64, error) {
+	args := m.Called(interval, timezone, from, to)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockBookRepo) Aggregate(query *dto.AnalyticsQueryRequest) ([]map[string]interface{}, error) {
+	args := m.Called(query)
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
+func (m *MockBookRepo) CountByWidth(field string, width float64) (map[int64]int64, error) {
+	args := m.Called(field, width)
+	return args.Get(0).(map[int64]int64), args.Error(1)
+}
+
+func (m *MockBookRepo) CountByEdges(field string, edges []float64) (map[int64]int64, error) {
+	args := m.Called(field, edges)
+	return args.Get(0).(map[int64]int64), args.Error(1)
+}
+
+// MockBlobStore hands out URLs below mockBlobBaseURL; storage calls are mocked
+const mockBlobBaseURL = "https://cdn.test"
+
+type MockBlobStore struct {
 	mock.Mock
 }
 
-// UPDATED: UploadImage now requires fileHeader and bookName only
-func (m *MockS3Repo) UploadImage(fileHeader *multipart.FileHeader, bookName string) (string, error) {
-	args := m.Called(fileHeader, bookName)
+func (m *MockBlobStore) Put(key string, body io.Reader, contentType string) (string, error) {
+	args := m.Called(key, body, contentType)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockS3Repo) DeleteImage(key string) error {
+func (m *MockBlobStore) Delete(key string) error {
 	args := m.Called(key)
 	return args.Error(0)
 }
 
+func (m *MockBlobStore) Exists(key string) (bool, error) {
+	args := m.Called(key)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBlobStore) Touch(key string) (bool, error) {
+	args := m.Called(key)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBlobStore) List(prefix string) ([]repository.BlobObject, error) {
+	args := m.Called(prefix)
+	return args.Get(0).([]repository.BlobObject), args.Error(1)
+}
+
+func (m *MockBlobStore) Open(key string) (io.ReadCloser, error) {
+	args := m.Called(key)
+	body, _ := args.Get(0).(io.ReadCloser)
+	return body, args.Error(1)
+}
+
+func (m *MockBlobStore) PresignPut(key, contentType string, size int64, expires time.Duration) (string, error) {
+	args := m.Called(key, contentType, size, expires)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockBlobStore) URL(key string) string {
+	return mockBlobBaseURL + "/" + key
+}
+
+func (m *MockBlobStore) Key(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, mockBlobBaseURL+"/")
+	return key, ok && key != ""
+}
+
+// newFileHeader builds the header of a file uploaded through a multipart form
+func newFileHeader(t *testing.T, filename, contentType string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="image"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["image"][0]
+}
+
+// testImage is a gradient, so that resized and re-encoded variants are not trivially flat
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / width), uint8(y * 255 / height), 120, 255})
+		}
+	}
+	return img
+}
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(width, height)))
+	return buf.Bytes()
+}
+
+func encodeTestJPEG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(width, height), nil))
+	return buf.Bytes()
+}
+
+// withExifOrientation inserts an EXIF segment holding only the orientation tag after the SOI marker
+func withExifOrientation(t *testing.T, data []byte, orientation uint16) []byte {
+	require.True(t, bytes.HasPrefix(data, []byte{0xFF, 0xD8}))
+
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	tiff = append(tiff, 0x01, 0x12, 0, 3, 0, 0, 0, 1, byte(orientation>>8), byte(orientation), 0, 0)
+	tiff = append(tiff, 0, 0, 0, 0)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+
+	result := append([]byte{0xFF, 0xD8}, segment...)
+	return append(result, data[2:]...)
+}
+
 func TestBookService_GetBooks(t *testing.T) {
 	mockRepo := new(MockBookRepo)
-	mockS3 := new(MockS3Repo)
+	mockStore := new(MockBlobStore)
 
-	svc := service.NewBookService(mockRepo, mockS3)
+	svc := service.NewBookService(mockRepo, mockStore, new(MockDashboardAggregator))
 
 	params := dto.BookQueryParams{Limit: 10, Offset: 0}
 
@@ -88,8 +261,8 @@ func TestBookService_GetBooks(t *testing.T) {
 
 func TestBookService_GetBookByID_NotFound(t *testing.T) {
 	mockRepo := new(MockBookRepo)
-	mockS3 := new(MockS3Repo)
-	svc := service.NewBookService(mockRepo, mockS3)
+	mockStore := new(MockBlobStore)
+	svc := service.NewBookService(mockRepo, mockStore, new(MockDashboardAggregator))
 
 	bookID := uuid.New()
 	mockRepo.On("FindByID", bookID).Return((*model.Book)(nil), nil)
@@ -104,8 +277,8 @@ func TestBookService_GetBookByID_NotFound(t *testing.T) {
 
 func TestBookService_CreateBook_WithImage(t *testing.T) {
 	mockRepo := new(MockBookRepo)
-	mockS3 := new(MockS3Repo)
-	svc := service.NewBookService(mockRepo, mockS3)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewBookService(mockRepo, store, new(MockDashboardAggregator))
 
 	req := &dto.BookCreateRequest{
 		Title:           "Book A",
@@ -117,28 +290,166 @@ func TestBookService_CreateBook_WithImage(t *testing.T) {
 		Isbn:            "12345",
 	}
 
-	fileHeader := &multipart.FileHeader{}
-
-	// Expect UploadImage with fileHeader and book title only
-	mockS3.On("UploadImage", fileHeader, req.Title).
-		Return("s3://bucket/book.png", nil)
+	fileHeader := newFileHeader(t, "cover.png", "image/png", encodeTestPNG(t, 800, 1600))
 
+	var saved *model.Book
 	mockRepo.On("Create", mock.AnythingOfType("*model.Book")).
-		Return(&model.Book{Title: req.Title, Image: "s3://bucket/book.png"}, nil)
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*model.Book) }).
+		Return(&model.Book{Title: req.Title}, nil)
 
 	book, err := svc.CreateBook(req, fileHeader)
 	assert.NoError(t, err)
 	assert.Equal(t, "Book A", book.Title)
-	assert.Equal(t, "s3://bucket/book.png", book.Image)
 
-	mockS3.AssertExpectations(t)
+	require.NotNil(t, saved.Images)
+	assert.Equal(t, 800, saved.Images.Width)
+	assert.Equal(t, 1600, saved.Images.Height)
+	assert.NotEmpty(t, saved.Images.Blurhash)
+	assert.Regexp(t, `^#[0-9a-f]{6}$`, saved.Images.DominantColor)
+	assert.Equal(t, saved.Images.Variants["large"].JPEG, saved.Image)
+
+	sizes := map[string][2]int{"thumbnail": {100, 200}, "medium": {300, 600}, "large": {600, 1200}}
+	for name, size := range sizes {
+		variant := saved.Images.Variants[name]
+		assert.Equal(t, size, [2]int{variant.Width, variant.Height}, name)
+
+		key, ok := store.Key(variant.JPEG)
+		require.True(t, ok)
+		assert.True(t, utils.IsContentKey(key), key)
+		assert.True(t, strings.HasSuffix(key, ".jpg"))
+		data, contentType, ok := store.Get(key)
+		require.True(t, ok)
+		assert.Equal(t, "image/jpeg", contentType)
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, size, [2]int{config.Width, config.Height}, name)
+
+		key, ok = store.Key(variant.WebP)
+		require.True(t, ok)
+		data, contentType, ok = store.Get(key)
+		require.True(t, ok)
+		assert.Equal(t, "image/webp", contentType)
+		config, err = webp.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, size, [2]int{config.Width, config.Height}, name)
+		// Lossy WebP data is held in a "VP8 " chunk, lossless data in "VP8L"
+		require.Greater(t, len(data), 16)
+		assert.Equal(t, "VP8 ", string(data[12:16]), name)
+	}
+
 	mockRepo.AssertExpectations(t)
 }
 
+func TestBookService_CreateBook_RejectsContentThatIsNotAnImage(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewBookService(mockRepo, store, new(MockDashboardAggregator))
+
+	req := &dto.BookCreateRequest{Title: "Book A", AuthorName: "Author", Category: "fiction", PublicationYear: 2000, Rating: 4, Pages: 300, Isbn: "12345"}
+	// The extension and content type claim PNG; the bytes decide
+	fileHeader := newFileHeader(t, "cover.png", "image/png", []byte("<?php system($_GET['c']); ?>"))
+
+	_, err := svc.CreateBook(req, fileHeader)
+	require.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestBookService_CreateBook_AppliesExifOrientation(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewBookService(mockRepo, store, new(MockDashboardAggregator))
+
+	req := &dto.BookCreateRequest{Title: "Book A", AuthorName: "Author", Category: "fiction", PublicationYear: 2000, Rating: 4, Pages: 300, Isbn: "12345"}
+	// A landscape sensor image tagged "rotate 90° clockwise", as phones produce
+	fileHeader := newFileHeader(t, "cover.jpg", "image/jpeg", withExifOrientation(t, encodeTestJPEG(t, 400, 200), 6))
+
+	var saved *model.Book
+	mockRepo.On("Create", mock.AnythingOfType("*model.Book")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*model.Book) }).
+		Return(&model.Book{Title: req.Title}, nil)
+
+	_, err := svc.CreateBook(req, fileHeader)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, saved.Images.Width)
+	assert.Equal(t, 400, saved.Images.Height)
+
+	key, _ := store.Key(saved.Image)
+	data, _, _ := store.Get(key)
+	assert.NotContains(t, string(data), "Exif")
+	config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 200, config.Width)
+	assert.Equal(t, 400, config.Height)
+}
+
+func TestBookService_CreateBook_StoresTheSameCoverOnce(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewBookService(mockRepo, store, new(MockDashboardAggregator))
+
+	cover := encodeTestPNG(t, 400, 600)
+	var saved []*model.Book
+	mockRepo.On("Create", mock.AnythingOfType("*model.Book")).
+		Run(func(args mock.Arguments) { saved = append(saved, args.Get(0).(*model.Book)) }).
+		Return(&model.Book{}, nil)
+
+	for _, title := range []string{"Book A", "Book B"} {
+		req := &dto.BookCreateRequest{Title: title, AuthorName: "Author", Category: "fiction", PublicationYear: 2000, Rating: 4, Pages: 300, Isbn: title}
+		_, err := svc.CreateBook(req, newFileHeader(t, "cover.png", "image/png", cover))
+		require.NoError(t, err)
+	}
+
+	require.Len(t, saved, 2)
+	assert.Equal(t, saved[0].Image, saved[1].Image)
+	assert.Equal(t, saved[0].Images, saved[1].Images)
+
+	// Variants with the same bytes, like a medium variant as large as the original, are shared too
+	urls := map[string]bool{}
+	for _, url := range saved[0].Images.URLs() {
+		urls[url] = true
+	}
+	objects, err := store.List("books/")
+	require.NoError(t, err)
+	assert.Len(t, objects, len(urls))
+}
+
+func TestBookService_CreateBook_KeepsSharedCoverWhenSaveFails(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewBookService(mockRepo, store, new(MockDashboardAggregator))
+
+	cover := encodeTestPNG(t, 64, 64)
+	var saved *model.Book
+	mockRepo.On("Create", mock.AnythingOfType("*model.Book")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*model.Book) }).
+		Return(&model.Book{}, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*model.Book")).
+		Return((*model.Book)(nil), goerrors.New("connection reset"))
+
+	req := &dto.BookCreateRequest{Title: "Book A", AuthorName: "Author", Category: "fiction", PublicationYear: 2000, Rating: 4, Pages: 300, Isbn: "12345"}
+	_, err := svc.CreateBook(req, newFileHeader(t, "cover.png", "image/png", cover))
+	require.NoError(t, err)
+
+	// The same cover for a book that fails to save must not take the first book's files along
+	req = &dto.BookCreateRequest{Title: "Book B", AuthorName: "Author", Category: "fiction", PublicationYear: 2000, Rating: 4, Pages: 300, Isbn: "67890"}
+	_, err = svc.CreateBook(req, newFileHeader(t, "cover.png", "image/png", cover))
+	assert.Error(t, err)
+
+	require.NotNil(t, saved.Images)
+	for _, url := range saved.Images.URLs() {
+		key, ok := store.Key(url)
+		require.True(t, ok)
+		exists, _ := store.Exists(key)
+		assert.True(t, exists, key)
+	}
+}
+
 func TestBookService_DeleteBook_NotFound(t *testing.T) {
 	mockRepo := new(MockBookRepo)
-	mockS3 := new(MockS3Repo)
-	svc := service.NewBookService(mockRepo, mockS3)
+	mockStore := new(MockBlobStore)
+	svc := service.NewBookService(mockRepo, mockStore, new(MockDashboardAggregator))
 
 	bookID := uuid.New()
 	mockRepo.On("FindByID", bookID).Return((*model.Book)(nil), nil)
@@ -148,5 +459,298 @@ func TestBookService_DeleteBook_NotFound(t *testing.T) {
 	assert.Equal(t, 404, err.(*errors.AppError).Code)
 
 	mockRepo.AssertExpectations(t)
-	mockS3.AssertExpectations(t)
+	mockStore.AssertExpectations(t)
+}
+
+func TestBookService_DeleteBook_RecountsItsReviewers(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	mockAggregator := new(MockDashboardAggregator)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), mockAggregator)
+
+	book := &model.Book{ID: uuid.New(), Category: "fiction"}
+	mockRepo.On("FindByID", book.ID).Return(book, nil)
+	mockRepo.On("FindReviewerNames", book.ID).Return([]string{"Alice", "Bob"}, nil)
+	mockRepo.On("Delete", book.ID).Return(nil)
+
+	err := svc.DeleteBook(book.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Book{book}, mockAggregator.ChangedBooks)
+	assert.Equal(t, []string{"Alice", "Bob"}, mockAggregator.ChangedReviewers)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookService_UpdateBook_RefreshesOldAndNewAggregates(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	mockAggregator := new(MockDashboardAggregator)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), mockAggregator)
+
+	bookID := uuid.New()
+	category := "poetry"
+	existing := &model.Book{ID: bookID, Category: "fiction", Rating: 4}
+	updated := &model.Book{ID: bookID, Category: category, Rating: 4}
+	req := &dto.BookUpdateRequest{Category: &category}
+
+	mockRepo.On("FindByID", bookID).Return(existing, nil)
+	mockRepo.On("Update", bookID, req, "admin").Return(updated, nil)
+
+	_, err := svc.UpdateBook(bookID, req, nil, "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Book{existing, updated}, mockAggregator.ChangedBooks)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookService_FindDuplicates(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	original := model.Book{ID: uuid.New(), Title: "The Name of the Wind", AuthorName: "Patrick Rothfuss", Isbn: "978-0-7564-0407-9"}
+	respelled := model.Book{ID: uuid.New(), Title: "Name of the Wind!", AuthorName: "Patrick Rothfus", Isbn: "0000000000"}
+	otherEdition := model.Book{ID: uuid.New(), Title: "Wind Name", AuthorName: "P. Rothfuss", Isbn: "0-7564-0407-X"}
+	unrelated := model.Book{ID: uuid.New(), Title: "The Wise Man's Fear", AuthorName: "Patrick Rothfuss", Isbn: "9780756404734"}
+
+	mockRepo.On("FindDuplicateCandidates").Return([]model.Book{original, respelled, otherEdition, unrelated}, nil)
+
+	duplicates, err := svc.FindDuplicates()
+	assert.NoError(t, err)
+	assert.Len(t, duplicates, 2)
+
+	assert.Equal(t, original.ID, duplicates[0].Book.ID)
+	assert.Equal(t, otherEdition.ID, duplicates[0].Duplicate.ID)
+	assert.Equal(t, []string{"isbn"}, duplicates[0].Reasons)
+	assert.Equal(t, 1.0, duplicates[0].Score)
+
+	assert.Equal(t, original.ID, duplicates[1].Book.ID)
+	assert.Equal(t, respelled.ID, duplicates[1].Duplicate.ID)
+	assert.Equal(t, []string{"title_author"}, duplicates[1].Reasons)
+}
+
+func TestBookService_MergeBooks_FillEmpty(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	mockStore := new(MockBlobStore)
+	mockAggregator := new(MockDashboardAggregator)
+	svc := service.NewBookService(mockRepo, mockStore, mockAggregator)
+
+	target := &model.Book{ID: uuid.New(), Title: "Dune", Description: "", Image: "", Pages: 412}
+	source := &model.Book{ID: uuid.New(), Title: "Dune!", Description: "Spice", Image: "source.png", Pages: 400}
+	merged := &model.Book{ID: target.ID, Title: "Dune", Description: "Spice", Image: "source.png", Pages: 412}
+
+	mockRepo.On("FindByID", target.ID).Return(target, nil)
+	mockRepo.On("FindByID", source.ID).Return(source, nil)
+	mockRepo.On("FindReviewerNames", source.ID).Return([]string{"Alice", "Bob"}, nil)
+	mockRepo.On("Merge", target.ID, source.ID, map[string]interface{}{
+		"description": "Spice",
+		"image":       "source.png",
+		"images":      (*model.BookImages)(nil),
+	}, "moderator").Return(merged, nil)
+
+	book, err := svc.MergeBooks(target.ID, &dto.BookMergeRequest{SourceID: source.ID, Strategy: "fill_empty"}, "moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, merged, book)
+	assert.Equal(t, []*model.Book{source, target, merged}, mockAggregator.ChangedBooks)
+	// Only the reviewers of the moved reviews are recounted
+	assert.Equal(t, []string{"Alice", "Bob"}, mockAggregator.ChangedReviewers)
+
+	// The source's cover moved to the target, so nothing is deleted
+	mockStore.AssertNotCalled(t, "Delete", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookService_MergeBooks_LeavesReplacedCoverToTheImageGC(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	mockStore := new(MockBlobStore)
+	svc := service.NewBookService(mockRepo, mockStore, new(MockDashboardAggregator))
+
+	target := &model.Book{ID: uuid.New(), Title: "Dune", Image: mockBlobBaseURL + "/books/target.png"}
+	source := &model.Book{ID: uuid.New(), Title: "Dune", Image: mockBlobBaseURL + "/books/source.png"}
+
+	mockRepo.On("FindByID", target.ID).Return(target, nil)
+	mockRepo.On("FindByID", source.ID).Return(source, nil)
+	mockRepo.On("FindReviewerNames", source.ID).Return([]string{}, nil)
+	mockRepo.On("Merge", target.ID, source.ID, map[string]interface{}{}, "moderator").Return(target, nil)
+
+	_, err := svc.MergeBooks(target.ID, &dto.BookMergeRequest{SourceID: source.ID}, "moderator")
+	assert.NoError(t, err)
+
+	// An upload of the same bytes may be reusing the blob, so only the collector deletes it
+	mockStore.AssertNotCalled(t, "Delete", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookService_MergeBooks_Invalid(t *testing.T) {
+	svc := service.NewBookService(new(MockBookRepo), new(MockBlobStore), new(MockDashboardAggregator))
+	bookID := uuid.New()
+
+	_, err := svc.MergeBooks(bookID, &dto.BookMergeRequest{SourceID: bookID}, "moderator")
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	_, err = svc.MergeBooks(bookID, &dto.BookMergeRequest{SourceID: uuid.New(), Strategy: "newest"}, "moderator")
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+}
+
+func TestBookService_RestoreRevision_RollsBackLaterRevisions(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	existing := &model.Book{ID: bookID, Title: "Dune (Deluxe)", Image: "new.png", Pages: 500}
+	restored := &model.Book{ID: bookID, Title: "Dune", Image: "old.png", Pages: 400}
+
+	mockRepo.On("FindByID", bookID).Return(existing, nil)
+	mockRepo.On("FindRevisionsSince", bookID, 2).Return([]model.BookRevision{
+		{Number: 2, Before: map[string]interface{}{"title": "Dune", "image": "old.png"}, After: map[string]interface{}{"title": "Dune (Deluxe)", "image": "new.png"}},
+		{Number: 3, Before: map[string]interface{}{"title": "Dune (Deluxe) ", "pages": 400.0}, After: map[string]interface{}{"title": "Dune (Deluxe)", "pages": 500.0}},
+	}, nil)
+
+	title, image, pages := "Dune", "old.png", 400
+	mockRepo.On("Update", bookID, &dto.BookUpdateRequest{
+		Title:  &title,
+		Image:  &image,
+		Pages:  &pages,
+		Reason: "Restored revision 2: vandalism",
+	}, "moderator").Return(restored, nil)
+
+	book, err := svc.RestoreRevision(bookID, 2, &dto.BookRevisionRestoreRequest{Reason: "vandalism"}, "moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, restored, book)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookService_RestoreRevision_KeepsCurrentCoverWhenOldOneWasCollected(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewBookService(mockRepo, store, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	current := mockBlobBaseURL + "/books/current.jpg"
+	collected := mockBlobBaseURL + "/books/collected.jpg"
+	existing := &model.Book{ID: bookID, Title: "Dune (Deluxe)", Image: current}
+
+	mockRepo.On("FindByID", bookID).Return(existing, nil)
+	mockRepo.On("FindRevisionsSince", bookID, 1).Return([]model.BookRevision{
+		{Number: 1, Before: map[string]interface{}{"title": "Dune", "image": collected, "images": nil}, After: map[string]interface{}{"title": "Dune (Deluxe)", "image": current}},
+	}, nil)
+
+	// The revision is older than the image GC keeps covers for, so its cover is gone
+	title := "Dune"
+	mockRepo.On("Update", bookID, &dto.BookUpdateRequest{
+		Title:  &title,
+		Reason: "Restored revision 1",
+	}, "admin").Return(&model.Book{ID: bookID, Title: "Dune", Image: current}, nil)
+
+	book, err := svc.RestoreRevision(bookID, 1, &dto.BookRevisionRestoreRequest{}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, current, book.Image)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookService_RestoreRevision_NotFound(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	mockRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID}, nil)
+	mockRepo.On("FindRevisionsSince", bookID, 7).Return([]model.BookRevision{}, nil)
+
+	_, err := svc.RestoreRevision(bookID, 7, &dto.BookRevisionRestoreRequest{}, "admin")
+	assert.Equal(t, 404, err.(*errors.AppError).Code)
+}
+
+func TestBookService_GetRevisions_HidesDrafts(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	mockRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: utils.BookStatusDraft}, nil)
+	mockRepo.On("FindRevisions", bookID, 0, 10).Return([]model.BookRevision{}, dto.PaginationMeta{Limit: 10}, nil)
+
+	_, _, err := svc.GetRevisions(bookID, 0, 10, false)
+	require.Error(t, err)
+	assert.Equal(t, 404, err.(*errors.AppError).Code)
+	mockRepo.AssertNotCalled(t, "FindRevisions", bookID, 0, 10)
+
+	_, _, err = svc.GetRevisions(bookID, 0, 10, true)
+	assert.NoError(t, err)
+}
+
+func TestBookService_RestoreBook_NotInTrash(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	mockRepo.On("FindReviewerNames", bookID).Return([]string{}, nil)
+	mockRepo.On("Restore", bookID).Return((*model.Book)(nil), nil)
+
+	_, err := svc.RestoreBook(bookID)
+	assert.Equal(t, 404, err.(*errors.AppError).Code)
+}
+
+func TestBookService_CreateBook_ScheduledInThePast(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	req := &dto.BookCreateRequest{
+		Title:           "Dune",
+		Category:        "fiction",
+		PublicationYear: 2020,
+		Pages:           412,
+		Isbn:            "9780441013593",
+		AuthorName:      "Frank Herbert",
+		Status:          "scheduled",
+		PublishAt:       time.Now().Add(-time.Hour).Unix(),
+	}
+
+	_, err := svc.CreateBook(req, nil)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestBookService_UpdateBook_SchedulesDraft(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	status := "scheduled"
+	publishAt := time.Now().Add(time.Hour).Unix()
+	existing := &model.Book{ID: bookID, Status: "draft"}
+
+	mockRepo.On("FindByID", bookID).Return(existing, nil)
+	mockRepo.On("Update", bookID, mock.MatchedBy(func(req *dto.BookUpdateRequest) bool {
+		return *req.Status == "scheduled" && *req.PublishAt == publishAt
+	}), "admin").Return(&model.Book{ID: bookID, Status: status, PublishAt: publishAt}, nil)
+
+	book, err := svc.UpdateBook(bookID, &dto.BookUpdateRequest{Status: &status, PublishAt: &publishAt}, nil, "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "scheduled", book.Status)
+}
+
+func TestBookService_UpdateBook_RejectsInvalidTransition(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	status := "draft"
+	mockRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+
+	_, err := svc.UpdateBook(bookID, &dto.BookUpdateRequest{Status: &status}, nil, "admin")
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookService_PublishScheduled(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	aggregator := new(MockDashboardAggregator)
+	svc := service.NewBookService(mockRepo, new(MockBlobStore), aggregator)
+
+	now := time.Unix(1700000000, 0)
+	mockRepo.On("PublishDue", int64(1700000000)).Return([]model.Book{{ID: uuid.New(), Status: "published", Category: "Fiction"}}, nil)
+
+	count, err := svc.PublishScheduled(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	// Books count on the dashboard once published
+	require.Len(t, aggregator.ChangedBooks, 1)
+	assert.Equal(t, "Fiction", aggregator.ChangedBooks[0].Category)
 }
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"crypto/sha256"
+	"honya/backend/errors"
+	"honya/backend/service"
+	"math/bits"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockChallengeRepo struct {
+	mock.Mock
+}
+
+func (m *MockChallengeRepo) Redeem(nonce string, expiresAt int64) (bool, error) {
+	args := m.Called(nonce, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockChallengeRepo) CountRedeemedSince(since int64) (int64, error) {
+	args := m.Called(since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockChallengeRepo) DeleteExpired(before int64) error {
+	args := m.Called(before)
+	return args.Error(0)
+}
+
+func newChallengeRepo(recent int64) *MockChallengeRepo {
+	repo := new(MockChallengeRepo)
+	repo.On("CountRedeemedSince", mock.AnythingOfType("int64")).Return(recent, nil)
+	repo.On("DeleteExpired", mock.AnythingOfType("int64")).Return(nil)
+	return repo
+}
+
+func solveChallenge(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		solution := strconv.Itoa(i)
+		sum := sha256.Sum256([]byte(challenge + ":" + solution))
+
+		zeros := 0
+		for _, b := range sum {
+			zeros += bits.LeadingZeros8(b)
+			if b != 0 {
+				break
+			}
+		}
+		if zeros >= difficulty {
+			return solution
+		}
+	}
+}
+
+func TestChallengeService_VerifySolution(t *testing.T) {
+	repo := newChallengeRepo(0)
+	svc := service.NewChallengeService(repo, true, "secret", 4, 8)
+
+	challenge, err := svc.IssueChallenge()
+	require.NoError(t, err)
+	assert.Equal(t, 4, challenge.Difficulty)
+
+	nonce := strings.SplitN(challenge.Challenge, ".", 2)[0]
+	repo.On("Redeem", nonce, challenge.ExpiresAt).Return(true, nil).Once()
+
+	solution := solveChallenge(challenge.Challenge, challenge.Difficulty)
+	assert.NoError(t, svc.VerifySolution(challenge.Challenge, solution))
+
+	// Replaying the same challenge is rejected, even on another instance
+	repo.On("Redeem", nonce, challenge.ExpiresAt).Return(false, nil).Once()
+	other := service.NewChallengeService(repo, true, "secret", 4, 8)
+	err = other.VerifySolution(challenge.Challenge, solution)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+	assert.Equal(t, "Proof-of-work challenge has already been used", err.(*errors.AppError).Message)
+}
+
+func TestChallengeService_CheckSolution_DoesNotSpendTheChallenge(t *testing.T) {
+	repo := newChallengeRepo(0)
+	svc := service.NewChallengeService(repo, true, "secret", 4, 8)
+
+	challenge, err := svc.IssueChallenge()
+	require.NoError(t, err)
+	solution := solveChallenge(challenge.Challenge, challenge.Difficulty)
+
+	assert.NoError(t, svc.CheckSolution(challenge.Challenge, solution))
+	assert.NoError(t, svc.CheckSolution(challenge.Challenge, solution))
+	repo.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything)
+
+	nonce := strings.SplitN(challenge.Challenge, ".", 2)[0]
+	repo.On("Redeem", nonce, challenge.ExpiresAt).Return(true, nil).Once()
+	assert.NoError(t, svc.SpendChallenge(challenge.Challenge))
+	repo.AssertExpectations(t)
+}
+
+func TestChallengeService_VerifySolution_RejectsTamperedDifficulty(t *testing.T) {
+	svc := service.NewChallengeService(newChallengeRepo(0), true, "secret", 4, 8)
+
+	challenge, err := svc.IssueChallenge()
+	require.NoError(t, err)
+
+	tampered := challenge.Challenge[:33] + "0" + challenge.Challenge[34:]
+	err = svc.VerifySolution(tampered, solveChallenge(tampered, 0))
+	assert.Equal(t, "Invalid proof-of-work challenge", err.(*errors.AppError).Message)
+}
+
+func TestChallengeService_IssueChallenge_ScalesWithSubmissionRate(t *testing.T) {
+	// Twenty redeemed challenges in the last minute across all instances
+	svc := service.NewChallengeService(newChallengeRepo(20), true, "secret", 2, 4)
+
+	challenge, err := svc.IssueChallenge()
+	require.NoError(t, err)
+	assert.Equal(t, 3, challenge.Difficulty)
+
+	svc = service.NewChallengeService(newChallengeRepo(1000), true, "secret", 2, 4)
+	challenge, err = svc.IssueChallenge()
+	require.NoError(t, err)
+	assert.Equal(t, 4, challenge.Difficulty)
+}
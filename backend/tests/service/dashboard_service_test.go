@@ -0,0 +1,247 @@
+package service_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDashboardAggregator records change notifications instead of expecting them, so
+// services that write books or reviews can be tested without stubbing every refresh
+type MockDashboardAggregator struct {
+	mock.Mock
+	ChangedBooks     []*model.Book
+	ChangedReviewers []string
+}
+
+func (m *MockDashboardAggregator) Counts(dimension string, limit int) ([]model.DashboardAggregate, *model.DashboardAggregateState, error) {
+	args := m.Called(dimension, limit)
+	return args.Get(0).([]model.DashboardAggregate), args.Get(1).(*model.DashboardAggregateState), args.Error(2)
+}
+
+func (m *MockDashboardAggregator) BooksChanged(books ...*model.Book) {
+	m.ChangedBooks = append(m.ChangedBooks, books...)
+}
+
+func (m *MockDashboardAggregator) ReviewersChanged(names ...string) {
+	m.ChangedReviewers = append(m.ChangedReviewers, names...)
+}
+
+func (m *MockDashboardAggregator) RefreshDimension(dimension string) error {
+	return nil
+}
+
+func (m *MockDashboardAggregator) RefreshAll() error {
+	return nil
+}
+
+func (m *MockDashboardAggregator) StaleAfter(state *model.DashboardAggregateState) int64 {
+	return state.RefreshedAt + 3600
+}
+
+func (m *MockDashboardAggregator) StartScheduler() {}
+
+func TestDashboardService_GetTimeSeries_ZeroFillsBuckets(t *testing.T) {
+	mockBookRepo := new(MockBookRepo)
+	mockReviewRepo := new(MockReviewRepo)
+	svc := service.NewDashboardService(mockBookRepo, mockReviewRepo, new(MockDashboardAggregator))
+
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, tokyo).Unix()
+	to := time.Date(2026, 1, 4, 0, 0, 0, 0, tokyo).Unix()
+
+	mockReviewRepo.On("CountByInterval", "day", "Asia/Tokyo", from, to).
+		Return(map[string]int64{"2026-01-02": 4}, nil)
+
+	data, err := svc.GetTimeSeries(dto.TimeSeriesQuery{
+		Metric:   "reviews_created",
+		Interval: "day",
+		From:     "2026-01-01",
+		To:       "2026-01-03",
+		Timezone: "Asia/Tokyo",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.TimeSeriesPoint{
+		{Bucket: "2026-01-01", Count: 0},
+		{Bucket: "2026-01-02", Count: 4},
+		{Bucket: "2026-01-03", Count: 0},
+	}, data.Data)
+
+	mockReviewRepo.AssertExpectations(t)
+}
+
+func TestDashboardService_GetTimeSeries_WeeksStartOnMonday(t *testing.T) {
+	mockBookRepo := new(MockBookRepo)
+	mockReviewRepo := new(MockReviewRepo)
+	svc := service.NewDashboardService(mockBookRepo, mockReviewRepo, new(MockDashboardAggregator))
+
+	from := time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC).Unix()
+	to := time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC).Unix()
+
+	mockBookRepo.On("CountByInterval", "week", "UTC", from, to).
+		Return(map[string]int64{"2026-01-05": 2}, nil)
+
+	data, err := svc.GetTimeSeries(dto.TimeSeriesQuery{
+		Metric:   "books_created",
+		Interval: "week",
+		From:     "2026-01-01",
+		To:       "2026-01-07",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-12-29", data.From)
+	assert.Equal(t, "2026-01-11", data.To)
+	assert.Equal(t, []dto.TimeSeriesPoint{
+		{Bucket: "2025-12-29", Count: 0},
+		{Bucket: "2026-01-05", Count: 2},
+	}, data.Data)
+}
+
+func TestDashboardService_GetTimeSeries_InvalidTimezone(t *testing.T) {
+	svc := service.NewDashboardService(new(MockBookRepo), new(MockReviewRepo), new(MockDashboardAggregator))
+
+	_, err := svc.GetTimeSeries(dto.TimeSeriesQuery{Metric: "books_created", Timezone: "Mars/Olympus"})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+}
+
+func TestDashboardService_RunQuery_PivotsSecondDimension(t *testing.T) {
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewDashboardService(mockBookRepo, new(MockReviewRepo), new(MockDashboardAggregator))
+
+	req := &dto.AnalyticsQueryRequest{
+		Dimensions: []string{"publication_year_bucket", "category"},
+		Metrics:    []string{"count"},
+	}
+
+	mockBookRepo.On("Aggregate", req).Return([]map[string]interface{}{
+		{"publication_year_bucket": int64(1980), "category": "fiction", "count": int64(3)},
+		{"publication_year_bucket": int64(1980), "category": "science", "count": int64(1)},
+		{"publication_year_bucket": int64(2010), "category": nil, "count": int64(2)},
+	}, nil)
+
+	data, err := svc.RunQuery(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1980", "2010"}, data.Labels)
+	assert.Equal(t, []dto.AnalyticsSeries{
+		{Name: "fiction", Metric: "count", Data: []float64{3, 0}},
+		{Name: "science", Metric: "count", Data: []float64{1, 0}},
+		{Name: "Unknown", Metric: "count", Data: []float64{0, 2}},
+	}, data.Series)
+	assert.Equal(t, "Unknown", data.Rows[2]["category"])
+}
+
+func TestDashboardService_RunQuery_RejectsUnknownDimension(t *testing.T) {
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewDashboardService(mockBookRepo, new(MockReviewRepo), new(MockDashboardAggregator))
+
+	_, err := svc.RunQuery(&dto.AnalyticsQueryRequest{
+		Dimensions: []string{"isbn; DROP TABLE books"},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	mockBookRepo.AssertNotCalled(t, "Aggregate", mock.Anything)
+}
+
+func TestDashboardService_GetBooksData_DecadeBuckets(t *testing.T) {
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewDashboardService(mockBookRepo, new(MockReviewRepo), new(MockDashboardAggregator))
+
+	mockBookRepo.On("CountByWidth", "publication_year", 10.0).
+		Return(map[int64]int64{198: 3, 200: 1}, nil)
+
+	data, err := svc.GetBooksData(dto.BooksDataQuery{FilterBy: "publication_year"})
+	assert.NoError(t, err)
+	assert.Equal(t, "decade", data.Bucket)
+	assert.Len(t, data.Buckets, 3)
+	assert.Equal(t, "1980s", data.Buckets[0].Label)
+	assert.Equal(t, int64(3), data.Buckets[0].Count)
+	assert.Equal(t, "1990s", data.Buckets[1].Label)
+	assert.Equal(t, int64(0), data.Buckets[1].Count)
+	assert.Equal(t, 2010.0, *data.Buckets[2].Max)
+}
+
+func TestDashboardService_GetBooksData_BucketsContinuousFieldsByDefault(t *testing.T) {
+	mockBookRepo := new(MockBookRepo)
+	mockAggregator := new(MockDashboardAggregator)
+	svc := service.NewDashboardService(mockBookRepo, new(MockReviewRepo), mockAggregator)
+
+	mockBookRepo.On("CountByWidth", "rating", 1.0).Return(map[int64]int64{3: 1, 4: 5}, nil)
+	mockBookRepo.On("CountByWidth", "pages", 100.0).Return(map[int64]int64{2: 2}, nil)
+
+	// 4.5 and 4.6 fall in the same slice instead of one slice per raw value
+	data, err := svc.GetBooksData(dto.BooksDataQuery{FilterBy: "rating"})
+	assert.NoError(t, err)
+	assert.Equal(t, "width", data.Bucket)
+	assert.Equal(t, map[string]int64{"3–4": 1, "4–5": 5}, data.Data)
+
+	// An explicit width bucketing without a width falls back to the field's default
+	data, err = svc.GetBooksData(dto.BooksDataQuery{FilterBy: "pages", Bucket: "width"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"200–300": 2}, data.Data)
+
+	mockAggregator.AssertNotCalled(t, "Counts", mock.Anything, mock.Anything)
+}
+
+func TestDashboardService_GetBooksData_EdgeBuckets(t *testing.T) {
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewDashboardService(mockBookRepo, new(MockReviewRepo), new(MockDashboardAggregator))
+
+	mockBookRepo.On("CountByEdges", "rating", []float64{2, 3.5, 4.5}).
+		Return(map[int64]int64{0: 1, 2: 4, 3: 2}, nil)
+
+	data, err := svc.GetBooksData(dto.BooksDataQuery{FilterBy: "rating", Bucket: "edges", Edges: "2, 3.5, 4.5"})
+	assert.NoError(t, err)
+
+	labels := []string{}
+	for _, bucket := range data.Buckets {
+		labels = append(labels, bucket.Label)
+	}
+	assert.Equal(t, []string{"< 2", "2–3.5", "3.5–4.5", "≥ 4.5"}, labels)
+	assert.Equal(t, int64(4), data.Data["3.5–4.5"])
+	assert.Nil(t, data.Buckets[0].Min)
+}
+
+func TestDashboardService_GetBooksData_RejectsUnorderedEdges(t *testing.T) {
+	svc := service.NewDashboardService(new(MockBookRepo), new(MockReviewRepo), new(MockDashboardAggregator))
+
+	_, err := svc.GetBooksData(dto.BooksDataQuery{FilterBy: "pages", Bucket: "edges", Edges: "300,100"})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+}
+
+func TestDashboardService_GetBooksData_ReadsAggregates(t *testing.T) {
+	mockAggregator := new(MockDashboardAggregator)
+	svc := service.NewDashboardService(new(MockBookRepo), new(MockReviewRepo), mockAggregator)
+
+	mockAggregator.On("Counts", "books_by_category", 0).Return([]model.DashboardAggregate{
+		{Dimension: "books_by_category", Key: "Fiction", Count: 4},
+		{Dimension: "books_by_category", Key: "Unknown", Count: 1},
+	}, &model.DashboardAggregateState{Dimension: "books_by_category", RefreshedAt: 1700000000}, nil)
+
+	data, err := svc.GetBooksData(dto.BooksDataQuery{FilterBy: "category"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"Fiction": 4, "Unknown": 1}, data.Data)
+	assert.Equal(t, int64(1700000000), data.RefreshedAt)
+	assert.Equal(t, int64(1700003600), data.StaleAfter)
+}
+
+func TestDashboardService_GetReviewsData_ReadsAggregates(t *testing.T) {
+	mockAggregator := new(MockDashboardAggregator)
+	svc := service.NewDashboardService(new(MockBookRepo), new(MockReviewRepo), mockAggregator)
+
+	mockAggregator.On("Counts", "reviewers", 10).Return([]model.DashboardAggregate{
+		{Dimension: "reviewers", Key: "Alice", Count: 3},
+	}, &model.DashboardAggregateState{Dimension: "reviewers", RefreshedAt: 1700000000}, nil)
+
+	data, err := svc.GetReviewsData(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.ReviewerStats{{Name: "Alice", Count: 3}}, data.Data)
+	assert.Equal(t, int64(1700003600), data.StaleAfter)
+}
@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"errors"
+	"honya/backend/dto"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDataQualityRepo struct {
+	mock.Mock
+}
+
+func (m *MockDataQualityRepo) CountBooks() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDataQualityRepo) FindBooksFailing(check string, sampleSize int) (int64, []uuid.UUID, error) {
+	args := m.Called(check, sampleSize)
+	return args.Get(0).(int64), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
+func (m *MockDataQualityRepo) FindIsbns() ([]model.Book, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockDataQualityRepo) FindImages() ([]model.Book, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockDataQualityRepo) FindDuplicateGroups() ([][]uuid.UUID, error) {
+	args := m.Called()
+	return args.Get(0).([][]uuid.UUID), args.Error(1)
+}
+
+func (m *MockDataQualityRepo) FindOrphanReviews(sampleSize int) (int64, []uuid.UUID, error) {
+	args := m.Called(sampleSize)
+	return args.Get(0).(int64), args.Get(1).([]uuid.UUID), args.Error(2)
+}
+
+func findCheck(report *dto.DataQualityReport, name string) *dto.DataQualityCheck {
+	for i := range report.Checks {
+		if report.Checks[i].Check == name {
+			return &report.Checks[i]
+		}
+	}
+	return nil
+}
+
+func newDataQualityRepoMock() *MockDataQualityRepo {
+	mockRepo := new(MockDataQualityRepo)
+	mockRepo.On("CountBooks").Return(int64(4), nil)
+	mockRepo.On("FindBooksFailing", mock.Anything, 10).Return(int64(0), []uuid.UUID{}, nil)
+	mockRepo.On("FindOrphanReviews", 10).Return(int64(0), []uuid.UUID{}, nil)
+	return mockRepo
+}
+
+func TestDataQualityService_GetReport_FlagsInvalidIsbnsAndDuplicates(t *testing.T) {
+	mockRepo := newDataQualityRepoMock()
+	mockStore := new(MockBlobStore)
+	svc := service.NewDataQualityService(mockRepo, mockStore)
+
+	valid10, valid13, invalid := uuid.New(), uuid.New(), uuid.New()
+	mockRepo.On("FindIsbns").Return([]model.Book{
+		{ID: valid10, Isbn: "0-306-40615-2"},
+		{ID: valid13, Isbn: "978-0-306-40615-7"},
+		{ID: invalid, Isbn: "978-0-306-40615-8"},
+	}, nil)
+
+	first, second := uuid.New(), uuid.New()
+	mockRepo.On("FindDuplicateGroups").Return([][]uuid.UUID{{first, second}}, nil)
+	mockRepo.On("FindImages").Return([]model.Book{}, nil)
+
+	report, err := svc.GetReport()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), report.TotalBooks)
+
+	isbns := findCheck(report, "invalid_isbn")
+	assert.Equal(t, int64(1), isbns.Count)
+	assert.Equal(t, []uuid.UUID{invalid}, isbns.SampleIDs)
+
+	duplicates := findCheck(report, "duplicate_books")
+	assert.Equal(t, int64(2), duplicates.Count)
+	assert.Equal(t, [][]uuid.UUID{{first, second}}, duplicates.SampleGroups)
+}
+
+func TestDataQualityService_GetReport_ChecksStoredImagesOnly(t *testing.T) {
+	mockRepo := newDataQualityRepoMock()
+	mockStore := new(MockBlobStore)
+	svc := service.NewDataQualityService(mockRepo, mockStore)
+
+	bucketURL := mockBlobBaseURL + "/"
+	present, missing, external := uuid.New(), uuid.New(), uuid.New()
+
+	mockRepo.On("FindIsbns").Return([]model.Book{}, nil)
+	mockRepo.On("FindDuplicateGroups").Return([][]uuid.UUID{}, nil)
+	mockRepo.On("FindImages").Return([]model.Book{
+		{ID: present, Image: bucketURL + "books/present.png"},
+		{ID: missing, Image: bucketURL + "books/missing.png"},
+		{ID: external, Image: "https://covers.example.com/book.png"},
+	}, nil)
+	mockStore.On("List", "books/").Return([]repository.BlobObject{{Key: "books/present.png"}, {Key: "books/unused.png"}}, nil)
+
+	report, err := svc.GetReport()
+	assert.NoError(t, err)
+
+	images := findCheck(report, "broken_images")
+	assert.Equal(t, int64(1), images.Count)
+	assert.Equal(t, []uuid.UUID{missing}, images.SampleIDs)
+	assert.Empty(t, images.Error)
+
+	// One listing of the folder instead of a lookup per cover
+	mockStore.AssertNumberOfCalls(t, "List", 1)
+	mockStore.AssertNotCalled(t, "Exists", mock.Anything)
+}
+
+func TestDataQualityService_GetReport_ReportsStorageErrorsOnTheCheck(t *testing.T) {
+	mockRepo := newDataQualityRepoMock()
+	mockStore := new(MockBlobStore)
+	svc := service.NewDataQualityService(mockRepo, mockStore)
+
+	bucketURL := mockBlobBaseURL + "/"
+
+	mockRepo.On("FindIsbns").Return([]model.Book{}, nil)
+	mockRepo.On("FindDuplicateGroups").Return([][]uuid.UUID{}, nil)
+	mockRepo.On("FindImages").Return([]model.Book{{ID: uuid.New(), Image: bucketURL + "books/a.png"}}, nil)
+	mockStore.On("List", "books/").Return([]repository.BlobObject{}, errors.New("access denied"))
+
+	report, err := svc.GetReport()
+	assert.NoError(t, err)
+
+	images := findCheck(report, "broken_images")
+	assert.Equal(t, int64(0), images.Count)
+	assert.Contains(t, images.Error, "access denied")
+}
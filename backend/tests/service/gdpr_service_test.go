@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDataSubjectRequestRepo struct {
+	mock.Mock
+}
+
+func (m *MockDataSubjectRequestRepo) Create(request *model.DataSubjectRequest) (*model.DataSubjectRequest, error) {
+	args := m.Called(request)
+	return args.Get(0).(*model.DataSubjectRequest), args.Error(1)
+}
+
+func (m *MockDataSubjectRequestRepo) FindAll(params dto.QueryParams) ([]model.DataSubjectRequest, dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.DataSubjectRequest), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func TestGdprService_ExportByEmail(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockRequestRepo := new(MockDataSubjectRequestRepo)
+	mockChangeRepo := new(MockBookChangeRequestRepo)
+	svc := service.NewGdprService(mockReviewRepo, mockRequestRepo, mockChangeRepo, true, "gdpr-secret", new(MockDashboardAggregator))
+
+	reviews := []model.Review{
+		{ID: uuid.New(), Name: "John", Email: "john@example.com", Content: "Great!"},
+	}
+
+	changeRequests := []model.BookChangeRequest{
+		{ID: uuid.New(), Name: "John", Email: "john@example.com", After: map[string]interface{}{"pages": float64(412)}},
+	}
+
+	mockReviewRepo.On("FindByEmail", "john@example.com").Return(reviews, nil)
+	mockChangeRepo.On("FindByEmail", "john@example.com").Return(changeRequests, nil)
+	mockRequestRepo.On("Create", mock.MatchedBy(func(r *model.DataSubjectRequest) bool {
+		return r.Type == utils.DataSubjectRequestExport && r.EmailHMAC == utils.HashEmail([]byte("gdpr-secret"), "john@example.com") && r.ChangeRequestsAffected == 1
+	})).Return(&model.DataSubjectRequest{ID: uuid.New()}, nil)
+
+	archive, err := svc.ExportByEmail("john@example.com", utils.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, archive.ReviewCount)
+	assert.Equal(t, "john@example.com", archive.Reviews[0].Email)
+	assert.Equal(t, "john@example.com", archive.ChangeRequests[0].Email)
+
+	mockReviewRepo.AssertExpectations(t)
+	mockRequestRepo.AssertExpectations(t)
+}
+
+func TestGdprService_EraseByEmail_OverridesPolicy(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockRequestRepo := new(MockDataSubjectRequestRepo)
+	mockChangeRepo := new(MockBookChangeRequestRepo)
+	mockAggregator := new(MockDashboardAggregator)
+	svc := service.NewGdprService(mockReviewRepo, mockRequestRepo, mockChangeRepo, true, "gdpr-secret", mockAggregator)
+
+	keepContent := false
+	req := &dto.DataSubjectErasureRequest{Email: "john@example.com", KeepContent: &keepContent}
+
+	mockReviewRepo.On("FindByEmail", "john@example.com").Return([]model.Review{{Name: "John"}, {Name: "Johnny"}}, nil)
+	mockReviewRepo.On("AnonymizeByEmail", "john@example.com", false).Return(int64(3), nil)
+	mockChangeRepo.On("AnonymizeByEmail", "john@example.com").Return(int64(2), nil)
+	mockRequestRepo.On("Create", mock.AnythingOfType("*model.DataSubjectRequest")).
+		Return(&model.DataSubjectRequest{ID: uuid.New()}, nil)
+
+	result, err := svc.EraseByEmail(req, utils.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.ReviewsAffected)
+	assert.Equal(t, int64(2), result.ChangeRequestsAffected)
+	assert.False(t, result.KeepContent)
+	assert.Equal(t, []string{"John", "Johnny"}, mockAggregator.ChangedReviewers)
+
+	mockReviewRepo.AssertExpectations(t)
+	mockRequestRepo.AssertExpectations(t)
+}
+
+func TestGdprService_EraseByEmail_InvalidEmail(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockRequestRepo := new(MockDataSubjectRequestRepo)
+	mockChangeRepo := new(MockBookChangeRequestRepo)
+	svc := service.NewGdprService(mockReviewRepo, mockRequestRepo, mockChangeRepo, true, "gdpr-secret", new(MockDashboardAggregator))
+
+	result, err := svc.EraseByEmail(&dto.DataSubjectErasureRequest{Email: "not-an-email"}, utils.RoleAdmin)
+	assert.Nil(t, result)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	mockReviewRepo.AssertNotCalled(t, "AnonymizeByEmail", mock.Anything, mock.Anything)
+}
+
+func TestGdprService_GetRequests_FiltersByKeyedEmailHash(t *testing.T) {
+	mockRequestRepo := new(MockDataSubjectRequestRepo)
+	svc := service.NewGdprService(new(MockReviewRepo), mockRequestRepo, new(MockBookChangeRequestRepo), true, "gdpr-secret", new(MockDashboardAggregator))
+
+	hash := utils.HashEmail([]byte("gdpr-secret"), "john@example.com")
+	assert.NotEqual(t, dto.AvatarHash("john@example.com"), hash)
+	assert.NotEqual(t, utils.HashEmail([]byte("other-secret"), "john@example.com"), hash)
+
+	mockRequestRepo.On("FindAll", dto.QueryParams{Query: hash, Limit: 10}).
+		Return([]model.DataSubjectRequest{{EmailHMAC: hash}}, dto.PaginationMeta{TotalCount: 1, Limit: 10}, nil)
+
+	requests, _, err := svc.GetRequests(dto.QueryParams{Query: " John@Example.com", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	mockRequestRepo.AssertExpectations(t)
+}
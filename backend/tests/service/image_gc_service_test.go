@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"errors"
+	"honya/backend/dto"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockImageGCRepo struct {
+	mock.Mock
+}
+
+func (m *MockImageGCRepo) FindReferencedImages(revisionsSince int64) ([]string, error) {
+	args := m.Called(revisionsSince)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockImageGCRepo) FindDeletions() ([]model.BlobDeletion, error) {
+	args := m.Called()
+	return args.Get(0).([]model.BlobDeletion), args.Error(1)
+}
+
+func (m *MockImageGCRepo) SaveDeletion(deletion *model.BlobDeletion) error {
+	args := m.Called(deletion)
+	return args.Error(0)
+}
+
+func (m *MockImageGCRepo) RemoveDeletion(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func TestImageGCService_Collect_DeletesOldOrphans(t *testing.T) {
+	mockRepo := new(MockImageGCRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewImageGCService(mockRepo, store, time.Hour, 30*24*time.Hour)
+
+	for _, key := range []string{"books/current.jpg", "books/revision.jpg", "books/orphan.jpg", "uploads/abandoned"} {
+		_, err := store.Put(key, strings.NewReader("x"), "image/jpeg")
+		require.NoError(t, err)
+	}
+
+	mockRepo.On("FindReferencedImages", mock.Anything).Return([]string{
+		mockBlobBaseURL + "/books/current.jpg",
+		mockBlobBaseURL + "/books/revision.jpg",
+		"https://covers.example.com/external.jpg",
+	}, nil)
+	mockRepo.On("FindDeletions").Return([]model.BlobDeletion{}, nil)
+
+	// Within the grace period nothing is an orphan yet
+	report, err := svc.Collect(time.Now(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Scanned)
+	assert.Equal(t, 2, report.Referenced)
+	assert.Equal(t, 2, report.Recent)
+	assert.Empty(t, report.Orphans)
+
+	later := time.Now().Add(2 * time.Hour)
+	report, err = svc.Collect(later, true)
+	require.NoError(t, err)
+	require.Len(t, report.Orphans, 2)
+	assert.Equal(t, "books/orphan.jpg", report.Orphans[0].Key)
+	assert.Equal(t, mockBlobBaseURL+"/books/orphan.jpg", report.Orphans[0].URL)
+	assert.Equal(t, "uploads/abandoned", report.Orphans[1].Key)
+	assert.Equal(t, 0, report.Deleted)
+	exists, _ := store.Exists("books/orphan.jpg")
+	assert.True(t, exists, "a dry run deletes nothing")
+
+	report, err = svc.Collect(later, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Deleted)
+	for key, want := range map[string]bool{"books/current.jpg": true, "books/revision.jpg": true, "books/orphan.jpg": false, "uploads/abandoned": false} {
+		exists, _ := store.Exists(key)
+		assert.Equal(t, want, exists, key)
+	}
+}
+
+func TestImageGCService_Collect_OnlyKeepsCoversOfRetainedRevisions(t *testing.T) {
+	mockRepo := new(MockImageGCRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewImageGCService(mockRepo, store, time.Hour, 30*24*time.Hour)
+
+	now := time.Unix(1700000000, 0)
+	// Revisions older than the retention no longer hold on to the covers they replaced
+	mockRepo.On("FindReferencedImages", now.Add(-30*24*time.Hour).Unix()).Return([]string{}, nil)
+	mockRepo.On("FindDeletions").Return([]model.BlobDeletion{}, nil)
+
+	_, err := svc.Collect(now, true)
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImageGCService_Collect_QueuesFailedDeletes(t *testing.T) {
+	mockRepo := new(MockImageGCRepo)
+	mockStore := new(MockBlobStore)
+	svc := service.NewImageGCService(mockRepo, mockStore, time.Hour, 30*24*time.Hour)
+
+	now := time.Unix(1700000000, 0)
+	old := now.Add(-2 * time.Hour)
+
+	mockRepo.On("FindReferencedImages", mock.Anything).Return([]string{}, nil)
+	mockStore.On("List", "books/").Return([]repository.BlobObject{{Key: "books/orphan.jpg", ModifiedAt: old}, {Key: "books/retry.jpg", ModifiedAt: old}}, nil)
+	mockStore.On("List", "uploads/").Return([]repository.BlobObject{}, nil)
+	mockRepo.On("FindDeletions").Return([]model.BlobDeletion{
+		{Key: "books/retry.jpg", Attempts: 2, NextAttemptAt: now.Unix() - 1},
+		{Key: "books/later.jpg", Attempts: 1, NextAttemptAt: now.Unix() + 60},
+	}, nil)
+
+	mockStore.On("Delete", "books/orphan.jpg").Return(errors.New("timeout"))
+	mockStore.On("Delete", "books/retry.jpg").Return(nil)
+	mockRepo.On("SaveDeletion", mock.AnythingOfType("*model.BlobDeletion")).Return(nil)
+	mockRepo.On("RemoveDeletion", "books/retry.jpg").Return(nil)
+
+	report, err := svc.Collect(now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Deleted)
+	assert.Equal(t, 1, report.Retried)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, report.PendingRetries)
+
+	mockRepo.AssertCalled(t, "SaveDeletion", &model.BlobDeletion{
+		Key:           "books/orphan.jpg",
+		Attempts:      1,
+		LastError:     "timeout",
+		NextAttemptAt: now.Add(5 * time.Minute).Unix(),
+	})
+	mockStore.AssertNotCalled(t, "Delete", "books/later.jpg")
+}
+
+func TestImageGCService_Collect_DropsQueuedBlobsThatAreReferencedAgain(t *testing.T) {
+	mockRepo := new(MockImageGCRepo)
+	mockStore := new(MockBlobStore)
+	svc := service.NewImageGCService(mockRepo, mockStore, time.Hour, 30*24*time.Hour)
+
+	now := time.Unix(1700000000, 0)
+
+	mockRepo.On("FindReferencedImages", mock.Anything).Return([]string{mockBlobBaseURL + "/books/restored.jpg"}, nil)
+	mockStore.On("List", "books/").Return([]repository.BlobObject{{Key: "books/restored.jpg", ModifiedAt: now.Add(-2 * time.Hour)}}, nil)
+	mockStore.On("List", "uploads/").Return([]repository.BlobObject{}, nil)
+	mockRepo.On("FindDeletions").Return([]model.BlobDeletion{{Key: "books/restored.jpg", Attempts: 3, NextAttemptAt: now.Unix()}}, nil)
+	mockRepo.On("RemoveDeletion", "books/restored.jpg").Return(nil)
+
+	report, err := svc.Collect(now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Referenced)
+	assert.Equal(t, 0, report.PendingRetries)
+	mockStore.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestImageGCService_Collect_KeepsCoversTouchedByAConcurrentUpload(t *testing.T) {
+	dir := t.TempDir()
+	store, err := repository.NewLocalBlobStore(dir, mockBlobBaseURL, "secret")
+	require.NoError(t, err)
+	bookRepo := new(MockBookRepo)
+	uploads := service.NewUploadService(store, service.NewBookService(bookRepo, store, new(MockDashboardAggregator)))
+
+	content := encodeTestPNG(t, 400, 600)
+	setCover := func(book *model.Book) []string {
+		upload, err := uploads.CreateUpload(&dto.UploadCreateRequest{ContentType: "image/png", Size: int64(len(content))})
+		require.NoError(t, err)
+		key, expires, signature := presignedUpload(t, store, upload.URL)
+		require.NoError(t, uploads.ReceiveUpload(key, "image/png", expires, signature, content))
+
+		var update *dto.BookUpdateRequest
+		bookRepo.On("FindByID", book.ID).Return(book, nil)
+		bookRepo.On("Update", book.ID, mock.AnythingOfType("*dto.BookUpdateRequest"), "admin").
+			Run(func(args mock.Arguments) { update = args.Get(1).(*dto.BookUpdateRequest) }).
+			Return(book, nil)
+		_, err = uploads.FinalizeUpload(upload.ID, &dto.UploadFinalizeRequest{BookID: book.ID}, "admin")
+		require.NoError(t, err)
+		return update.Images.URLs()
+	}
+
+	// The covers of a book trashed long ago
+	covers := setCover(&model.Book{ID: uuid.New(), Title: "Book A"})
+	require.NotEmpty(t, covers)
+	old := time.Now().Add(-2 * time.Hour)
+	for _, url := range covers {
+		key, ok := store.Key(url)
+		require.True(t, ok)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, filepath.FromSlash(key)), old, old))
+	}
+
+	// Purging the book in dry-run mode leaves its covers to the collector
+	trashRepo := new(MockTrashRepo)
+	trashed := model.Book{ID: uuid.New(), Image: covers[0]}
+	trashRepo.On("FindExpiredBooks", mock.Anything).Return([]model.Book{trashed}, nil)
+	trashRepo.On("FindBookImages", trashed.ID).Return([]string{}, nil)
+	trashRepo.On("PurgeBook", trashed.ID).Return(nil)
+	trashRepo.On("PurgeReviews", mock.Anything).Return(int64(0), nil)
+	purgeRepo := new(MockImageGCRepo)
+	purgeRepo.On("FindReferencedImages", mock.Anything).Return([]string{}, nil)
+	purgeRepo.On("FindDeletions").Return([]model.BlobDeletion{}, nil)
+	purgeImages := service.NewImageGCService(purgeRepo, store, time.Hour, 30*24*time.Hour)
+	_, _, err = service.NewTrashService(trashRepo, time.Hour, purgeImages, true).Purge(time.Now())
+	require.NoError(t, err)
+
+	// The same cover is uploaded for another book after the collector read the references but
+	// before it listed the blobs, so the new row is not seen yet
+	gcRepo := new(MockImageGCRepo)
+	gcRepo.On("FindReferencedImages", mock.Anything).
+		Run(func(mock.Arguments) { setCover(&model.Book{ID: uuid.New(), Title: "Book B"}) }).
+		Return([]string{}, nil)
+	gcRepo.On("FindDeletions").Return([]model.BlobDeletion{}, nil)
+
+	report, err := service.NewImageGCService(gcRepo, store, time.Hour, 30*24*time.Hour).Collect(time.Now(), false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Orphans)
+	for _, url := range covers {
+		key, _ := store.Key(url)
+		exists, _ := store.Exists(key)
+		assert.True(t, exists, key)
+	}
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	goerrors "errors"
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReviewReportRepo struct {
+	mock.Mock
+}
+
+func (m *MockReviewReportRepo) Create(report *model.ReviewReport) (*model.ReviewReport, error) {
+	args := m.Called(report)
+	return args.Get(0).(*model.ReviewReport), args.Error(1)
+}
+
+func (m *MockReviewReportRepo) ExistsForReporter(reviewID uuid.UUID, reporterHash string) (bool, error) {
+	args := m.Called(reviewID, reporterHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReviewReportRepo) CountByReviewID(reviewID uuid.UUID) (int64, error) {
+	args := m.Called(reviewID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReviewReportRepo) CountReasonsByReviewIDs(reviewIDs []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	args := m.Called(reviewIDs)
+	return args.Get(0).(map[uuid.UUID]map[string]int64), args.Error(1)
+}
+
+func (m *MockReviewReportRepo) ResolveByReviewID(reviewID uuid.UUID, resolvedAt int64) error {
+	args := m.Called(reviewID, resolvedAt)
+	return args.Error(0)
+}
+
+func TestModerationService_ReportReview_HidesAtThreshold(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockReportRepo := new(MockReviewReportRepo)
+	svc := service.NewModerationService(mockReviewRepo, mockReportRepo, 3, "report-secret", new(MockDashboardAggregator))
+
+	id := uuid.New()
+	review := &model.Review{ID: id, Name: "John", Content: "Buy cheap watches", Status: utils.ReviewStatusPublished, ReportCount: 2}
+	hidden := &model.Review{ID: id, Name: "John", Content: "Buy cheap watches", Status: utils.ReviewStatusHidden, ReportCount: 3}
+
+	mockReviewRepo.On("FindByID", id).Return(review, nil)
+	mockReportRepo.On("ExistsForReporter", id, mock.Anything).Return(false, nil)
+	mockReportRepo.On("Create", mock.AnythingOfType("*model.ReviewReport")).Return(&model.ReviewReport{ID: uuid.New()}, nil)
+	mockReportRepo.On("CountByReviewID", id).Return(int64(3), nil)
+	mockReviewRepo.On("Update", id, map[string]interface{}{
+		"report_count": int64(3),
+		"status":       utils.ReviewStatusHidden,
+	}).Return(hidden, nil)
+
+	result, err := svc.ReportReview(id, &dto.ReviewReportRequest{Reason: utils.ReportReasonSpam}, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, result.Hidden)
+	assert.Equal(t, int64(3), result.ReportCount)
+
+	mockReviewRepo.AssertExpectations(t)
+	mockReportRepo.AssertExpectations(t)
+}
+
+func TestModerationService_ReportReview_RejectsDuplicateReporter(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockReportRepo := new(MockReviewReportRepo)
+	svc := service.NewModerationService(mockReviewRepo, mockReportRepo, 3, "report-secret", new(MockDashboardAggregator))
+
+	id := uuid.New()
+	mockReviewRepo.On("FindByID", id).Return(&model.Review{ID: id}, nil)
+	reporterHash := utils.KeyedHash([]byte("report-secret"), "ip:10.0.0.1")
+	assert.NotEqual(t, utils.HashString("ip:10.0.0.1"), reporterHash, "a plain digest of an IP can be reversed")
+	mockReportRepo.On("ExistsForReporter", id, reporterHash).Return(true, nil)
+
+	_, err := svc.ReportReview(id, &dto.ReviewReportRequest{Reason: utils.ReportReasonOffensive}, "10.0.0.1")
+	assert.Error(t, err)
+	assert.Equal(t, 409, err.(*errors.AppError).Code)
+
+	mockReportRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestModerationService_ApproveReview_KeepsReports(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	mockReportRepo := new(MockReviewReportRepo)
+	svc := service.NewModerationService(mockReviewRepo, mockReportRepo, 3, "report-secret", new(MockDashboardAggregator))
+
+	id := uuid.New()
+	mockReviewRepo.On("FindByID", id).Return(&model.Review{ID: id, Status: utils.ReviewStatusHidden, ReportCount: 3}, nil)
+	mockReportRepo.On("ResolveByReviewID", id, mock.AnythingOfType("int64")).Return(nil)
+	mockReviewRepo.On("Update", id, map[string]interface{}{
+		"status":       utils.ReviewStatusPublished,
+		"report_count": 0,
+	}).Return(&model.Review{ID: id, Name: "John", Status: utils.ReviewStatusPublished}, nil)
+
+	review, err := svc.ApproveReview(id)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.ReviewStatusPublished, review.Status)
+
+	mockReportRepo.AssertExpectations(t)
+}
+
+func TestModerationService_RemoveReview_WrapsRepositoryErrors(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepo)
+	svc := service.NewModerationService(mockReviewRepo, new(MockReviewReportRepo), 3, "report-secret", new(MockDashboardAggregator))
+
+	id := uuid.New()
+	mockReviewRepo.On("FindByID", id).Return(&model.Review{ID: id}, nil)
+	mockReviewRepo.On("Delete", id).Return(goerrors.New("connection reset"))
+
+	err := svc.RemoveReview(id)
+	assert.Equal(t, 500, err.(*errors.AppError).Code)
+}
@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"errors"
+	"honya/backend/dto"
+	appErrors "honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduledReportRepo struct {
+	mock.Mock
+}
+
+func (m *MockScheduledReportRepo) FindAll(params dto.QueryParams) ([]model.ScheduledReport, dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.ScheduledReport), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockScheduledReportRepo) FindByID(id uuid.UUID) (*model.ScheduledReport, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.ScheduledReport), args.Error(1)
+}
+
+func (m *MockScheduledReportRepo) Create(report *model.ScheduledReport) (*model.ScheduledReport, error) {
+	args := m.Called(report)
+	return args.Get(0).(*model.ScheduledReport), args.Error(1)
+}
+
+func (m *MockScheduledReportRepo) Update(id uuid.UUID, updates map[string]interface{}) (*model.ScheduledReport, error) {
+	args := m.Called(id, updates)
+	return args.Get(0).(*model.ScheduledReport), args.Error(1)
+}
+
+func (m *MockScheduledReportRepo) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockScheduledReportRepo) FindDue(now int64) ([]model.ScheduledReport, error) {
+	args := m.Called(now)
+	return args.Get(0).([]model.ScheduledReport), args.Error(1)
+}
+
+func (m *MockScheduledReportRepo) ClaimRun(id uuid.UUID, expectedNextRunAt, nextRunAt int64) (bool, error) {
+	args := m.Called(id, expectedNextRunAt, nextRunAt)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockReportRunRepo struct {
+	mock.Mock
+}
+
+func (m *MockReportRunRepo) Create(run *model.ReportRun) (*model.ReportRun, error) {
+	args := m.Called(run)
+	return args.Get(0).(*model.ReportRun), args.Error(1)
+}
+
+func (m *MockReportRunRepo) FindByReportID(reportID uuid.UUID, params dto.QueryParams) ([]model.ReportRun, dto.PaginationMeta, error) {
+	args := m.Called(reportID, params)
+	return args.Get(0).([]model.ReportRun), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+type MockMailerRepo struct {
+	mock.Mock
+}
+
+func (m *MockMailerRepo) Send(message *dto.EmailMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func newTestReport() *model.ScheduledReport {
+	return &model.ScheduledReport{
+		ID:         uuid.New(),
+		Name:       "Weekly summary",
+		Cron:       "0 9 * * 1",
+		Timezone:   "UTC",
+		Recipients: []string{"team@example.com"},
+		Charts:     []string{"books_by_category", "top_reviewers"},
+		Format:     "csv",
+		Enabled:    true,
+	}
+}
+
+func TestReportService_SendNow_EmailsChartsAsCSV(t *testing.T) {
+	mockReportRepo := new(MockScheduledReportRepo)
+	mockRunRepo := new(MockReportRunRepo)
+	mockAggregator := new(MockDashboardAggregator)
+	mockMailer := new(MockMailerRepo)
+	dashboard := service.NewDashboardService(new(MockBookRepo), new(MockReviewRepo), mockAggregator)
+	svc := service.NewReportService(mockReportRepo, mockRunRepo, dashboard, mockMailer)
+
+	report := newTestReport()
+	mockReportRepo.On("FindByID", report.ID).Return(report, nil)
+	mockAggregator.On("Counts", "books_by_category", 0).Return([]model.DashboardAggregate{{Key: "fiction", Count: 3}}, &model.DashboardAggregateState{}, nil)
+	mockAggregator.On("Counts", "reviewers", 10).Return([]model.DashboardAggregate{{Key: "Alice", Count: 2}}, &model.DashboardAggregateState{}, nil)
+	mockMailer.On("Send", mock.MatchedBy(func(message *dto.EmailMessage) bool {
+		return len(message.Attachments) == 2 &&
+			strings.HasPrefix(message.Attachments[0].Filename, "books_by_category-") &&
+			strings.Contains(string(message.Attachments[0].Data), "fiction,3") &&
+			strings.Contains(message.HTML, "Alice")
+	})).Return(nil)
+	mockRunRepo.On("Create", mock.MatchedBy(func(run *model.ReportRun) bool {
+		return run.Status == "sent" && run.Trigger == "manual" && run.Recipients == 1
+	})).Return(&model.ReportRun{ID: uuid.New(), Status: "sent"}, nil)
+	mockReportRepo.On("Update", report.ID, mock.Anything).Return(report, nil)
+
+	run, err := svc.SendNow(report.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "sent", run.Status)
+
+	mockMailer.AssertExpectations(t)
+	mockRunRepo.AssertExpectations(t)
+}
+
+func TestReportService_SendNow_RecordsFailedDelivery(t *testing.T) {
+	mockReportRepo := new(MockScheduledReportRepo)
+	mockRunRepo := new(MockReportRunRepo)
+	mockAggregator := new(MockDashboardAggregator)
+	mockMailer := new(MockMailerRepo)
+	dashboard := service.NewDashboardService(new(MockBookRepo), new(MockReviewRepo), mockAggregator)
+	svc := service.NewReportService(mockReportRepo, mockRunRepo, dashboard, mockMailer)
+
+	report := newTestReport()
+	report.Charts = []string{"books_by_category"}
+	mockReportRepo.On("FindByID", report.ID).Return(report, nil)
+	mockAggregator.On("Counts", "books_by_category", 0).Return([]model.DashboardAggregate{}, &model.DashboardAggregateState{}, nil)
+	mockMailer.On("Send", mock.Anything).Return(errors.New("connection refused"))
+	mockRunRepo.On("Create", mock.MatchedBy(func(run *model.ReportRun) bool {
+		return run.Status == "failed" && run.Error == "connection refused"
+	})).Return(&model.ReportRun{Status: "failed", Error: "connection refused"}, nil)
+	mockReportRepo.On("Update", report.ID, mock.Anything).Return(report, nil)
+
+	run, err := svc.SendNow(report.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "failed", run.Status)
+
+	mockRunRepo.AssertExpectations(t)
+}
+
+func TestReportService_CreateReport_RejectsInvalidCron(t *testing.T) {
+	mockReportRepo := new(MockScheduledReportRepo)
+	svc := service.NewReportService(mockReportRepo, new(MockReportRunRepo), nil, new(MockMailerRepo))
+
+	_, err := svc.CreateReport(&dto.ScheduledReportCreateRequest{
+		Name:       "Daily",
+		Cron:       "every day at nine",
+		Recipients: []string{"team@example.com"},
+		Charts:     []string{"books_by_category"},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*appErrors.AppError).Code)
+
+	mockReportRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReportService_CreateReport_NormalizesRecipients(t *testing.T) {
+	mockReportRepo := new(MockScheduledReportRepo)
+	svc := service.NewReportService(mockReportRepo, new(MockReportRunRepo), nil, new(MockMailerRepo))
+
+	mockReportRepo.On("Create", mock.MatchedBy(func(report *model.ScheduledReport) bool {
+		return assert.ObjectsAreEqual([]string{`"Ops Team" <ops@example.com>`, "<team@example.com>"}, report.Recipients)
+	})).Return(&model.ScheduledReport{ID: uuid.New()}, nil)
+
+	_, err := svc.CreateReport(&dto.ScheduledReportCreateRequest{
+		Name:       "Daily",
+		Cron:       "0 9 * * *",
+		Recipients: []string{"Ops Team <ops@example.com>", "team@example.com", "OPS@example.com"},
+		Charts:     []string{"books_by_category"},
+	})
+	assert.NoError(t, err)
+
+	mockReportRepo.AssertExpectations(t)
+}
+
+func TestReportService_SendNow_EscapesFormulasInCSV(t *testing.T) {
+	mockReportRepo := new(MockScheduledReportRepo)
+	mockRunRepo := new(MockReportRunRepo)
+	mockAggregator := new(MockDashboardAggregator)
+	mockMailer := new(MockMailerRepo)
+	dashboard := service.NewDashboardService(new(MockBookRepo), new(MockReviewRepo), mockAggregator)
+	svc := service.NewReportService(mockReportRepo, mockRunRepo, dashboard, mockMailer)
+
+	report := newTestReport()
+	report.Charts = []string{"books_by_author"}
+	mockReportRepo.On("FindByID", report.ID).Return(report, nil)
+	mockAggregator.On("Counts", "books_by_author", 0).Return([]model.DashboardAggregate{
+		{Key: "=HYPERLINK(\"http://evil\")", Count: 2},
+		{Key: "\t=1+1", Count: 1},
+	}, &model.DashboardAggregateState{}, nil)
+
+	var csv string
+	mockMailer.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		csv = string(args.Get(0).(*dto.EmailMessage).Attachments[0].Data)
+	}).Return(nil)
+	mockRunRepo.On("Create", mock.Anything).Return(&model.ReportRun{Status: "sent"}, nil)
+	mockReportRepo.On("Update", report.ID, mock.Anything).Return(report, nil)
+
+	_, err := svc.SendNow(report.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, csv, `"'=HYPERLINK(""http://evil"")",2`)
+	assert.Contains(t, csv, "'\t=1+1,1")
+}
+
+func TestReportService_RunDueReports_SkipsReportsClaimedElsewhere(t *testing.T) {
+	mockReportRepo := new(MockScheduledReportRepo)
+	mockMailer := new(MockMailerRepo)
+	svc := service.NewReportService(mockReportRepo, new(MockReportRunRepo), nil, mockMailer)
+
+	now := time.Date(2026, 1, 5, 9, 0, 30, 0, time.UTC)
+	due := now.Add(-30 * time.Second).Unix()
+	report := newTestReport()
+	report.NextRunAt = &due
+
+	mockReportRepo.On("FindDue", now.Unix()).Return([]model.ScheduledReport{*report}, nil)
+	mockReportRepo.On("ClaimRun", report.ID, due, time.Date(2026, 1, 12, 9, 0, 0, 0, time.UTC).Unix()).Return(false, nil)
+
+	svc.RunDueReports(now)
+
+	mockReportRepo.AssertExpectations(t)
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything)
+}
@@ -4,11 +4,15 @@ import (
 	"honya/backend/dto"
 	"honya/backend/model"
 	"honya/backend/service"
+	"honya/backend/utils"
+	"strings"
 	"testing"
+	"time"
 
 	"honya/backend/errors"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -52,9 +56,55 @@ func (m *MockReviewRepo) GetTopReviewers(limit int) ([]dto.ReviewerStats, error)
 	return args.Get(0).([]dto.ReviewerStats), args.Error(1)
 }
 
+func (m *MockReviewRepo) FindByEmail(email string) ([]model.Review, error) {
+	args := m.Called(email)
+	return args.Get(0).([]model.Review), args.Error(1)
+}
+
+func (m *MockReviewRepo) AnonymizeByEmail(email string, keepContent bool) (int64, error) {
+	args := m.Called(email, keepContent)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReviewRepo) ExistsByBookAndEmail(bookID uuid.UUID, normalizedEmail string) (bool, error) {
+	args := m.Called(bookID, normalizedEmail)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReviewRepo) CountByEmailSince(normalizedEmail string, since int64) (int64, error) {
+	args := m.Called(normalizedEmail, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReviewRepo) CountActiveBooksSince(since int64, minCount int64) (map[uuid.UUID]int64, error) {
+	args := m.Called(since, minCount)
+	return args.Get(0).(map[uuid.UUID]int64), args.Error(1)
+}
+
+func (m *MockReviewRepo) CountHourlyByBook(bookIDs []uuid.UUID, from, to int64) ([]dto.BookHourlyCount, error) {
+	args := m.Called(bookIDs, from, to)
+	return args.Get(0).([]dto.BookHourlyCount), args.Error(1)
+}
+
+func (m *MockReviewRepo) CountBySourceSince(source string, bookIDs []uuid.UUID, since int64) ([]dto.ReviewSourceCount, error) {
+	args := m.Called(source, bookIDs, since)
+	return args.Get(0).([]dto.ReviewSourceCount), args.Error(1)
+}
+
+func (m *MockReviewRepo) FindModerationQueue(params dto.QueryParams) ([]model.Review, dto.PaginationMeta, error) {
+	args := m.Called(params)
+	return args.Get(0).([]model.Review), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockReviewRepo) CountByInterval(interval, timezone string, from, to int64) (map[string]int64, error) {
+	args := m.Called(interval, timezone, from, to)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
 func TestReviewService_CreateReview(t *testing.T) {
 	mockRepo := new(MockReviewRepo)
-	svc := service.NewReviewService(mockRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
 
 	bookID := uuid.New()
 	req := &dto.ReviewCreateRequest{
@@ -72,9 +122,11 @@ func TestReviewService_CreateReview(t *testing.T) {
 		Content: "Great book!",
 	}
 
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(false, nil)
 	mockRepo.On("Create", mock.AnythingOfType("*model.Review")).Return(reviewModel, nil)
 
-	result, err := svc.CreateReview(req)
+	result, err := svc.CreateReview(req, "203.0.113.7")
 	assert.NoError(t, err)
 	assert.Equal(t, req.Name, result.Name)
 	assert.Equal(t, req.Content, result.Content)
@@ -82,9 +134,170 @@ func TestReviewService_CreateReview(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestReviewService_CreateReview_NormalizesEmail(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{IgnoreGmailDots: true}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "John.Doe+books@GoogleMail.com",
+		Content: "Great book!",
+	}
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "johndoe@gmail.com").Return(false, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(r *model.Review) bool {
+		return r.Email == req.Email && r.EmailNormalized == "johndoe@gmail.com"
+	})).Return(&model.Review{ID: uuid.New(), BookID: bookID}, nil)
+
+	_, err := svc.CreateReview(req, "203.0.113.7")
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReviewService_CreateReview_AlreadyReviewed(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "JOHN@example.com",
+		Content: "Great book!",
+	}
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(true, nil)
+
+	result, err := svc.CreateReview(req, "203.0.113.7")
+	assert.Nil(t, result)
+	assert.Equal(t, 409, err.(*errors.AppError).Code)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReviewService_CreateReview_RequiresProofOfWork(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, true, "secret", 4, 8), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	_, err := svc.CreateReview(&dto.ReviewCreateRequest{
+		BookID:  uuid.New(),
+		Name:    "John",
+		Email:   "john@example.com",
+		Content: "Great book!",
+	}, "203.0.113.7")
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	mockBookRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReviewService_CreateReview_KeepsChallengeOfRejectedReview(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	challengeRepo := newChallengeRepo(0)
+	challenges := service.NewChallengeService(challengeRepo, true, "secret", 4, 8)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, challenges, service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	challenge, err := challenges.IssueChallenge()
+	assert.NoError(t, err)
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:       bookID,
+		Name:         "John",
+		Email:        "john@example.com",
+		Content:      "Great book!",
+		PowChallenge: challenge.Challenge,
+		PowSolution:  solveChallenge(challenge.Challenge, challenge.Difficulty),
+	}
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(true, nil).Once()
+
+	// A duplicate review is rejected without spending the challenge
+	_, err = svc.CreateReview(req, "203.0.113.7")
+	assert.Equal(t, 409, err.(*errors.AppError).Code)
+	challengeRepo.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything)
+
+	// The same solution then goes through once the review can be saved
+	nonce := strings.SplitN(challenge.Challenge, ".", 2)[0]
+	challengeRepo.On("Redeem", nonce, challenge.ExpiresAt).Return(true, nil).Once()
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*model.Review")).Return(&model.Review{ID: uuid.New(), BookID: bookID, Name: "John"}, nil)
+
+	_, err = svc.CreateReview(req, "203.0.113.7")
+	assert.NoError(t, err)
+	challengeRepo.AssertExpectations(t)
+}
+
+func TestReviewService_CreateReview_ConcurrentDuplicate(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "john@example.com",
+		Content: "Great book!",
+	}
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(false, nil)
+
+	// Another submission wins the race between the existence check and the insert
+	mockRepo.On("Create", mock.AnythingOfType("*model.Review")).Return((*model.Review)(nil), &pgconn.PgError{
+		Code:           "23505",
+		ConstraintName: "idx_reviews_book_email",
+	}).Once()
+	_, err := svc.CreateReview(req, "203.0.113.7")
+	assert.Equal(t, 409, err.(*errors.AppError).Code)
+
+	// Other constraints are not a duplicate review
+	mockRepo.On("Create", mock.AnythingOfType("*model.Review")).Return((*model.Review)(nil), &pgconn.PgError{
+		Code:           "23505",
+		ConstraintName: "reviews_pkey",
+	}).Once()
+	_, err = svc.CreateReview(req, "203.0.113.7")
+	assert.Equal(t, 500, err.(*errors.AppError).Code)
+}
+
+func TestReviewService_CreateReview_RateLimited(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{MaxPerWindow: 5, Window: time.Hour}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "john@example.com",
+		Content: "Great book!",
+	}
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(false, nil)
+	mockRepo.On("CountByEmailSince", "john@example.com", mock.AnythingOfType("int64")).Return(int64(5), nil)
+
+	result, err := svc.CreateReview(req, "203.0.113.7")
+	assert.Nil(t, result)
+	assert.Equal(t, 429, err.(*errors.AppError).Code)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
 func TestReviewService_GetReviewByID_NotFound(t *testing.T) {
 	mockRepo := new(MockReviewRepo)
-	svc := service.NewReviewService(mockRepo)
+	svc := service.NewReviewService(mockRepo, new(MockBookRepo), service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
 
 	id := uuid.New()
 	mockRepo.On("FindByID", id).Return((*model.Review)(nil), nil)
@@ -100,7 +313,7 @@ func TestReviewService_GetReviewByID_NotFound(t *testing.T) {
 
 func TestReviewService_FindByBookID(t *testing.T) {
 	mockRepo := new(MockReviewRepo)
-	svc := service.NewReviewService(mockRepo)
+	svc := service.NewReviewService(mockRepo, new(MockBookRepo), service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
 
 	bookID := uuid.New()
 	params := dto.QueryParams{Limit: 10, Offset: 0}
@@ -123,7 +336,7 @@ func TestReviewService_FindByBookID(t *testing.T) {
 
 func TestReviewService_DeleteReview_NotFound(t *testing.T) {
 	mockRepo := new(MockReviewRepo)
-	svc := service.NewReviewService(mockRepo)
+	svc := service.NewReviewService(mockRepo, new(MockBookRepo), service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
 
 	id := uuid.New()
 	mockRepo.On("FindByID", id).Return((*model.Review)(nil), nil)
@@ -134,3 +347,132 @@ func TestReviewService_DeleteReview_NotFound(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestReviewService_CreateReview_RendersSanitizedMarkdown(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "john@example.com",
+		Content: "**Loved** it <script>alert(1)</script>\n\n> The ending ||dies|| [link](javascript:alert(1))",
+	}
+
+	expectedHTML := `<p><strong>Loved</strong> it &lt;script&gt;alert(1)&lt;/script&gt;</p>` +
+		`<blockquote><p>The ending <span class="spoiler">dies</span> link</p></blockquote>`
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(false, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(r *model.Review) bool {
+		return r.Content == req.Content && r.ContentHTML == expectedHTML
+	})).Return(&model.Review{ID: uuid.New(), BookID: bookID}, nil)
+
+	_, err := svc.CreateReview(req, "203.0.113.7")
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReviewService_CreateReview_RendersNestedMarkdown(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "john@example.com",
+		Content: "See [`main.go`](https://a.com/x_(y)), *very **good** read*\n**mis *nested** tags*",
+	}
+
+	// Code inside a link label is kept, and markers that cross each other stay literal
+	expectedHTML := `<p>See <a href="https://a.com/x_(y)" rel="nofollow noopener noreferrer" target="_blank"><code>main.go</code></a>, ` +
+		`<em>very <strong>good</strong> read</em><br><strong>mis *nested</strong> tags*</p>`
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published"}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(false, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(r *model.Review) bool {
+		return r.ContentHTML == expectedHTML
+	})).Return(&model.Review{ID: uuid.New(), BookID: bookID}, nil)
+
+	_, err := svc.CreateReview(req, "203.0.113.7")
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRenderMarkdown_LongDelimiterRunsRenderInLinearTime(t *testing.T) {
+	// A run of delimiters that never close used to rescan the rest of the line at every one
+	// of them, taking seconds for a few tens of thousands of characters
+	for _, marker := range []string{"*", "_", "~", "|", "[", "**", "*a", "[a]("} {
+		source := strings.Repeat(marker, 100000/len(marker))
+
+		started := time.Now()
+		rendered := utils.RenderMarkdown(source)
+		elapsed := time.Since(started)
+
+		assert.True(t, strings.HasPrefix(rendered, "<p>"), marker)
+		assert.Less(t, elapsed, 2*time.Second, "rendering a run of %q took %s", marker, elapsed)
+	}
+}
+
+func TestReviewService_UpdateReview_RejectsOverlongContent(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	svc := service.NewReviewService(mockRepo, new(MockBookRepo), service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	content := strings.Repeat("é", utils.ReviewContentMaxLength+1)
+	_, err := svc.UpdateReview(uuid.New(), &dto.ReviewUpdateRequest{Content: &content})
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+	mockRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestReviewService_CreateReview_HeldBookGoesToModeration(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	req := &dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "john@example.com",
+		Content: "Great book!",
+	}
+
+	mockBookRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Status: "published", ReviewsHeldUntil: time.Now().Add(time.Hour).Unix()}, nil)
+	mockRepo.On("ExistsByBookAndEmail", bookID, "john@example.com").Return(false, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(r *model.Review) bool {
+		return r.Status == "pending" && r.IPRange == "203.0.113.0/24"
+	})).Return(&model.Review{ID: uuid.New(), BookID: bookID, Status: "pending"}, nil)
+
+	result, err := svc.CreateReview(req, "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReviewService_CreateReview_BookNotFound(t *testing.T) {
+	mockRepo := new(MockReviewRepo)
+	mockBookRepo := new(MockBookRepo)
+	svc := service.NewReviewService(mockRepo, mockBookRepo, service.NewChallengeService(nil, false, "", 0, 0), service.ReviewLimits{}, new(MockDashboardAggregator))
+
+	bookID := uuid.New()
+	mockBookRepo.On("FindByID", bookID).Return((*model.Book)(nil), nil)
+
+	result, err := svc.CreateReview(&dto.ReviewCreateRequest{
+		BookID:  bookID,
+		Name:    "John",
+		Email:   "john@example.com",
+		Content: "Great book!",
+	}, "203.0.113.7")
+	assert.Nil(t, result)
+	assert.Equal(t, 404, err.(*errors.AppError).Code)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"errors"
+	"honya/backend/dto"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockTrashRepo struct {
+	mock.Mock
+}
+
+func (m *MockTrashRepo) FindBooks(offset, limit int) ([]model.Book, dto.PaginationMeta, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]model.Book), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockTrashRepo) FindReviews(offset, limit int) ([]model.Review, dto.PaginationMeta, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]model.Review), args.Get(1).(dto.PaginationMeta), args.Error(2)
+}
+
+func (m *MockTrashRepo) FindExpiredBooks(before time.Time) ([]model.Book, error) {
+	args := m.Called(before)
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockTrashRepo) FindBookImages(id uuid.UUID) ([]string, error) {
+	args := m.Called(id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTrashRepo) PurgeBook(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTrashRepo) PurgeReviews(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestTrashService_GetTrash_Books(t *testing.T) {
+	mockRepo := new(MockTrashRepo)
+	svc := service.NewTrashService(mockRepo, 24*time.Hour, nil, false)
+
+	deletedAt := time.Unix(1700000000, 0)
+	book := model.Book{ID: uuid.New(), Title: "Dune", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}
+	mockRepo.On("FindBooks", 0, 10).Return([]model.Book{book}, dto.PaginationMeta{TotalCount: 1, Limit: 10}, nil)
+
+	items, meta, err := svc.GetTrash(dto.TrashQueryParams{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), meta.TotalCount)
+	assert.Equal(t, []dto.TrashItem{{
+		Type:      "book",
+		ID:        book.ID,
+		Label:     "Dune",
+		DeletedAt: 1700000000,
+		PurgeAt:   1700086400,
+	}}, items)
+}
+
+func TestTrashService_Purge_RemovesExpiredRows(t *testing.T) {
+	mockRepo := new(MockTrashRepo)
+	gcRepo := new(MockImageGCRepo)
+	images := service.NewImageGCService(gcRepo, repository.NewMemoryBlobStore(mockBlobBaseURL), time.Hour, 30*24*time.Hour)
+	svc := service.NewTrashService(mockRepo, 24*time.Hour, images, false)
+
+	now := time.Unix(1700086400, 0)
+	cutoff := time.Unix(1700000000, 0)
+	books := []model.Book{{ID: uuid.New(), Image: mockBlobBaseURL + "/books/current.png"}, {ID: uuid.New()}}
+
+	gcRepo.On("FindReferencedImages", mock.Anything).Return([]string{}, nil)
+	gcRepo.On("FindDeletions").Return([]model.BlobDeletion{}, nil)
+	mockRepo.On("FindExpiredBooks", cutoff).Return(books, nil)
+	mockRepo.On("FindBookImages", books[0].ID).Return([]string{}, nil)
+	mockRepo.On("FindBookImages", books[1].ID).Return([]string{}, nil)
+	mockRepo.On("PurgeBook", books[0].ID).Return(nil)
+	mockRepo.On("PurgeBook", books[1].ID).Return(nil)
+	mockRepo.On("PurgeReviews", cutoff).Return(int64(4), nil)
+
+	purged, reviews, err := svc.Purge(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.Equal(t, int64(4), reviews)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTrashService_Purge_StopsOnError(t *testing.T) {
+	mockRepo := new(MockTrashRepo)
+	gcRepo := new(MockImageGCRepo)
+	images := service.NewImageGCService(gcRepo, repository.NewMemoryBlobStore(mockBlobBaseURL), time.Hour, 30*24*time.Hour)
+	svc := service.NewTrashService(mockRepo, 24*time.Hour, images, false)
+
+	book := model.Book{ID: uuid.New(), Image: mockBlobBaseURL + "/books/current.png"}
+	mockRepo.On("FindExpiredBooks", mock.Anything).Return([]model.Book{book, {ID: uuid.New()}}, nil)
+	mockRepo.On("FindBookImages", book.ID).Return([]string{}, nil)
+	mockRepo.On("PurgeBook", book.ID).Return(errors.New("connection reset"))
+
+	purged, _, err := svc.Purge(time.Unix(1700086400, 0))
+	assert.Error(t, err)
+	assert.Equal(t, 0, purged)
+	mockRepo.AssertNotCalled(t, "PurgeReviews", mock.Anything)
+	gcRepo.AssertNotCalled(t, "FindReferencedImages", mock.Anything)
+}
+
+func TestTrashService_Purge_DeletesImagesNothingElseRefersTo(t *testing.T) {
+	mockRepo := new(MockTrashRepo)
+	gcRepo := new(MockImageGCRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	images := service.NewImageGCService(gcRepo, store, time.Hour, 30*24*time.Hour)
+	svc := service.NewTrashService(mockRepo, 24*time.Hour, images, false)
+
+	for _, key := range []string{"books/cover.jpg", "books/cover_thumb.webp", "books/shared.jpg", "books/revision.jpg", "books/gallery.jpg"} {
+		_, err := store.Put(key, strings.NewReader("x"), "image/jpeg")
+		require.NoError(t, err)
+	}
+
+	now := time.Now().Add(48 * time.Hour)
+	book := model.Book{
+		ID:     uuid.New(),
+		Image:  mockBlobBaseURL + "/books/cover.jpg",
+		Images: &model.BookImages{Variants: map[string]model.ImageVariant{"thumb": {WebP: mockBlobBaseURL + "/books/cover_thumb.webp"}}},
+	}
+	mockRepo.On("FindExpiredBooks", mock.Anything).Return([]model.Book{book}, nil)
+	mockRepo.On("FindBookImages", book.ID).Return([]string{
+		mockBlobBaseURL + "/books/shared.jpg",
+		mockBlobBaseURL + "/books/revision.jpg",
+		mockBlobBaseURL + "/books/gallery.jpg",
+		"https://covers.example.com/external.jpg",
+	}, nil)
+	mockRepo.On("PurgeBook", book.ID).Return(nil)
+	mockRepo.On("PurgeReviews", mock.Anything).Return(int64(0), nil)
+	// Another book still uses one of the covers
+	gcRepo.On("FindReferencedImages", mock.Anything).Return([]string{mockBlobBaseURL + "/books/shared.jpg"}, nil)
+	gcRepo.On("FindDeletions").Return([]model.BlobDeletion{}, nil)
+
+	purged, _, err := svc.Purge(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	for key, kept := range map[string]bool{
+		"books/cover.jpg":        false,
+		"books/cover_thumb.webp": false,
+		"books/shared.jpg":       true,
+		"books/revision.jpg":     false,
+		"books/gallery.jpg":      false,
+	} {
+		exists, err := store.Exists(key)
+		require.NoError(t, err)
+		assert.Equal(t, kept, exists, key)
+	}
+}
+
+func TestTrashService_Purge_KeepsRecentImages(t *testing.T) {
+	mockRepo := new(MockTrashRepo)
+	gcRepo := new(MockImageGCRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	images := service.NewImageGCService(gcRepo, store, time.Hour, 30*24*time.Hour)
+	svc := service.NewTrashService(mockRepo, 24*time.Hour, images, false)
+
+	// The same bytes were just uploaded for another book that is still being saved
+	_, err := store.Put("books/cover.jpg", strings.NewReader("x"), "image/jpeg")
+	require.NoError(t, err)
+
+	book := model.Book{ID: uuid.New(), Image: mockBlobBaseURL + "/books/cover.jpg"}
+	mockRepo.On("FindExpiredBooks", mock.Anything).Return([]model.Book{book}, nil)
+	mockRepo.On("FindBookImages", book.ID).Return([]string{}, nil)
+	mockRepo.On("PurgeBook", book.ID).Return(nil)
+	mockRepo.On("PurgeReviews", mock.Anything).Return(int64(0), nil)
+	gcRepo.On("FindReferencedImages", mock.Anything).Return([]string{}, nil)
+	gcRepo.On("FindDeletions").Return([]model.BlobDeletion{}, nil)
+
+	_, _, err = svc.Purge(time.Now())
+	require.NoError(t, err)
+
+	exists, err := store.Exists("books/cover.jpg")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestTrashService_GetTrash_InvalidType(t *testing.T) {
+	svc := service.NewTrashService(new(MockTrashRepo), time.Hour, nil, false)
+
+	_, _, err := svc.GetTrash(dto.TrashQueryParams{Type: "author"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"honya/backend/dto"
+	"honya/backend/errors"
+	"honya/backend/model"
+	"honya/backend/repository"
+	"honya/backend/service"
+	"honya/backend/utils"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// presignedUpload splits a presigned URL of the memory store into what the API receives
+func presignedUpload(t *testing.T, store repository.BlobStore, presigned string) (string, int64, string) {
+	parsed, err := url.Parse(presigned)
+	require.NoError(t, err)
+
+	query := parsed.Query()
+	parsed.RawQuery = ""
+	key, ok := store.Key(parsed.String())
+	require.True(t, ok)
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	require.NoError(t, err)
+	return key, expires, query.Get("signature")
+}
+
+func TestUploadService_UploadAndFinalize(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewUploadService(store, service.NewBookService(mockRepo, store, new(MockDashboardAggregator)))
+
+	content := encodeTestPNG(t, 400, 600)
+	upload, err := svc.CreateUpload(&dto.UploadCreateRequest{ContentType: "image/png", Size: int64(len(content))})
+	require.NoError(t, err)
+	assert.Equal(t, "PUT", upload.Method)
+	assert.Equal(t, map[string]string{"Content-Type": "image/png"}, upload.Headers)
+
+	key, expires, signature := presignedUpload(t, store, upload.URL)
+	assert.Equal(t, "uploads/"+upload.ID.String(), key)
+	require.NoError(t, svc.ReceiveUpload(key, "image/png", expires, signature, content))
+
+	bookID := uuid.New()
+	book := &model.Book{ID: bookID, Title: "Book A"}
+	mockRepo.On("FindByID", bookID).Return(book, nil)
+
+	var update *dto.BookUpdateRequest
+	mockRepo.On("Update", bookID, mock.AnythingOfType("*dto.BookUpdateRequest"), "admin").
+		Run(func(args mock.Arguments) { update = args.Get(1).(*dto.BookUpdateRequest) }).
+		Return(book, nil)
+
+	_, err = svc.FinalizeUpload(upload.ID, &dto.UploadFinalizeRequest{BookID: bookID, Reason: "Better scan"}, "admin")
+	require.NoError(t, err)
+
+	require.NotNil(t, update.Images)
+	assert.Equal(t, update.Images.Variants["large"].JPEG, *update.Image)
+	assert.Equal(t, [2]int{400, 600}, [2]int{update.Images.Width, update.Images.Height})
+	assert.Equal(t, "Better scan", update.Reason)
+
+	coverKey, ok := store.Key(*update.Image)
+	require.True(t, ok)
+	assert.True(t, utils.IsContentKey(coverKey), coverKey)
+	exists, _ := store.Exists(coverKey)
+	assert.True(t, exists)
+
+	exists, _ = store.Exists(key)
+	assert.False(t, exists, "the staged upload is removed")
+}
+
+func TestUploadService_CreateUpload_RejectsInvalidFiles(t *testing.T) {
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewUploadService(store, nil)
+
+	for _, req := range []dto.UploadCreateRequest{
+		{ContentType: "image/gif", Size: 100},
+		{ContentType: "image/png", Size: 0},
+		{ContentType: "image/png", Size: 9 << 20},
+	} {
+		_, err := svc.CreateUpload(&req)
+		require.Error(t, err, req)
+		assert.Equal(t, 400, err.(*errors.AppError).Code)
+	}
+}
+
+func TestUploadService_ReceiveUpload_RejectsMismatchedUploads(t *testing.T) {
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewUploadService(store, nil)
+
+	upload, err := svc.CreateUpload(&dto.UploadCreateRequest{ContentType: "image/png", Size: 4})
+	require.NoError(t, err)
+	key, expires, signature := presignedUpload(t, store, upload.URL)
+
+	tests := []struct {
+		name        string
+		key         string
+		contentType string
+		expires     int64
+		signature   string
+		body        string
+	}{
+		{"other size", key, "image/png", expires, signature, "12345"},
+		{"other content type", key, "image/jpeg", expires, signature, "1234"},
+		{"extended expiry", key, "image/png", expires + 3600, signature, "1234"},
+		{"tampered signature", key, "image/png", expires, strings.Repeat("0", len(signature)), "1234"},
+		{"outside uploads", "books/cover.jpg", "image/png", expires, signature, "1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.ReceiveUpload(tt.key, tt.contentType, tt.expires, tt.signature, []byte(tt.body))
+			require.Error(t, err)
+			assert.Equal(t, 403, err.(*errors.AppError).Code)
+		})
+	}
+
+	exists, _ := store.Exists(key)
+	assert.False(t, exists)
+}
+
+func TestUploadService_FinalizeUpload_RemovesInvalidImage(t *testing.T) {
+	mockRepo := new(MockBookRepo)
+	store := repository.NewMemoryBlobStore(mockBlobBaseURL)
+	svc := service.NewUploadService(store, service.NewBookService(mockRepo, store, new(MockDashboardAggregator)))
+
+	content := []byte("definitely not an image")
+	upload, err := svc.CreateUpload(&dto.UploadCreateRequest{ContentType: "image/png", Size: int64(len(content))})
+	require.NoError(t, err)
+	key, expires, signature := presignedUpload(t, store, upload.URL)
+	require.NoError(t, svc.ReceiveUpload(key, "image/png", expires, signature, content))
+
+	bookID := uuid.New()
+	mockRepo.On("FindByID", bookID).Return(&model.Book{ID: bookID, Title: "Book A"}, nil)
+
+	_, err = svc.FinalizeUpload(upload.ID, &dto.UploadFinalizeRequest{BookID: bookID}, "admin")
+	require.Error(t, err)
+	assert.Equal(t, 400, err.(*errors.AppError).Code)
+
+	exists, _ := store.Exists(key)
+	assert.False(t, exists)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = svc.FinalizeUpload(upload.ID, &dto.UploadFinalizeRequest{BookID: bookID}, "admin")
+	require.Error(t, err)
+	assert.Equal(t, 404, err.(*errors.AppError).Code)
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"honya/backend/dto"
+	"strings"
+)
+
+var allowedAnalyticsDimensions = map[string]struct{}{
+	AnalyticsDimensionCategory:              {},
+	AnalyticsDimensionAuthor:                {},
+	AnalyticsDimensionPublicationYearBucket: {},
+	AnalyticsDimensionRatingBucket:          {},
+	AnalyticsDimensionCreatedMonth:          {},
+}
+
+var allowedAnalyticsMetrics = map[string]struct{}{
+	AnalyticsMetricCount:       {},
+	AnalyticsMetricAvgRating:   {},
+	AnalyticsMetricAvgPages:    {},
+	AnalyticsMetricReviewCount: {},
+}
+
+// Filterable fields and whether they hold numbers (true) or text (false)
+var analyticsFilterFields = map[string]bool{
+	"category":         false,
+	"author":           false,
+	"publication_year": true,
+	"rating":           true,
+	"pages":            true,
+}
+
+var analyticsNumericOps = map[string]struct{}{
+	AnalyticsFilterEq:  {},
+	AnalyticsFilterNeq: {},
+	AnalyticsFilterGt:  {},
+	AnalyticsFilterGte: {},
+	AnalyticsFilterLt:  {},
+	AnalyticsFilterLte: {},
+}
+
+var analyticsTextOps = map[string]struct{}{
+	AnalyticsFilterEq:  {},
+	AnalyticsFilterNeq: {},
+	AnalyticsFilterIn:  {},
+}
+
+// ValidateAnalyticsQueryRequest checks every dimension, metric and filter against the whitelist
+// and normalizes filter values so that repositories can bind them as parameters as-is.
+func ValidateAnalyticsQueryRequest(request *dto.AnalyticsQueryRequest) error {
+	if len(request.Dimensions) > MaxAnalyticsDimensions {
+		return fmt.Errorf("at most %d dimensions are allowed", MaxAnalyticsDimensions)
+	}
+	seen := map[string]struct{}{}
+	for _, dimension := range request.Dimensions {
+		if _, valid := allowedAnalyticsDimensions[dimension]; !valid {
+			return fmt.Errorf("invalid dimension: %s. Allowed dimensions are: category, author, publication_year_bucket, rating_bucket, created_month", dimension)
+		}
+		if _, duplicate := seen[dimension]; duplicate {
+			return fmt.Errorf("duplicate dimension: %s", dimension)
+		}
+		seen[dimension] = struct{}{}
+	}
+
+	if len(request.Metrics) == 0 {
+		request.Metrics = []string{AnalyticsMetricCount}
+	}
+	for _, metric := range request.Metrics {
+		if _, valid := allowedAnalyticsMetrics[metric]; !valid {
+			return fmt.Errorf("invalid metric: %s. Allowed metrics are: count, avg_rating, avg_pages, review_count", metric)
+		}
+		if _, duplicate := seen[metric]; duplicate {
+			return fmt.Errorf("duplicate metric: %s", metric)
+		}
+		seen[metric] = struct{}{}
+	}
+
+	if len(request.Filters) > MaxAnalyticsFilters {
+		return fmt.Errorf("at most %d filters are allowed", MaxAnalyticsFilters)
+	}
+	for i := range request.Filters {
+		if err := validateAnalyticsFilter(&request.Filters[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateAnalyticsFilter(filter *dto.AnalyticsFilter) error {
+	numeric, valid := analyticsFilterFields[filter.Field]
+	if !valid {
+		return fmt.Errorf("invalid filter field: %s. Allowed fields are: category, author, publication_year, rating, pages", filter.Field)
+	}
+
+	if numeric {
+		if _, valid := analyticsNumericOps[filter.Op]; !valid {
+			return fmt.Errorf("invalid operator %s for %s. Allowed operators are: eq, neq, gt, gte, lt, lte", filter.Op, filter.Field)
+		}
+		if _, isNumber := filter.Value.(float64); !isNumber {
+			return fmt.Errorf("%s filter value must be a number", filter.Field)
+		}
+		return nil
+	}
+
+	if _, valid := analyticsTextOps[filter.Op]; !valid {
+		return fmt.Errorf("invalid operator %s for %s. Allowed operators are: eq, neq, in", filter.Op, filter.Field)
+	}
+
+	if filter.Op != AnalyticsFilterIn {
+		value, isString := filter.Value.(string)
+		if !isString {
+			return fmt.Errorf("%s filter value must be a string", filter.Field)
+		}
+		filter.Value = strings.TrimSpace(value)
+		return nil
+	}
+
+	items, isList := filter.Value.([]interface{})
+	if !isList || len(items) == 0 {
+		return fmt.Errorf("%s filter value must be a non-empty list for the in operator", filter.Field)
+	}
+	if len(items) > MaxAnalyticsFilterValues {
+		return fmt.Errorf("at most %d values are allowed in an in filter", MaxAnalyticsFilterValues)
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		value, isString := item.(string)
+		if !isString {
+			return errors.New("in filter values must be strings")
+		}
+		values = append(values, strings.TrimSpace(value))
+	}
+	filter.Value = values
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"encoding/json"
+	"honya/backend/dto"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetAudit tells the audit middleware what the handler changed. Summaries must not carry
+// personal data: name the fields that changed, not their values.
+func SetAudit(ctx *fiber.Ctx, entityType, entityID, action, summary string) {
+	ctx.Locals(AuditLocalsKey, &dto.AuditEvent{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Summary:    summary,
+	})
+}
+
+// GetAudit returns the event set by the handler, or nil if it did not set one
+func GetAudit(ctx *fiber.Ctx) *dto.AuditEvent {
+	event, _ := ctx.Locals(AuditLocalsKey).(*dto.AuditEvent)
+	return event
+}
+
+// AuditFields summarizes a partial update request as the sorted list of fields it sets
+func AuditFields(req interface{}, extra ...string) string {
+	var fields map[string]interface{}
+	payload, _ := json.Marshal(req)
+	_ = json.Unmarshal(payload, &fields)
+
+	seen := map[string]bool{}
+	names := []string{}
+	for _, name := range extra {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	// Cover variants always change with the image, so they are not named separately
+	for name, value := range fields {
+		if value != nil && name != "reason" && name != "images" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return "No fields changed"
+	}
+	return "Changed " + strings.Join(names, ", ")
+}
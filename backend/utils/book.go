@@ -1,13 +1,19 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"honya/backend/dto"
+	"net/mail"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/google/uuid"
 )
 
 var allowedCategories = map[string]struct{}{
@@ -23,6 +29,38 @@ var allowedCategories = map[string]struct{}{
 	"classics":    {},
 }
 
+// bookStatusTransitions lists the statuses a book may move to from each status; the empty
+// status stands for a book being created
+var bookStatusTransitions = map[string][]string{
+	"":                  {BookStatusDraft, BookStatusScheduled, BookStatusPublished},
+	BookStatusDraft:     {BookStatusScheduled, BookStatusPublished, BookStatusArchived},
+	BookStatusScheduled: {BookStatusDraft, BookStatusScheduled, BookStatusPublished, BookStatusArchived},
+	BookStatusPublished: {BookStatusArchived},
+	BookStatusArchived:  {BookStatusDraft, BookStatusPublished},
+}
+
+// IsBookStatus reports whether status is one of the publishing statuses
+func IsBookStatus(status string) bool {
+	_, ok := bookStatusTransitions[status]
+	return ok && status != ""
+}
+
+// CanChangeBookStatus reports whether a book may move from one status to another
+func CanChangeBookStatus(from, to string) bool {
+	for _, allowed := range bookStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBookVisible reports whether a book with this status can be fetched by public callers.
+// Archived books stay reachable by ID but are not listed.
+func IsBookVisible(status string) bool {
+	return status == BookStatusPublished || status == BookStatusArchived
+}
+
 func ValidateBookCreateRequest(request *dto.BookCreateRequest) error {
 	if request.Title == "" {
 		return errors.New("title is required")
@@ -49,6 +87,9 @@ func ValidateBookCreateRequest(request *dto.BookCreateRequest) error {
 	if request.Isbn == "" {
 		return errors.New("ISBN is required")
 	}
+	if request.Status != "" && !IsBookStatus(request.Status) {
+		return errors.New("status must be draft, scheduled, published or archived")
+	}
 	return nil
 }
 
@@ -80,10 +121,152 @@ func ValidateBookUpdateRequest(request *dto.BookUpdateRequest) error {
 	if request.Pages != nil && *request.Pages <= 0 {
 		return errors.New("pages must be a positive integer")
 	}
+	if request.Status != nil && !IsBookStatus(*request.Status) {
+		return errors.New("status must be draft, scheduled, published or archived")
+	}
 
 	return nil
 }
 
+// ValidateBookChangeRequest checks a reader's proposed correction. Readers may only propose
+// content fields; the ISBN, cover, status and publish time are left to editors.
+func ValidateBookChangeRequest(request *dto.BookChangeRequestCreateRequest) error {
+	changes := &request.Changes
+	if changes.Isbn != nil || changes.Image != nil || changes.Images != nil || changes.Status != nil || changes.PublishAt != nil {
+		return errors.New("only title, description, category, publication_year, rating, pages and author_name can be changed")
+	}
+	if changes.Title == nil && changes.Description == nil && changes.Category == nil && changes.PublicationYear == nil &&
+		changes.Rating == nil && changes.Pages == nil && changes.AuthorName == nil {
+		return errors.New("at least one change is required")
+	}
+	if err := ValidateBookUpdateRequest(changes); err != nil {
+		return err
+	}
+	if len(request.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	if request.Email != "" {
+		if _, err := mail.ParseAddress(request.Email); err != nil || len(request.Email) > 100 {
+			return errors.New("invalid email format")
+		}
+	}
+	return nil
+}
+
+// IsValidISBN checks the length and check digit of an ISBN-10 or ISBN-13, ignoring hyphens and spaces
+func IsValidISBN(isbn string) bool {
+	isbn = strings.NewReplacer("-", "", " ", "").Replace(isbn)
+
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, r := range isbn {
+			var digit int
+			switch {
+			case r >= '0' && r <= '9':
+				digit = int(r - '0')
+			case (r == 'X' || r == 'x') && i == 9:
+				digit = 10
+			default:
+				return false
+			}
+			sum += digit * (10 - i)
+		}
+		return sum%11 == 0
+	case 13:
+		sum := 0
+		for i, r := range isbn {
+			if r < '0' || r > '9' {
+				return false
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += int(r-'0') * weight
+		}
+		return sum%10 == 0
+	default:
+		return false
+	}
+}
+
+// NormalizeISBN returns the ISBN-13 form of a valid ISBN-10 or ISBN-13 so both editions of the
+// same number compare equal, or "" when the ISBN is not valid
+func NormalizeISBN(isbn string) string {
+	if !IsValidISBN(isbn) {
+		return ""
+	}
+	isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+	if len(isbn) == 13 {
+		return isbn
+	}
+
+	isbn = "978" + isbn[:9]
+	sum := 0
+	for i, r := range isbn {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+	return isbn + strconv.Itoa((10-sum%10)%10)
+}
+
+var leadingArticles = map[string]struct{}{"the": {}, "a": {}, "an": {}}
+
+// NormalizeBookText lowercases a title or author name, drops punctuation and a leading
+// article and collapses whitespace, so "The Hobbit!" and "hobbit" compare equal
+func NormalizeBookText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			return ' '
+		default:
+			return -1
+		}
+	}, s)
+
+	words := strings.Fields(s)
+	if len(words) > 1 {
+		if _, ok := leadingArticles[words[0]]; ok {
+			words = words[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// TextSimilarity is the Dice coefficient of the character bigrams of two normalized strings,
+// from 0 (nothing shared) to 1 (identical)
+func TextSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < 2 || len(rb) < 2 {
+		return 0
+	}
+
+	bigrams := make(map[string]int, len(ra)-1)
+	for i := 0; i < len(ra)-1; i++ {
+		bigrams[string(ra[i:i+2])]++
+	}
+
+	shared := 0
+	for i := 0; i < len(rb)-1; i++ {
+		bigram := string(rb[i : i+2])
+		if bigrams[bigram] > 0 {
+			bigrams[bigram]--
+			shared++
+		}
+	}
+
+	return float64(2*shared) / float64(len(ra)+len(rb)-2)
+}
+
 func Slugify(s string) string {
 	s = strings.TrimSpace(s)
 	s = strings.ReplaceAll(s, " ", "-")
@@ -92,7 +275,22 @@ func Slugify(s string) string {
 	return s
 }
 
-func ExtractS3Key(url, bucketName, region string) string {
-	prefix := "https://" + bucketName + ".s3." + region + ".amazonaws.com/"
-	return strings.TrimPrefix(url, prefix)
+// UploadKey is where a direct upload is staged until it is finalized
+func UploadKey(id uuid.UUID) string {
+	return UploadKeyPrefix + id.String()
+}
+
+var contentKeyPattern = regexp.MustCompile(`^` + CoverKeyPrefix + `[0-9a-f]{64}\.[a-z]+$`)
+
+// ContentKey names a stored cover variant after the SHA-256 of its bytes, so the same image is
+// stored once however often and for however many books it is uploaded
+func ContentKey(data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	return CoverKeyPrefix + hex.EncodeToString(sum[:]) + ext
+}
+
+// IsContentKey reports whether a key was named by ContentKey. The object behind such a key
+// never changes, so it can be cached for good.
+func IsContentKey(key string) bool {
+	return contentKeyPattern.MatchString(key)
 }
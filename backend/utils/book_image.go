@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"honya/backend/dto"
+	"unicode/utf8"
+)
+
+// IsBookImageRole reports whether role is one of the gallery image roles
+func IsBookImageRole(role string) bool {
+	switch role {
+	case BookImageRoleFront, BookImageRoleBack, BookImageRoleSpine, BookImageRoleSample:
+		return true
+	}
+	return false
+}
+
+func ValidateBookImageCreateRequest(request *dto.BookImageCreateRequest) error {
+	if !IsBookImageRole(request.Role) {
+		return errors.New("role must be front, back, spine or sample")
+	}
+	if request.Position != nil && *request.Position < 0 {
+		return errors.New("position cannot be negative")
+	}
+	return validateAltText(request.AltEn, request.AltJa)
+}
+
+func ValidateBookImageUpdateRequest(request *dto.BookImageUpdateRequest) error {
+	if request.Role == nil && request.AltEn == nil && request.AltJa == nil {
+		return errors.New("at least one of role, alt_en and alt_ja is required")
+	}
+	if request.Role != nil && !IsBookImageRole(*request.Role) {
+		return errors.New("role must be front, back, spine or sample")
+	}
+	var altEn, altJa string
+	if request.AltEn != nil {
+		altEn = *request.AltEn
+	}
+	if request.AltJa != nil {
+		altJa = *request.AltJa
+	}
+	return validateAltText(altEn, altJa)
+}
+
+func validateAltText(texts ...string) error {
+	for _, text := range texts {
+		if utf8.RuneCountInString(text) > BookImageAltMaxLength {
+			return fmt.Errorf("alt text must be at most %d characters", BookImageAltMaxLength)
+		}
+	}
+	return nil
+}
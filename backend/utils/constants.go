@@ -13,6 +13,211 @@ const (
 const (
 	RateLimitMaxRequests    = 100
 	RateLimitExpiryDuration = 1 * time.Minute
+
+	// Reader reports per client IP and window
+	ReportRateLimitMax    = 10
+	ReportRateLimitWindow = 1 * time.Hour
+
+	// Suggested book edits per client IP and window
+	ChangeRequestRateLimitMax    = 10
+	ChangeRequestRateLimitWindow = 1 * time.Hour
+)
+
+const (
+	// SQLSTATE Postgres reports for a unique constraint violation
+	PgUniqueViolationCode = "23505"
+
+	ReviewUniqueConstraint       = "idx_reviews_book_email"
+	ReviewReportUniqueConstraint = "idx_review_reports_reporter"
+)
+
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusHidden    = "hidden"
+	// Submitted while the book was on a moderation hold; published once approved
+	ReviewStatusPending = "pending"
+
+	// Longest review content accepted, in characters
+	ReviewContentMaxLength = 10000
+)
+
+const (
+	ReportReasonSpam      = "spam"
+	ReportReasonOffensive = "offensive"
+	ReportReasonSpoilers  = "spoilers"
+	ReportReasonOffTopic  = "off_topic"
+)
+
+const (
+	AlertTypeVolumeSpike  = "volume_spike"
+	AlertTypeDomainBurst  = "email_domain_burst"
+	AlertTypeIPRangeBurst = "ip_range_burst"
+
+	AnomalyAnalyzerInterval = 5 * time.Minute
+	// Hours of history the per-book baseline is computed over
+	AnomalyBaselineHours = 7 * 24
+	// Weight of the most recent hour in the moving average and variance
+	AnomalyEWMAAlpha = 0.1
+	// Floor for the baseline deviation so quiet books need more than one review to spike
+	AnomalyMinStdDev = 1.0
+	// Share of a book's reviews in the window one source must account for to count as a burst
+	AnomalyBurstShare = 0.5
+)
+
+const (
+	DataQualityMissingCover       = "missing_cover"
+	DataQualityMissingDescription = "missing_description"
+	DataQualityMissingISBN        = "missing_isbn"
+	DataQualityInvalidISBN        = "invalid_isbn"
+	DataQualitySuspiciousPages    = "suspicious_pages"
+	DataQualityDuplicateBooks     = "duplicate_books"
+	DataQualityBrokenImages       = "broken_images"
+	DataQualityOrphanReviews      = "orphan_reviews"
+
+	DataQualitySampleSize = 10
+	// Page counts outside this range are more likely typos than real books
+	DataQualityMinPages = 10
+	DataQualityMaxPages = 5000
+)
+
+const (
+	// Minimum normalized title and author similarity for two books to be reported as duplicates
+	DuplicateTitleThreshold  = 0.85
+	DuplicateAuthorThreshold = 0.8
+
+	DuplicateReasonISBN        = "isbn"
+	DuplicateReasonTitleAuthor = "title_author"
+
+	// How a merge reconciles fields that differ between the two books
+	MergeStrategyKeepTarget   = "keep_target"
+	MergeStrategyFillEmpty    = "fill_empty"
+	MergeStrategyPreferSource = "prefer_source"
+)
+
+const (
+	BookStatusDraft     = "draft"
+	BookStatusScheduled = "scheduled"
+	BookStatusPublished = "published"
+	BookStatusArchived  = "archived"
+
+	// Actor recorded on revisions made by the publishing scheduler
+	ActorScheduler = "scheduler"
+
+	BookPublishInterval = 1 * time.Minute
+)
+
+const (
+	StorageDriverLocal  = "local"
+	StorageDriverS3     = "s3"
+	StorageDriverMemory = "memory"
+
+	// Prefix for uploaded book covers inside the blob store
+	CoverKeyPrefix = "books/"
+	// Prefix for direct uploads waiting to be finalized
+	UploadKeyPrefix = "uploads/"
+
+	UploadURLExpiry = 15 * time.Minute
+
+	// Cache-Control of content-addressed covers, whose bytes never change under their key
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+)
+
+const (
+	// Largest accepted cover upload; the request body limit leaves room for the form fields
+	CoverMaxBytes = 8 << 20
+	// Largest accepted width or height, which bounds the memory a decoded cover takes
+	CoverMaxDimension = 6000
+	CoverMinDimension = 32
+	CoverJPEGQuality  = 85
+	// Lossy WebP at this quality looks like the JPEG variant at a fraction of the size
+	CoverWebPQuality = 80
+	// Encoder effort from 0 (fastest) to 6 (smallest)
+	CoverWebPMethod = 4
+
+	CoverVariantThumbnail = "thumbnail"
+	CoverVariantMedium    = "medium"
+	CoverVariantLarge     = "large"
+)
+
+// CoverVariantSizes is the longest side of each generated cover variant. Smaller originals
+// are never scaled up.
+var CoverVariantSizes = []struct {
+	Name string
+	Size int
+}{
+	{CoverVariantThumbnail, 200},
+	{CoverVariantMedium, 600},
+	{CoverVariantLarge, 1200},
+}
+
+// Roles of the images in a book's gallery
+const (
+	BookImageRoleFront  = "front"
+	BookImageRoleBack   = "back"
+	BookImageRoleSpine  = "spine"
+	BookImageRoleSample = "sample"
+
+	BookImageMaxCount     = 20
+	BookImageAltMaxLength = 500
+)
+
+const (
+	ChangeRequestStatusPending  = "pending"
+	ChangeRequestStatusAccepted = "accepted"
+	ChangeRequestStatusRejected = "rejected"
+)
+
+const (
+	TrashTypeBook   = "book"
+	TrashTypeReview = "review"
+
+	TrashPurgeInterval = 1 * time.Hour
+)
+
+const (
+	ImageGCInterval = 1 * time.Hour
+	// Failed deletions are retried after ImageGCRetryDelay, doubling with every attempt up to ImageGCMaxRetryDelay
+	ImageGCRetryDelay    = 5 * time.Minute
+	ImageGCMaxRetryDelay = 24 * time.Hour
+)
+
+const (
+	AuditLocalsKey = "audit"
+
+	AuditEntityBook               = "book"
+	AuditEntityReview             = "review"
+	AuditEntityScheduledReport    = "scheduled_report"
+	AuditEntityDataSubjectRequest = "data_subject_request"
+	AuditEntityCatalog            = "catalog"
+	AuditEntityChangeRequest      = "change_request"
+	AuditEntityStorage            = "storage"
+
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionMerge   = "merge"
+	AuditActionSeed    = "seed"
+	AuditActionReport  = "report"
+	AuditActionApprove = "approve"
+	AuditActionRemove  = "remove"
+	AuditActionErase   = "erase"
+	AuditActionSend    = "send"
+	AuditActionAccept  = "accept"
+	AuditActionReject  = "reject"
+
+	// Key for the advisory lock that serializes appends to the hash chain
+	AuditChainLockID = 7310420
+	// Entries read per batch when verifying the chain
+	AuditVerifyBatchSize = 500
+)
+
+const (
+	PowAlgorithm    = "sha256"
+	PowChallengeTTL = 5 * time.Minute
+	// Review submissions per minute tolerated before difficulty starts rising
+	PowSurgeThreshold = 10
+	PowSurgeWindow    = 1 * time.Minute
 )
 
 const (
@@ -25,6 +230,108 @@ const (
 	DefaultDonutChartFilterBy = "category"
 )
 
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+const (
+	ReportChartBooksByCategory = "books_by_category"
+	ReportChartBooksByRating   = "books_by_rating"
+	ReportChartBooksByAuthor   = "books_by_author"
+	ReportChartTopReviewers    = "top_reviewers"
+	ReportChartBooksCreated    = "books_created"
+	ReportChartReviewsCreated  = "reviews_created"
+
+	ReportTriggerSchedule = "schedule"
+	ReportTriggerManual   = "manual"
+
+	ReportRunStatusSent   = "sent"
+	ReportRunStatusFailed = "failed"
+
+	ReportSchedulerInterval = 1 * time.Minute
+	MaxReportRecipients     = 20
+)
+
+const (
+	BucketWidth  = "width"
+	BucketEdges  = "edges"
+	BucketDecade = "decade"
+
+	DefaultPagesBucketWidth  = 100
+	DefaultRatingBucketWidth = 1
+	MaxHistogramBuckets      = 200
+)
+
+const (
+	AggregateBooksByCategory = "books_by_category"
+	AggregateBooksByAuthor   = "books_by_author"
+	AggregateReviewers       = "reviewers"
+
+	AggregateUnknownKey = "Unknown"
+)
+
+const (
+	MetricBooksCreated   = "books_created"
+	MetricReviewsCreated = "reviews_created"
+
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+
+	DefaultTimeSeriesInterval = IntervalDay
+	DefaultTimeSeriesBuckets  = 30
+	MaxTimeSeriesBuckets      = 1000
+	TimeSeriesDateLayout      = "2006-01-02"
+)
+
+const (
+	AnalyticsDimensionCategory              = "category"
+	AnalyticsDimensionAuthor                = "author"
+	AnalyticsDimensionPublicationYearBucket = "publication_year_bucket"
+	AnalyticsDimensionRatingBucket          = "rating_bucket"
+	AnalyticsDimensionCreatedMonth          = "created_month"
+
+	AnalyticsMetricCount       = "count"
+	AnalyticsMetricAvgRating   = "avg_rating"
+	AnalyticsMetricAvgPages    = "avg_pages"
+	AnalyticsMetricReviewCount = "review_count"
+
+	AnalyticsFilterEq  = "eq"
+	AnalyticsFilterNeq = "neq"
+	AnalyticsFilterGt  = "gt"
+	AnalyticsFilterGte = "gte"
+	AnalyticsFilterLt  = "lt"
+	AnalyticsFilterLte = "lte"
+	AnalyticsFilterIn  = "in"
+
+	MaxAnalyticsDimensions   = 2
+	MaxAnalyticsFilters      = 10
+	MaxAnalyticsFilterValues = 50
+	MaxAnalyticsRows         = 500
+)
+
+const (
+	RolePublic    = "public"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+
+	RoleLocalsKey = "role"
+	ApiKeyHeader  = "X-API-Key"
+)
+
+const (
+	DataSubjectRequestExport = "export"
+	DataSubjectRequestErase  = "erase"
+
+	DeletedUserName      = "Deleted user"
+	RemovedReviewContent = "This review was removed at the reviewer's request."
+)
+
 var BooksDummyData = []map[string]interface{}{
 	{
 		"id":               "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d",
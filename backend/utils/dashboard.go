@@ -0,0 +1,31 @@
+package utils
+
+import "time"
+
+// TruncateToInterval returns the start of the day, ISO week (Monday) or month containing t,
+// matching Postgres date_trunc in t's location.
+func TruncateToInterval(t time.Time, interval string) time.Time {
+	year, month, day := t.Date()
+
+	switch interval {
+	case IntervalWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case IntervalMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// AddInterval moves t forward by n calendar intervals, staying on wall-clock midnight across DST changes
+func AddInterval(t time.Time, interval string, n int) time.Time {
+	switch interval {
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7*n)
+	case IntervalMonth:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"honya/backend/dto"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var invalidSheetChars = regexp.MustCompile(`[\[\]:*?/\\]`)
+
+// WriteCSV renders a table as CSV with a header row
+func WriteCSV(table dto.Table) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(escapeFormula(table.Headers)); err != nil {
+		return nil, err
+	}
+	for _, row := range table.Rows {
+		if err := writer.Write(escapeFormula(row)); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// WriteXLSX renders each table on its own sheet of a single workbook
+func WriteXLSX(tables ...dto.Table) ([]byte, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	used := map[string]bool{}
+	for i, table := range tables {
+		sheet := sheetName(table.Title, i)
+		if used[sheet] {
+			sheet = sheetName(fmt.Sprintf("%d %s", i+1, table.Title), i)
+		}
+		used[sheet] = true
+
+		if i == 0 {
+			if err := file.SetSheetName("Sheet1", sheet); err != nil {
+				return nil, err
+			}
+		} else if _, err := file.NewSheet(sheet); err != nil {
+			return nil, err
+		}
+
+		if err := file.SetSheetRow(sheet, "A1", &table.Headers); err != nil {
+			return nil, err
+		}
+		for r, row := range table.Rows {
+			cell, _ := excelize.CoordinatesToCellName(1, r+2)
+			values := make([]interface{}, 0, len(row))
+			for _, value := range row {
+				values = append(values, spreadsheetValue(value))
+			}
+			if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// spreadsheetValue keeps numeric cells numeric in spreadsheets
+func spreadsheetValue(value string) interface{} {
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	return value
+}
+
+// escapeFormula prevents spreadsheet apps from evaluating user-provided values such as author
+// names. Tabs and carriage returns count too, as some apps skip them before looking for a formula.
+func escapeFormula(row []string) []string {
+	escaped := make([]string, len(row))
+	for i, value := range row {
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				value = "'" + value
+			}
+		}
+		escaped[i] = value
+	}
+	return escaped
+}
+
+// sheetName makes a title safe for use as a worksheet name (max 31 chars, no []:*?/\)
+func sheetName(title string, index int) string {
+	name := []rune(invalidSheetChars.ReplaceAllString(title, " "))
+	if len(name) > 31 {
+		name = name[:31]
+	}
+	if len(name) == 0 {
+		return fmt.Sprintf("Sheet%d", index+1)
+	}
+	return string(name)
+}
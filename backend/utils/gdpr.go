@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+func ValidateDataSubjectEmail(email string) error {
+	if email == "" {
+		return errors.New("email is required")
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return errors.New("invalid email format")
+	}
+	return nil
+}
+
+// HashEmail returns the HMAC-SHA256 of an email for the data subject request log. A plain
+// digest would equal the public avatar hash and could be checked against guessed emails; this
+// one cannot without the key.
+func HashEmail(key []byte, email string) string {
+	return KeyedHash(key, strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"honya/backend/errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"github.com/buckket/go-blurhash"
+	"github.com/gen2brain/webp"
+	xdraw "golang.org/x/image/draw"
+)
+
+// ProcessedCover is an uploaded cover, validated and re-encoded into its variants
+type ProcessedCover struct {
+	Width         int
+	Height        int
+	Blurhash      string
+	DominantColor string
+	Variants      []CoverVariant
+}
+
+type CoverVariant struct {
+	Name   string
+	Width  int
+	Height int
+	JPEG   []byte
+	WebP   []byte
+}
+
+// Accepted cover formats by the content type sniffed from their first bytes
+var coverFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// IsCoverContentType reports whether covers may be uploaded with this content type
+func IsCoverContentType(contentType string) bool {
+	_, ok := coverFormats[contentType]
+	return ok
+}
+
+// ProcessCoverImage checks that r holds a JPEG, PNG or WebP cover within the size limits and
+// renders every variant from the decoded pixels. Re-encoding drops EXIF and any other
+// metadata; the EXIF orientation is applied first so covers stay upright. Invalid uploads
+// return a bad request error.
+func ProcessCoverImage(r io.Reader) (*ProcessedCover, error) {
+	data, err := io.ReadAll(io.LimitReader(r, CoverMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > CoverMaxBytes {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("image must be at most %d MB", CoverMaxBytes>>20))
+	}
+
+	format, ok := coverFormats[http.DetectContentType(data)]
+	if !ok {
+		return nil, errors.NewBadRequestError("image must be a JPEG, PNG or WebP file")
+	}
+
+	// Check the dimensions from the header before decoding allocates the pixels
+	config, decodedFormat, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || decodedFormat != format {
+		return nil, errors.NewBadRequestError("image could not be read")
+	}
+	if config.Width > CoverMaxDimension || config.Height > CoverMaxDimension {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("image must be at most %dx%d pixels", CoverMaxDimension, CoverMaxDimension))
+	}
+	if config.Width < CoverMinDimension || config.Height < CoverMinDimension {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("image must be at least %dx%d pixels", CoverMinDimension, CoverMinDimension))
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewBadRequestError("image could not be read")
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	cover := &ProcessedCover{Width: config.Width, Height: config.Height}
+	if orientation >= 5 {
+		cover.Width, cover.Height = config.Height, config.Width
+	}
+
+	// Variants fit a square box, so resizing before turning the image upright gives the same size
+	for _, size := range CoverVariantSizes {
+		resized := applyOrientation(fitImage(src, size.Size), orientation)
+
+		variant := CoverVariant{Name: size.Name, Width: resized.Bounds().Dx(), Height: resized.Bounds().Dy()}
+
+		var jpegBuf bytes.Buffer
+		if err := jpeg.Encode(&jpegBuf, resized, &jpeg.Options{Quality: CoverJPEGQuality}); err != nil {
+			return nil, err
+		}
+		variant.JPEG = jpegBuf.Bytes()
+
+		var webpBuf bytes.Buffer
+		if err := webp.Encode(&webpBuf, resized, webp.Options{Quality: CoverWebPQuality, Method: CoverWebPMethod}); err != nil {
+			return nil, err
+		}
+		variant.WebP = webpBuf.Bytes()
+
+		if size.Name == CoverVariantThumbnail {
+			xComponents, yComponents := 4, 3
+			if variant.Height > variant.Width {
+				xComponents, yComponents = 3, 4
+			}
+			if cover.Blurhash, err = blurhash.Encode(xComponents, yComponents, resized); err != nil {
+				return nil, err
+			}
+			cover.DominantColor = dominantColor(resized)
+		}
+
+		cover.Variants = append(cover.Variants, variant)
+	}
+
+	return cover, nil
+}
+
+// fitImage scales src down to fit within size x size, flattening any transparency onto white
+func fitImage(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, xdraw.Over, nil)
+	return dst
+}
+
+// applyOrientation turns an image upright according to its EXIF orientation (1-8)
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flip horizontally
+				dx, dy = width-1-x, y
+			case 3: // rotate 180°
+				dx, dy = width-1-x, height-1-y
+			case 4: // flip vertically
+				dx, dy = x, height-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90° clockwise
+				dx, dy = height-1-y, x
+			case 7: // transverse
+				dx, dy = height-1-y, width-1-x
+			case 8: // rotate 90° counter-clockwise
+				dx, dy = y, width-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, defaulting to 1 (upright)
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		// Start of scan: the metadata segments are over
+		if marker == 0xDA {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation looks up tag 0x0112 in the first IFD of a TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// dominantColor returns the average colour of the most common colour group as #rrggbb
+func dominantColor(img *image.RGBA) string {
+	type group struct{ r, g, b, count int }
+	groups := map[int]*group{}
+	var best *group
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			// 4 bits per channel is coarse enough that near-identical shades count together
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			g, ok := groups[key]
+			if !ok {
+				g = &group{}
+				groups[key] = g
+			}
+			g.r += int(c.R)
+			g.g += int(c.G)
+			g.b += int(c.B)
+			g.count++
+			if best == nil || g.count > best.count {
+				best = g
+			}
+		}
+	}
+	if best == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
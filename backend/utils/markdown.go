@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	mdBlockPattern = regexp.MustCompile(`\n\s*\n`)
+	// The target of a link, after its [label]. The URL may hold one level of balanced
+	// parentheses, as in Wikipedia links.
+	mdLinkTargetPattern = regexp.MustCompile(`^\(((?:[^()\s]|\([^()\s]*\))+)\)`)
+)
+
+type emphasisRule struct {
+	marker string
+	open   string
+	close  string
+}
+
+// mdEmphasis lists the emphasis markers, longest first so that ** is not read as two *
+var mdEmphasis = []emphasisRule{
+	{"**", "<strong>", "</strong>"},
+	{"__", "<strong>", "</strong>"},
+	{"~~", "<del>", "</del>"},
+	{"||", `<span class="spoiler">`, "</span>"},
+	{"*", "<em>", "</em>"},
+	{"_", "<em>", "</em>"},
+}
+
+// mdMaxNesting is how deeply inline elements may nest. Far more than any review needs, and it
+// keeps a long run of markers, where each pair would nest in the next, from costing a scan of
+// the whole run per pair.
+const mdMaxNesting = 16
+
+var allowedLinkSchemes = map[string]struct{}{
+	"http":   {},
+	"https":  {},
+	"mailto": {},
+}
+
+// RenderMarkdown converts the Markdown subset allowed in reviews to HTML.
+// All text is HTML-escaped on its way to the output, so raw tags, scripts and
+// event handlers can never reach it, and every tag written is closed in order. Supported syntax: paragraphs,
+// line breaks, > quotes, **bold**, *italic*, ~~strike~~, `code`, [links](https://...)
+// and ||spoilers||.
+func RenderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\x00", "")
+	source = strings.TrimSpace(strings.ReplaceAll(source, "\r\n", "\n"))
+	if source == "" {
+		return ""
+	}
+
+	var out strings.Builder
+	for _, block := range mdBlockPattern.Split(source, -1) {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+
+		if isQuoteBlock(lines) {
+			for i, line := range lines {
+				lines[i] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ">"))
+			}
+			out.WriteString("<blockquote><p>" + renderInline(lines) + "</p></blockquote>")
+			continue
+		}
+
+		out.WriteString("<p>" + renderInline(lines) + "</p>")
+	}
+
+	return out.String()
+}
+
+func isQuoteBlock(lines []string) bool {
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), ">") {
+			return false
+		}
+	}
+	return true
+}
+
+func renderInline(lines []string) string {
+	return renderSpan(strings.Join(lines, "\n"), true, 0)
+}
+
+// renderSpan renders inline markup left to right. Every element is closed inside the text it
+// opened in, so mis-nested markers stay literal instead of producing unbalanced tags. Text is
+// escaped as it is written out; links are not allowed inside link labels. Each nesting level
+// scans its text again, so markers nested deeper than mdMaxNesting stay literal.
+func renderSpan(text string, links bool, depth int) string {
+	var out strings.Builder
+	scanner := newSpanScanner(text)
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		if end, ok := codeSpanEnd(rest); ok {
+			out.WriteString("<code>" + html.EscapeString(rest[1:end]) + "</code>")
+			i += end + 1
+			continue
+		}
+
+		if links && rest[0] == '[' {
+			if label, target, end, ok := scanner.link(i); ok {
+				rendered := renderSpan(label, false, depth+1)
+				if href, ok := safeLinkHref(target); ok {
+					out.WriteString(`<a href="` + href + `" rel="nofollow noopener noreferrer" target="_blank">` + rendered + `</a>`)
+				} else {
+					out.WriteString(rendered)
+				}
+				i = end
+				continue
+			}
+		}
+
+		if emphasis, end, ok := scanner.matchEmphasis(i, depth); ok {
+			inner := text[i+len(emphasis.marker) : end]
+			out.WriteString(emphasis.open + renderSpan(inner, links, depth+1) + emphasis.close)
+			i = end + len(emphasis.marker)
+			continue
+		}
+
+		if rest[0] == '\n' {
+			out.WriteString("<br>")
+		} else {
+			out.WriteString(html.EscapeString(rest[:1]))
+		}
+		i++
+	}
+
+	return out.String()
+}
+
+// codeSpanEnd returns the index of the backtick closing a code span at the start of text
+func codeSpanEnd(text string) (int, bool) {
+	if text[0] != '`' {
+		return 0, false
+	}
+	end := strings.IndexAny(text[1:], "`\n")
+	if end <= 0 || text[1+end] != '`' {
+		return 0, false
+	}
+	return 1 + end, true
+}
+
+// spanScanner remembers where the searches for closing markers and link ends in one span
+// ended. A search that fails at one delimiter would fail the same way from every delimiter
+// after it on the line, so without this a line full of unclosed markers is rescanned once
+// per marker, which is quadratic in its length.
+type spanScanner struct {
+	text string
+	// Per marker and start position: 0 when not searched yet, -1 when there is no closing
+	// marker, otherwise one past its position
+	closing map[string][]int
+	// The ']' or newline ending a link label, by the position after the '['
+	labelEnd []int
+	// The target pattern's match after a ']', nil when there is none, by the position of the ']'
+	targets map[int][]string
+}
+
+func newSpanScanner(text string) *spanScanner {
+	return &spanScanner{text: text, closing: map[string][]int{}, targets: map[int][]string{}}
+}
+
+// matchEmphasis finds the emphasis opening at position i and the position of its closing marker
+func (s *spanScanner) matchEmphasis(i, depth int) (emphasisRule, int, bool) {
+	if depth >= mdMaxNesting {
+		return emphasisRule{}, 0, false
+	}
+
+	text := s.text
+	for _, emphasis := range mdEmphasis {
+		marker := emphasis.marker
+		if !strings.HasPrefix(text[i:], marker) {
+			continue
+		}
+		// _ only marks emphasis at word boundaries, so snake_case stays as it is
+		if marker == "_" && i > 0 && isWordByte(text[i-1]) {
+			continue
+		}
+
+		start := i + len(marker)
+		end, ok := s.closingMarker(start, marker)
+		if !ok || end == start {
+			continue
+		}
+
+		inner := text[start:end]
+		first, _ := utf8.DecodeRuneInString(inner)
+		last, _ := utf8.DecodeLastRuneInString(inner)
+		if len(marker) == 1 && (unicode.IsSpace(first) || unicode.IsSpace(last) || inner[0] == marker[0]) {
+			continue
+		}
+		return emphasis, end, true
+	}
+
+	return emphasisRule{}, 0, false
+}
+
+// closingMarker looks for marker on the same line, skipping code spans and, for single
+// markers, doubled ones that belong to another element. Every position the search steps
+// through shares its result, so each one is searched from at most once per marker.
+func (s *spanScanner) closingMarker(start int, marker string) (int, bool) {
+	text := s.text
+	memo, ok := s.closing[marker]
+	if !ok {
+		memo = make([]int, len(text)+1)
+		s.closing[marker] = memo
+	}
+
+	var visited []int
+	result := -1
+	for k := start; k < len(text); {
+		if memo[k] != 0 {
+			result = memo[k]
+			break
+		}
+		visited = append(visited, k)
+
+		next, found, done := closingStep(text, k, marker)
+		if done {
+			if found {
+				result = k + 1
+			}
+			break
+		}
+		k = next
+	}
+
+	for _, k := range visited {
+		memo[k] = result
+	}
+	if result < 0 {
+		return 0, false
+	}
+	return result - 1, true
+}
+
+// closingStep checks whether marker closes at position k. When it does not, and the line
+// goes on, next is where the search continues.
+func closingStep(text string, k int, marker string) (next int, found bool, done bool) {
+	if text[k] == '\n' {
+		return 0, false, true
+	}
+	if end, ok := codeSpanEnd(text[k:]); ok {
+		return k + end + 1, false, false
+	}
+	if !strings.HasPrefix(text[k:], marker) {
+		return k + 1, false, false
+	}
+	if len(marker) == 1 && k+1 < len(text) && text[k+1] == marker[0] {
+		return k + 2, false, false
+	}
+	// In ***, the double marker closes last so the single one nests inside it
+	if len(marker) == 2 && k+2 < len(text) && text[k+2] == marker[0] {
+		return k + 1, false, false
+	}
+	if marker == "_" && k+1 < len(text) && isWordByte(text[k+1]) {
+		return k + 1, false, false
+	}
+	return k, true, true
+}
+
+// link matches a [label](target) link at position i and returns the position after it.
+// The label ends at the first ']' on the line, so the links starting at every '[' before
+// the same ']' share one label end and one target match.
+func (s *spanScanner) link(i int) (string, string, int, bool) {
+	text := s.text
+	if s.labelEnd == nil {
+		s.labelEnd = make([]int, len(text)+1)
+		s.labelEnd[len(text)] = len(text)
+		for k := len(text) - 1; k >= 0; k-- {
+			if text[k] == ']' || text[k] == '\n' {
+				s.labelEnd[k] = k
+			} else {
+				s.labelEnd[k] = s.labelEnd[k+1]
+			}
+		}
+	}
+
+	labelClose := s.labelEnd[i+1]
+	if labelClose == i+1 || labelClose == len(text) || text[labelClose] != ']' {
+		return "", "", 0, false
+	}
+
+	target, ok := s.targets[labelClose]
+	if !ok {
+		target = mdLinkTargetPattern.FindStringSubmatch(text[labelClose+1:])
+		s.targets[labelClose] = target
+	}
+	if target == nil {
+		return "", "", 0, false
+	}
+
+	return text[i+1 : labelClose], target[1], labelClose + 1 + len(target[0]), true
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// safeLinkHref only lets absolute http(s) and mailto links through
+func safeLinkHref(raw string) (string, bool) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if _, ok := allowedLinkSchemes[strings.ToLower(parsed.Scheme)]; !ok {
+		return "", false
+	}
+	return html.EscapeString(parsed.String()), true
+}
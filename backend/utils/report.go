@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"honya/backend/dto"
+	"html/template"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var allowedReportCharts = map[string]struct{}{
+	ReportChartBooksByCategory: {},
+	ReportChartBooksByRating:   {},
+	ReportChartBooksByAuthor:   {},
+	ReportChartTopReviewers:    {},
+	ReportChartBooksCreated:    {},
+	ReportChartReviewsCreated:  {},
+}
+
+func ValidateScheduledReportCreateRequest(request *dto.ScheduledReportCreateRequest) error {
+	if strings.TrimSpace(request.Name) == "" {
+		return errors.New("name is required")
+	}
+	if request.Cron == "" {
+		return errors.New("cron is required")
+	}
+	if _, err := NextReportRun(request.Cron, request.Timezone, time.Now()); err != nil {
+		return err
+	}
+	if err := validateReportRecipients(request.Recipients); err != nil {
+		return err
+	}
+	if err := validateReportCharts(request.Charts); err != nil {
+		return err
+	}
+	return validateReportFormat(request.Format)
+}
+
+func ValidateScheduledReportUpdateRequest(request *dto.ScheduledReportUpdateRequest) error {
+	if request.Name != nil && strings.TrimSpace(*request.Name) == "" {
+		return errors.New("name cannot be empty")
+	}
+	if request.Recipients != nil {
+		if err := validateReportRecipients(*request.Recipients); err != nil {
+			return err
+		}
+	}
+	if request.Charts != nil {
+		if err := validateReportCharts(*request.Charts); err != nil {
+			return err
+		}
+	}
+	if request.Format != nil {
+		return validateReportFormat(*request.Format)
+	}
+	return nil
+}
+
+// NextReportRun returns the first run of a standard 5-field cron expression (or descriptor such as @daily)
+// after now, evaluated in the given IANA timezone
+func NextReportRun(expression, timezone string, now time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression: %v", err)
+	}
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone: %s", timezone)
+	}
+
+	return schedule.Next(now.In(loc)), nil
+}
+
+func validateReportRecipients(recipients []string) error {
+	if len(recipients) == 0 {
+		return errors.New("at least one recipient is required")
+	}
+	if len(recipients) > MaxReportRecipients {
+		return fmt.Errorf("at most %d recipients are allowed", MaxReportRecipients)
+	}
+	for _, recipient := range recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("invalid recipient email: %s", recipient)
+		}
+	}
+	return nil
+}
+
+// NormalizeReportRecipients formats validated recipients as RFC 5322 addresses and drops
+// repeats of the same mailbox, so "Ops <ops@example.com>" and "ops@example.com" get one email
+func NormalizeReportRecipients(recipients []string) []string {
+	normalized := make([]string, 0, len(recipients))
+	seen := map[string]bool{}
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			continue
+		}
+		key := strings.ToLower(address.Address)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, address.String())
+	}
+	return normalized
+}
+
+func validateReportCharts(charts []string) error {
+	if len(charts) == 0 {
+		return errors.New("at least one chart is required")
+	}
+	for _, chart := range charts {
+		if _, valid := allowedReportCharts[chart]; !valid {
+			return fmt.Errorf("invalid chart: %s. Allowed charts are: books_by_category, books_by_rating, books_by_author, top_reviewers, books_created, reviews_created", chart)
+		}
+	}
+	return nil
+}
+
+func validateReportFormat(format string) error {
+	if format != "" && format != ExportFormatCSV && format != ExportFormatXLSX {
+		return fmt.Errorf("invalid format: %s. Allowed formats are: csv, xlsx", format)
+	}
+	return nil
+}
+
+var reportEmailTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+<h1 style="font-size: 20px;">{{.Name}}</h1>
+<p style="color: #666;">Generated {{.GeneratedAt}}</p>
+{{range .Tables}}
+<h2 style="font-size: 16px; margin-top: 24px;">{{.Title}}</h2>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr>{{range .Headers}}<th style="border-bottom: 1px solid #ccc; text-align: left;">{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td style="border-bottom: 1px solid #eee;">{{.}}</td>{{end}}</tr>
+{{else}}<tr><td>No data</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// RenderReportEmail renders the HTML summary of a report and a plain-text fallback
+func RenderReportEmail(name string, generatedAt time.Time, tables []dto.Table) (string, string, error) {
+	var html bytes.Buffer
+	if err := reportEmailTemplate.Execute(&html, map[string]interface{}{
+		"Name":        name,
+		"GeneratedAt": generatedAt.Format("2006-01-02 15:04 MST"),
+		"Tables":      tables,
+	}); err != nil {
+		return "", "", err
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\nGenerated %s\n", name, generatedAt.Format("2006-01-02 15:04 MST"))
+	for _, table := range tables {
+		fmt.Fprintf(&text, "\n%s\n%s\n", table.Title, strings.Join(table.Headers, "\t"))
+		for _, row := range table.Rows {
+			fmt.Fprintln(&text, strings.Join(row, "\t"))
+		}
+	}
+
+	return html.String(), text.String(), nil
+}
@@ -2,8 +2,13 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"honya/backend/dto"
+	"math"
+	"net"
 	"net/mail"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -24,7 +29,7 @@ func ValidateReviewCreateRequest(request *dto.ReviewCreateRequest) error {
 	if request.Content == "" {
 		return errors.New("content is required")
 	}
-	return nil
+	return validateReviewContentLength(request.Content)
 }
 
 func ValidateReviewUpdateRequest(request *dto.ReviewUpdateRequest) error {
@@ -39,8 +44,173 @@ func ValidateReviewUpdateRequest(request *dto.ReviewUpdateRequest) error {
 			return errors.New("invalid email format")
 		}
 	}
-	if request.Content != nil && *request.Content == "" {
-		return errors.New("content cannot be empty")
+	if request.Content != nil {
+		if *request.Content == "" {
+			return errors.New("content cannot be empty")
+		}
+		return validateReviewContentLength(*request.Content)
+	}
+	return nil
+}
+
+func validateReviewContentLength(content string) error {
+	if utf8.RuneCountInString(content) > ReviewContentMaxLength {
+		return fmt.Errorf("content must be at most %d characters", ReviewContentMaxLength)
+	}
+	return nil
+}
+
+var allowedReportReasons = map[string]struct{}{
+	ReportReasonSpam:      {},
+	ReportReasonOffensive: {},
+	ReportReasonSpoilers:  {},
+	ReportReasonOffTopic:  {},
+}
+
+func ValidateReviewReportRequest(request *dto.ReviewReportRequest) error {
+	if request.Reason == "" {
+		return errors.New("reason is required")
+	}
+	if _, valid := allowedReportReasons[request.Reason]; !valid {
+		return fmt.Errorf("invalid reason: %s. Allowed reasons are: spam, offensive, spoilers, off_topic", request.Reason)
 	}
 	return nil
 }
+
+// NormalizeEmail reduces an email to a canonical mailbox so that
+// case changes and plus-addressing can't be used to post duplicate reviews.
+// Gmail ignores dots in the local part, which can optionally be stripped too.
+func NormalizeEmail(email string, ignoreGmailDots bool) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+
+	if ignoreGmailDots && (domain == "gmail.com" || domain == "googlemail.com") {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+
+	return local + "@" + domain
+}
+
+// IPRange returns the /24 (IPv4) or /48 (IPv6) network of an address, which is enough to
+// spot coordinated submissions without storing the full address
+func IPRange(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// freemailDomains are shared by too many unrelated people for a burst from one of them to
+// mean anything
+var freemailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"yahoo.com":      true,
+	"yahoo.co.jp":    true,
+	"outlook.com":    true,
+	"outlook.jp":     true,
+	"hotmail.com":    true,
+	"hotmail.co.jp":  true,
+	"live.com":       true,
+	"live.jp":        true,
+	"msn.com":        true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"aol.com":        true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"gmx.com":        true,
+	"gmx.de":         true,
+	"yandex.ru":      true,
+	"mail.ru":        true,
+	"qq.com":         true,
+	"163.com":        true,
+	"naver.com":      true,
+	"docomo.ne.jp":   true,
+	"ezweb.ne.jp":    true,
+	"au.com":         true,
+	"softbank.ne.jp": true,
+	"i.softbank.jp":  true,
+	"nifty.com":      true,
+	"ocn.ne.jp":      true,
+}
+
+// IsFreemailDomain reports whether a domain belongs to a public email provider
+func IsFreemailDomain(domain string) bool {
+	return freemailDomains[strings.ToLower(domain)]
+}
+
+// ParseNetwork parses a CIDR range or a single address, which is read as a network of one
+func ParseNetwork(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		return network, err
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address or range: %s", value)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// ParseNetworks parses a list of CIDR ranges and addresses, skipping invalid entries
+func ParseNetworks(values []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if network, err := ParseNetwork(value); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// NetworksOverlap reports whether the range given in CIDR notation shares any address with
+// one of the networks
+func NetworksOverlap(cidr string, networks []*net.IPNet) bool {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	for _, other := range networks {
+		if other.Contains(network.IP) || network.Contains(other.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// EWMABaseline returns the exponentially weighted mean and standard deviation of a series,
+// oldest value first
+func EWMABaseline(series []int64, alpha float64) (float64, float64) {
+	if len(series) == 0 {
+		return 0, 0
+	}
+
+	mean := float64(series[0])
+	variance := 0.0
+	for _, value := range series[1:] {
+		diff := float64(value) - mean
+		mean += alpha * diff
+		variance = (1 - alpha) * (variance + alpha*diff*diff)
+	}
+
+	return mean, math.Sqrt(variance)
+}
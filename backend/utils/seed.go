@@ -74,6 +74,8 @@ func SeedBooksAndReviews(db *gorm.DB) error {
 		if reviews[i].BookID == uuid.Nil {
 			return fmt.Errorf("review at index %d does not have a valid BookID", i)
 		}
+		reviews[i].EmailNormalized = NormalizeEmail(reviews[i].Email, true)
+		reviews[i].ContentHTML = RenderMarkdown(reviews[i].Content)
 		reviews[i].CreatedAt = now
 		reviews[i].UpdatedAt = now
 	}
@@ -1,11 +1,16 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	goerrors "errors"
 	"honya/backend/errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func ParseInt(val string, defaultValue int) int {
@@ -34,3 +39,37 @@ func ParseUUIDParam(ctx *fiber.Ctx, param string) (uuid.UUID, error) {
 	}
 	return id, nil
 }
+
+func GetRole(ctx *fiber.Ctx) string {
+	if role, ok := ctx.Locals(RoleLocalsKey).(string); ok && role != "" {
+		return role
+	}
+	return RolePublic
+}
+
+// IsPrivileged reports whether the caller may see reviewer personal data
+func IsPrivileged(ctx *fiber.Ctx) bool {
+	role := GetRole(ctx)
+	return role == RoleAdmin || role == RoleModerator
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint violation on the given constraint
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return goerrors.As(err, &pgErr) && pgErr.Code == PgUniqueViolationCode && pgErr.ConstraintName == constraint
+}
+
+// HashString returns the hex-encoded SHA-256 digest of s
+func HashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyedHash returns the hex-encoded HMAC-SHA256 of s. Use it instead of HashString for
+// personal data with few possible values, such as emails or IP addresses, whose plain digest
+// can be reversed by trying them all.
+func KeyedHash(key []byte, s string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
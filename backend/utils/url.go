@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"net/url"
 	"regexp"
 
 	"honya/backend/dto"
@@ -29,3 +30,12 @@ func ValidateProcessUrlRequest(request *dto.ProcessUrlRequest) error {
 	}
 	return nil
 }
+
+// StoragePath is the route the API serves local blobs on, taken from LOCAL_STORAGE_URL
+func StoragePath(storageURL string) string {
+	parsed, err := url.Parse(storageURL)
+	if err != nil || parsed.Path == "" {
+		return "/uploads"
+	}
+	return parsed.Path
+}
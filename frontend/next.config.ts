@@ -8,6 +8,12 @@ const nextConfig: NextConfig = {
   },
   images: {
     remotePatterns: [
+      {
+        protocol: 'http',
+        hostname: 'localhost',
+        port: '8080',
+        pathname: '/uploads/**',
+      },
       {
         protocol: 'https',
         hostname: 's3.ap-south-1.amazonaws.com',
@@ -1,8 +1,9 @@
 'use server'
 
 import { ReviewFormData } from "@/components/book-details/AddReview";
-import { ReviewsResponse } from "@/types/book";
+import { ReviewChallenge, ReviewChallengeSolution, ReviewsResponse } from "@/types/book";
 import { revalidatePath } from "next/cache";
+import { forwardedHeaders } from "@/lib/request";
 
 const BACKEND_API_URL = process.env.BACKEND_API_URL
 
@@ -28,14 +29,30 @@ export async function getBookReviews(bookId: string): Promise<ReviewsResponse |
   }
 }
 
-export async function addReview(data: ReviewFormData, bookId: string) {
+// Fetches a proof-of-work challenge for the visitor's browser to solve before posting a review
+export async function getReviewChallenge(): Promise<ReviewChallenge> {
+  const res = await fetch(`${BACKEND_API_URL}/reviews/challenge`, {
+    headers: await forwardedHeaders(),
+    cache: 'no-store',
+  });
+  const { challenge, difficulty }: ReviewChallenge = await res.json();
+  return { challenge, difficulty };
+}
+
+export async function addReview(data: ReviewFormData, bookId: string, pow: ReviewChallengeSolution) {
   try {
     const res = await fetch(`${BACKEND_API_URL}/reviews`, {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
+        ...(await forwardedHeaders()),
       },
-      body: JSON.stringify({ ...data, book_id: bookId }),
+      body: JSON.stringify({
+        ...data,
+        pow_challenge: pow.pow_challenge,
+        pow_solution: pow.pow_solution,
+        book_id: bookId,
+      }),
       cache: 'no-store',
     });
 
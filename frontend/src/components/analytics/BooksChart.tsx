@@ -102,34 +102,9 @@ export default function BooksDonutChart({
       rawData = booksData;
     }
 
-    if (filterBy === 'rating') {
-      const ratingGroups: Record<string, number> = {
-        '1': 0,
-        '2': 0,
-        '3': 0,
-        '4': 0,
-        '5': 0,
-      };
-
-      Object.entries(rawData).forEach(([rating, count]) => {
-        const numRating = parseFloat(rating);
-        const roundedRating = Math.floor(numRating).toString();
-        if (ratingGroups[roundedRating] !== undefined) {
-          ratingGroups[roundedRating] += count;
-        }
-      });
-
-      const filteredRatingGroups = Object.entries(ratingGroups)
-        .filter(([_, count]) => count > 0)
-        .reduce((acc, [rating, count]) => {
-          acc[rating] = count;
-          return acc;
-        }, {} as Record<string, number>);
-
-      rawData = filteredRatingGroups;
-    }
-
+    // Ratings arrive already bucketed, with empty buckets between populated ones
     return Object.entries(rawData)
+      .filter(([, count]) => count > 0)
       .sort(([, a], [, b]) => b - a)
       .map(([name, count]) => ({
         name: name,
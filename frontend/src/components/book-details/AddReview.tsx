@@ -17,7 +17,8 @@ import { reviewFormSchema } from '@/lib/validation';
 import { useForm } from 'react-hook-form';
 import { zodResolver } from '@hookform/resolvers/zod';
 import { toast } from 'sonner';
-import { addReview } from '@/actions/reviews.action';
+import { addReview, getReviewChallenge } from '@/actions/reviews.action';
+import { solveChallenge } from '@/lib/pow';
 import { useReviewStore } from '@/stores/review.store';
 import { getNestedTranslation, resolveActionMessage } from '@/lib/utils';
 import { LocaleDict } from '@/lib/locales';
@@ -50,7 +51,8 @@ export default function AddReview({ bookId, translations }: AddReviewProps) {
   const onSubmit = async (data: ReviewFormData) => {
     try {
       startTransition(async () => {
-        const response = await addReview(data, bookId);
+        const pow = await solveChallenge(await getReviewChallenge());
+        const response = await addReview(data, bookId, pow);
 
         if (response?.success) {
           toast.success(
@@ -0,0 +1,20 @@
+import type { ReviewChallenge, ReviewChallengeSolution } from '@/types/book';
+
+// Solves a proof-of-work challenge in a Web Worker so the page stays responsive. The work is
+// done by the visitor's browser, never by the server.
+export function solveChallenge(challenge: ReviewChallenge): Promise<ReviewChallengeSolution> {
+  return new Promise((resolve, reject) => {
+    const worker = new Worker(new URL('./pow.worker.ts', import.meta.url));
+
+    worker.onmessage = (event: MessageEvent<string>) => {
+      worker.terminate();
+      resolve({ pow_challenge: challenge.challenge, pow_solution: event.data });
+    };
+    worker.onerror = (error) => {
+      worker.terminate();
+      reject(error);
+    };
+
+    worker.postMessage(challenge);
+  });
+}
@@ -0,0 +1,29 @@
+/// <reference lib="webworker" />
+
+import type { ReviewChallenge } from '@/types/book';
+
+function leadingZeroBits(hash: Uint8Array): number {
+  let count = 0;
+  for (const byte of hash) {
+    if (byte === 0) {
+      count += 8;
+      continue;
+    }
+    return count + Math.clz32(byte) - 24;
+  }
+  return count;
+}
+
+// Searches for a nonce whose SHA-256 with the challenge has enough leading zero bits
+self.onmessage = async (event: MessageEvent<ReviewChallenge>) => {
+  const { challenge, difficulty } = event.data;
+  const encoder = new TextEncoder();
+
+  for (let i = 0; ; i++) {
+    const hash = await crypto.subtle.digest('SHA-256', encoder.encode(`${challenge}:${i}`));
+    if (leadingZeroBits(new Uint8Array(hash)) >= difficulty) {
+      self.postMessage(String(i));
+      return;
+    }
+  }
+};
@@ -0,0 +1,11 @@
+import { headers } from "next/headers";
+
+// Headers that tell the API which visitor a server action is acting for. The API only
+// honours them from addresses listed in its TRUSTED_PROXIES.
+export async function forwardedHeaders(): Promise<Record<string, string>> {
+  const requestHeaders = await headers();
+  const ip = requestHeaders.get('x-real-ip')
+    ?? requestHeaders.get('x-forwarded-for')?.split(',')[0].trim();
+
+  return ip ? { 'X-Forwarded-For': ip } : {};
+}
@@ -71,4 +71,13 @@ export interface ReviewsResponse {
     limit: number
     offset: number
   }
-}
\ No newline at end of file
+}
+export interface ReviewChallenge {
+  challenge: string
+  difficulty: number
+}
+
+export interface ReviewChallengeSolution {
+  pow_challenge: string
+  pow_solution: string
+}